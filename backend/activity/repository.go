@@ -0,0 +1,264 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"gorm.io/gorm"
+)
+
+type ActivityListQuery struct {
+	Cursor string
+	Limit  int
+
+	ActorID *string
+	Types   []string // activities of any of the types
+}
+
+type ActivityRow struct {
+	ID        string      `gorm:"column:id"`
+	ProjectID string      `gorm:"column:project_id"`
+	Type      string      `gorm:"column:type"`
+	Body      models.JSON `gorm:"column:body"`
+	CreatedAt time.Time   `gorm:"column:created_at"`
+
+	ActorID     string  `gorm:"column:actor_id"`
+	Username    string  `gorm:"column:username"`
+	DisplayName *string `gorm:"column:display_name"`
+	Email       string  `gorm:"column:email"`
+	AvatarURL   *string `gorm:"column:avatar_url"`
+}
+
+// The activities are written by the services, in the transaction of the
+// change they record
+type ActivityRepository struct {
+	db *gorm.DB
+}
+
+func NewActivityRepository(db *gorm.DB) *ActivityRepository {
+	return &ActivityRepository{
+		db: db,
+	}
+}
+
+func (r *ActivityRepository) WithTx(tx *gorm.DB) *ActivityRepository {
+	return NewActivityRepository(tx)
+}
+
+func (r *ActivityRepository) Create(ctx context.Context,
+	projectID, actorID, aType string,
+	body models.JSON) (string, error) {
+
+	activity := models.Activity{
+		ID:        uuid.NewString(),
+		ProjectID: projectID,
+		ActorID:   actorID,
+		Type:      aType,
+		Body:      body,
+	}
+	err := gorm.G[models.Activity](r.db).Create(ctx, &activity)
+	if err != nil {
+		return "", fmt.Errorf("gorm create: %w", err)
+	}
+
+	return activity.ID, nil
+}
+
+func (r *ActivityRepository) task(ctx context.Context,
+	projectID, taskID string) (TaskBody, error) {
+
+	task, err := gorm.G[models.Task](r.db).
+		Where("project_id = ? AND id = ?", projectID, taskID).
+		First(ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return TaskBody{}, core.ErrNotFound
+	} else if err != nil {
+		return TaskBody{}, fmt.Errorf("gorm query: %w", err)
+	}
+
+	return TaskBody{
+		ID:    task.ID,
+		Title: task.Title,
+	}, nil
+}
+
+func (r *ActivityRepository) avatar(ctx context.Context,
+	userID string) (core.Avatar, error) {
+
+	user, err := gorm.G[models.User](r.db).Where("id = ?", userID).First(ctx)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return core.Avatar{}, core.ErrNotFound
+	} else if err != nil {
+		return core.Avatar{}, fmt.Errorf("gorm query: %w", err)
+	}
+
+	return core.Avatar{
+		UserID:      user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Email:       user.Email,
+		AvatarURL:   user.AvatarURL,
+	}, nil
+}
+
+func (r *ActivityRepository) TaskCreated(ctx context.Context,
+	projectID, taskID, actorID string) error {
+
+	task, err := r.task(ctx, projectID, taskID)
+	if err != nil {
+		return fmt.Errorf("activity repository task: %w", err)
+	}
+
+	body, _ := json.Marshal(TaskCreated{
+		Task: task,
+	})
+
+	_, err = r.Create(ctx, projectID, actorID, AT_TASK_CREATED, body)
+	if err != nil {
+		return fmt.Errorf("activity repository Create: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ActivityRepository) TaskStatusChanged(ctx context.Context,
+	projectID, taskID, status, actorID string) error {
+
+	task, err := r.task(ctx, projectID, taskID)
+	if err != nil {
+		return fmt.Errorf("activity repository task: %w", err)
+	}
+
+	body, _ := json.Marshal(TaskStatusChanged{
+		Task: task,
+		To:   status,
+	})
+
+	_, err = r.Create(ctx, projectID, actorID, AT_TASK_STATUS_CHANGED, body)
+	if err != nil {
+		return fmt.Errorf("activity repository Create: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ActivityRepository) CommentAdded(ctx context.Context,
+	projectID, taskID, commentID, actorID string) error {
+
+	task, err := r.task(ctx, projectID, taskID)
+	if err != nil {
+		return fmt.Errorf("activity repository task: %w", err)
+	}
+
+	body, _ := json.Marshal(CommentAdded{
+		Task:      task,
+		CommentID: commentID,
+	})
+
+	_, err = r.Create(ctx, projectID, actorID, AT_COMMENT_ADDED, body)
+	if err != nil {
+		return fmt.Errorf("activity repository Create: %w", err)
+	}
+
+	return nil
+}
+
+// Records the response of the responder, and the joining of the requestor
+// when the request is accepted
+func (r *ActivityRepository) JoinResponded(ctx context.Context,
+	projectID, requestorID, status, responderID string) error {
+
+	requestor, err := r.avatar(ctx, requestorID)
+	if err != nil {
+		return fmt.Errorf("activity repository avatar: %w", err)
+	}
+
+	body, _ := json.Marshal(JoinResponded{
+		Requestor: requestor,
+		Status:    status,
+	})
+
+	_, err = r.Create(ctx, projectID, responderID, AT_JOIN_RESPONDED, body)
+	if err != nil {
+		return fmt.Errorf("activity repository Create: %w", err)
+	}
+
+	if status != core.JOIN_STATUS_ACCEPTED {
+		return nil
+	}
+
+	responder, err := r.avatar(ctx, responderID)
+	if err != nil {
+		return fmt.Errorf("activity repository avatar: %w", err)
+	}
+
+	body, _ = json.Marshal(MemberJoined{
+		ApprovedBy: responder,
+	})
+
+	_, err = r.Create(ctx, projectID, requestorID, AT_MEMBER_JOINED, body)
+	if err != nil {
+		return fmt.Errorf("activity repository Create: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ActivityRepository) List(ctx context.Context,
+	projectID string,
+	query ActivityListQuery) ([]ActivityRow, string, error) {
+
+	cursor, err := core.DecodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	q := r.db.WithContext(ctx).
+		Table("activities a").
+		Select(`a.id, a.project_id, a.type, a.body, a.created_at, 
+				a.actor_id, 
+				u.username as username, 
+				u.display_name as display_name, 
+				u.email as email, 
+				u.avatar_url as avatar_url`).
+		Joins("INNER JOIN users as u ON u.id=a.actor_id").
+		Where("a.project_id = ?", projectID)
+
+	if query.ActorID != nil {
+		q = q.Where("a.actor_id = ?", *query.ActorID)
+	}
+	if len(query.Types) > 0 {
+		q = q.Where("a.type IN ?", query.Types)
+	}
+
+	// the feed is read from the latest activity backwards
+	if cursor != nil {
+		createdAt, err := cursor.Time()
+		if err != nil {
+			return nil, "", err
+		}
+		q = q.Where("(a.created_at, a.id) < (?, ?)", createdAt, cursor.ID)
+	}
+
+	var rows = []ActivityRow{}
+	err = q.
+		Order("a.created_at DESC, a.id DESC").
+		Limit(query.Limit + 1).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, "", fmt.Errorf("db query context: %w", err)
+	}
+
+	rows, nextCursor := core.Paginate(rows, query.Limit,
+		func(row ActivityRow) core.Cursor {
+			return core.NewTimeCursor(row.CreatedAt, row.ID)
+		})
+
+	return rows, nextCursor, nil
+}
@@ -0,0 +1,177 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"testing"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/ptracker/testhelpers/fixtures"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type activityRepositoryTestSuite struct {
+	suite.Suite
+	ctx         context.Context
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	fixtures    *fixtures.Fixtures
+	repo        *ActivityRepository
+}
+
+func TestActivityRepository(t *testing.T) {
+	suite.Run(t, new(activityRepositoryTestSuite))
+}
+
+func (suite *activityRepositoryTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx = context.Background()
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.repo = NewActivityRepository(suite.db)
+
+	suite.fixtures = fixtures.New(suite.ctx, suite.db)
+
+	USER_ONE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_TWO = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_THREE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+}
+
+func (suite *activityRepositoryTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM projects").Error
+	suite.Require().NoError(err)
+}
+
+func (suite *activityRepositoryTestSuite) TestTaskCreated() {
+	t := suite.T()
+
+	t.Run("should record task created activity", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskRow := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		task := suite.fixtures.InsertTask(taskRow)
+
+		err := suite.repo.TaskCreated(suite.ctx, p, task, USER_ONE)
+		a, _ := gorm.G[models.Activity](suite.db).Where("project_id = ?", p).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(AT_TASK_CREATED, a.Type)
+		suite.Require().Equal(USER_ONE, a.ActorID)
+		var body TaskCreated
+		json.Unmarshal(a.Body, &body)
+		suite.Require().Equal(task, body.Task.ID)
+		suite.Require().Equal(taskRow.Title, body.Task.Title)
+	})
+	t.Run("should return not found for task of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_UNASSIGNED))
+
+		err := suite.repo.TaskCreated(suite.ctx, p2, task, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
+
+func (suite *activityRepositoryTestSuite) TestTaskStatusChanged() {
+	t := suite.T()
+
+	t.Run("should record the new status", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_COMPLETED))
+
+		err := suite.repo.TaskStatusChanged(suite.ctx, p, task, core.TASK_STATUS_COMPLETED, USER_ONE)
+		a, _ := gorm.G[models.Activity](suite.db).Where("project_id = ?", p).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(AT_TASK_STATUS_CHANGED, a.Type)
+		var body TaskStatusChanged
+		json.Unmarshal(a.Body, &body)
+		suite.Require().Equal(core.TASK_STATUS_COMPLETED, body.To)
+	})
+}
+
+func (suite *activityRepositoryTestSuite) TestCommentAdded() {
+	t := suite.T()
+
+	t.Run("should record the comment", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		comment := fixtures.GetCommentRow(p, task, USER_TWO, "hello")
+		suite.fixtures.InsertComment(comment)
+
+		err := suite.repo.CommentAdded(suite.ctx, p, task, comment.ID, USER_TWO)
+		a, _ := gorm.G[models.Activity](suite.db).Where("project_id = ?", p).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(AT_COMMENT_ADDED, a.Type)
+		suite.Require().Equal(USER_TWO, a.ActorID)
+		var body CommentAdded
+		json.Unmarshal(a.Body, &body)
+		suite.Require().Equal(comment.ID, body.CommentID)
+	})
+}
+
+func (suite *activityRepositoryTestSuite) TestJoinResponded() {
+	t := suite.T()
+
+	t.Run("should record response and member joined when accepted", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.repo.JoinResponded(suite.ctx, p, USER_TWO, core.JOIN_STATUS_ACCEPTED, USER_ONE)
+		responded, _ := gorm.G[models.Activity](suite.db).
+			Where("project_id = ? AND type = ?", p, AT_JOIN_RESPONDED).
+			First(suite.ctx)
+		joined, _ := gorm.G[models.Activity](suite.db).
+			Where("project_id = ? AND type = ?", p, AT_MEMBER_JOINED).
+			First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(USER_ONE, responded.ActorID)
+		suite.Require().Equal(USER_TWO, joined.ActorID)
+	})
+	t.Run("should not record member joined when rejected", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.repo.JoinResponded(suite.ctx, p, USER_TWO, core.JOIN_STATUS_REJECTED, USER_ONE)
+		a, _ := gorm.G[models.Activity](suite.db).Where("project_id = ?", p).Find(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(a))
+		suite.Require().Equal(AT_JOIN_RESPONDED, a[0].Type)
+	})
+}
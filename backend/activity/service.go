@@ -0,0 +1,127 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/members"
+)
+
+const (
+	AT_TASK_CREATED        = "task_created"
+	AT_TASK_STATUS_CHANGED = "task_status_changed"
+	AT_COMMENT_ADDED       = "comment_added"
+	AT_JOIN_RESPONDED      = "join_responded"
+	AT_MEMBER_JOINED       = "member_joined"
+)
+
+var activityTypes = []string{
+	AT_TASK_CREATED,
+	AT_TASK_STATUS_CHANGED,
+	AT_COMMENT_ADDED,
+	AT_JOIN_RESPONDED,
+	AT_MEMBER_JOINED,
+}
+
+type TaskBody struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type TaskCreated struct {
+	Task TaskBody `json:"task"`
+}
+
+type TaskStatusChanged struct {
+	Task TaskBody `json:"task"`
+	To   string   `json:"to"`
+}
+
+type CommentAdded struct {
+	Task      TaskBody `json:"task"`
+	CommentID string   `json:"comment_id"`
+}
+
+type JoinResponded struct {
+	Requestor core.Avatar `json:"requestor"`
+	Status    string      `json:"status"`
+}
+
+type MemberJoined struct {
+	ApprovedBy core.Avatar `json:"approved_by"`
+}
+
+type Activity struct {
+	ID        string      `json:"id"`
+	ProjectID string      `json:"project_id"`
+	Type      string      `json:"type"`
+	Body      any         `json:"body"`
+	CreatedAt time.Time   `json:"created_at"`
+	Actor     core.Avatar `json:"actor"`
+}
+
+type ActivityService struct {
+	activityRepo *ActivityRepository
+	memberRepo   *members.MemberRepository
+}
+
+func NewActivityService(
+	activityRepo *ActivityRepository,
+	memberRepo *members.MemberRepository,
+) *ActivityService {
+	return &ActivityService{
+		activityRepo: activityRepo,
+		memberRepo:   memberRepo,
+	}
+}
+
+func (s *ActivityService) List(ctx context.Context,
+	projectID, userID string,
+	query ActivityListQuery) ([]Activity, string, error) {
+
+	err := core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_VIEW_PROJECT)
+	if err != nil {
+		return nil, "", fmt.Errorf("authorize view project: %w", err)
+	}
+
+	if query.Limit <= 0 || query.Limit > core.MAX_LIST_LIMIT {
+		return nil, "", core.ErrInvalidValue
+	}
+	for _, t := range query.Types {
+		if !slices.Contains(activityTypes, t) {
+			return nil, "", core.ErrInvalidValue
+		}
+	}
+
+	rows, nextCursor, err := s.activityRepo.List(ctx, projectID, query)
+	if err != nil {
+		return nil, "", fmt.Errorf("activity repository List: %w", err)
+	}
+
+	activities := []Activity{}
+	for _, r := range rows {
+		var body any
+		json.Unmarshal(r.Body, &body)
+		activities = append(activities, Activity{
+			ID:        r.ID,
+			ProjectID: r.ProjectID,
+			Type:      r.Type,
+			Body:      body,
+			CreatedAt: r.CreatedAt,
+			Actor: core.Avatar{
+				UserID:      r.ActorID,
+				Username:    r.Username,
+				DisplayName: r.DisplayName,
+				Email:       r.Email,
+				AvatarURL:   r.AvatarURL,
+			},
+		})
+	}
+
+	return activities, nextCursor, nil
+}
@@ -0,0 +1,156 @@
+package activity
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/members"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/ptracker/testhelpers/fixtures"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var USER_ONE, USER_TWO, USER_THREE string
+
+type activityServiceTestSuite struct {
+	suite.Suite
+	ctx         context.Context
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	fixtures    *fixtures.Fixtures
+	service     *ActivityService
+}
+
+func TestActivityService(t *testing.T) {
+	suite.Run(t, new(activityServiceTestSuite))
+}
+
+func (suite *activityServiceTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx = context.Background()
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	activityRepo := NewActivityRepository(suite.db)
+	memberRepo := members.NewMemberRepository(suite.db)
+	suite.service = NewActivityService(activityRepo, memberRepo)
+
+	suite.fixtures = fixtures.New(suite.ctx, suite.db)
+
+	USER_ONE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_TWO = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_THREE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+}
+
+func (suite *activityServiceTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM projects").Error
+	suite.Require().NoError(err)
+}
+
+func (suite *activityServiceTestSuite) TestList() {
+	t := suite.T()
+
+	t.Run("should list newest activity first for viewer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_VIEWER))
+		now := time.Now()
+		older := suite.fixtures.InsertActivity(fixtures.GetActivityRow(p, USER_ONE,
+			AT_TASK_CREATED, TaskCreated{}, now.Add(-time.Hour)))
+		newer := suite.fixtures.InsertActivity(fixtures.GetActivityRow(p, USER_ONE,
+			AT_COMMENT_ADDED, CommentAdded{}, now))
+
+		rows, _, err := suite.service.List(suite.ctx, p, USER_TWO, ActivityListQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, len(rows))
+		suite.Require().Equal(newer, rows[0].ID)
+		suite.Require().Equal(older, rows[1].ID)
+		suite.Require().Equal(USER_ONE, rows[0].Actor.UserID)
+	})
+	t.Run("should paginate with cursor", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		now := time.Now()
+		for i := range 3 {
+			suite.fixtures.InsertActivity(fixtures.GetActivityRow(p, USER_ONE,
+				AT_TASK_CREATED, TaskCreated{}, now.Add(time.Duration(-i)*time.Minute)))
+		}
+
+		first, cursor, err1 := suite.service.List(suite.ctx, p, USER_ONE, ActivityListQuery{Limit: 2})
+		second, next, err2 := suite.service.List(suite.ctx, p, USER_ONE,
+			ActivityListQuery{Cursor: cursor, Limit: 2})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err1)
+		suite.Require().NoError(err2)
+		suite.Require().Equal(2, len(first))
+		suite.Require().NotEmpty(cursor)
+		suite.Require().Equal(1, len(second))
+		suite.Require().Empty(next)
+	})
+	t.Run("should filter by actor and type", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		now := time.Now()
+		suite.fixtures.InsertActivity(fixtures.GetActivityRow(p, USER_ONE,
+			AT_TASK_CREATED, TaskCreated{}, now))
+		suite.fixtures.InsertActivity(fixtures.GetActivityRow(p, USER_TWO,
+			AT_TASK_CREATED, TaskCreated{}, now))
+		suite.fixtures.InsertActivity(fixtures.GetActivityRow(p, USER_TWO,
+			AT_COMMENT_ADDED, CommentAdded{}, now))
+
+		byActor, _, err1 := suite.service.List(suite.ctx, p, USER_ONE,
+			ActivityListQuery{Limit: 10, ActorID: &USER_TWO})
+		byType, _, err2 := suite.service.List(suite.ctx, p, USER_ONE,
+			ActivityListQuery{Limit: 10, Types: []string{AT_TASK_CREATED}})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err1)
+		suite.Require().NoError(err2)
+		suite.Require().Equal(2, len(byActor))
+		suite.Require().Equal(2, len(byType))
+	})
+	t.Run("should return invalid value for unknown type", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, _, err := suite.service.List(suite.ctx, p, USER_ONE,
+			ActivityListQuery{Limit: 10, Types: []string{"unknown"}})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should return forbidden when requester is not a member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, _, err := suite.service.List(suite.ctx, p, USER_THREE, ActivityListQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ptracker/activity"
+	"github.com/ptracker/core"
+)
+
+type ListedActivities struct {
+	Activities []activity.Activity `json:"activities"`
+	NextCursor string              `json:"next_cursor"`
+	Limit      int                 `json:"limit"`
+	HasNext    bool                `json:"has_next"`
+}
+
+type ActivityApi struct {
+	activityService *activity.ActivityService
+}
+
+func NewActivityApi(activityService *activity.ActivityService) *ActivityApi {
+	return &ActivityApi{
+		activityService: activityService,
+	}
+}
+
+func (api *ActivityApi) List(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	page, err := QueryPage(r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	values := r.URL.Query()
+	query := activity.ActivityListQuery{
+		Cursor: page.Cursor,
+		Limit:  page.Limit,
+	}
+	if actor := values.Get("actor"); actor != "" {
+		query.ActorID = &actor
+	}
+	for _, aType := range values["type"] {
+		for t := range strings.SplitSeq(aType, ",") {
+			if t != "" {
+				query.Types = append(query.Types, t)
+			}
+		}
+	}
+
+	activities, nextCursor, err := api.activityService.List(
+		r.Context(),
+		projectID,
+		userID,
+		query,
+	)
+	if err != nil {
+		return fmt.Errorf("activity service list: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[ListedActivities]{
+		Status: RESPONSE_SUCCESS_STATUS,
+		Data: &ListedActivities{
+			Activities: activities,
+			NextCursor: nextCursor,
+			Limit:      page.Limit,
+			HasNext:    nextCursor != "",
+		},
+	})
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/ptracker/auth"
+	"github.com/ptracker/boards"
+	"github.com/ptracker/core"
+)
+
+type BoardTicketResponse struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type BoardApi struct {
+	hub          *boards.Hub
+	tokenService *auth.TokenService
+
+	// hosts of the frontends allowed to open the websocket
+	originPatterns []string
+}
+
+func NewBoardApi(
+	hub *boards.Hub,
+	tokenService *auth.TokenService,
+	originPatterns []string,
+) *BoardApi {
+	return &BoardApi{
+		hub:            hub,
+		tokenService:   tokenService,
+		originPatterns: originPatterns,
+	}
+}
+
+/*
+Creates the ticket opening the board websocket
+
+A browser can not set the Authorization header of a websocket handshake,
+the ticket is sent in its query string instead.
+*/
+func (api *BoardApi) Ticket(w http.ResponseWriter, r *http.Request) error {
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	ticket, err := api.tokenService.CreateTicket(r.Context(), userID)
+	if err != nil {
+		return fmt.Errorf("token service create ticket: %w", err)
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[BoardTicketResponse]{
+		Status: RESPONSE_SUCCESS_STATUS,
+		Data: &BoardTicketResponse{
+			Ticket:    ticket.Value,
+			ExpiresAt: ticket.ExpiresAt,
+		},
+	})
+
+	return nil
+}
+
+/*
+Streams the live changes of the project board over a websocket
+
+The viewer is authenticated by the ticket query parameter, created by
+Ticket. Only the members of the project can connect. The presence of the viewer is
+refreshed along with the ping sent every BOARD_PING_INTERVAL.
+*/
+func (api *BoardApi) Connect(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	ticket := r.URL.Query().Get("ticket")
+	if ticket == "" {
+		return fmt.Errorf("ticket missing: %w", core.ErrUnauthorized)
+	}
+
+	userID, err := api.tokenService.RedeemTicket(r.Context(), ticket)
+	if err != nil {
+		return fmt.Errorf("token service redeem ticket: %w", err)
+	}
+
+	viewer, err := api.hub.Join(r.Context(), projectID, userID)
+	if err != nil {
+		return fmt.Errorf("hub join: %w", err)
+	}
+	defer api.hub.Leave(context.Background(), viewer)
+
+	// the connection outlives the server read and write timeouts
+	rc := http.NewResponseController(w)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("response controller SetReadDeadline: %w", err)
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("response controller SetWriteDeadline: %w", err)
+	}
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		OriginPatterns: api.originPatterns,
+	})
+	if err != nil {
+		// accept already wrote the response
+		log.Printf("[ERROR] websocket accept: %s", err)
+		return nil
+	}
+	defer conn.CloseNow()
+
+	// viewers only listen, ctx is done once the client closes
+	ctx := conn.CloseRead(r.Context())
+
+	ping := time.NewTicker(BOARD_PING_INTERVAL)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ping.C:
+			pingCtx, cancel := context.WithTimeout(ctx, BOARD_WRITE_TIMEOUT)
+			err = conn.Ping(pingCtx)
+			cancel()
+			if err != nil {
+				return nil
+			}
+
+			err = api.hub.Refresh(ctx, viewer)
+			if err != nil {
+				log.Printf("[ERROR] hub refresh: %s", err)
+			}
+		case e, ok := <-viewer.Events:
+			if !ok {
+				conn.Close(websocket.StatusPolicyViolation, "viewer too slow")
+				return nil
+			}
+
+			writeCtx, cancel := context.WithTimeout(ctx, BOARD_WRITE_TIMEOUT)
+			err = wsjson.Write(writeCtx, conn, e)
+			cancel()
+			if err != nil {
+				return nil
+			}
+		}
+	}
+}
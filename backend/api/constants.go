@@ -1,5 +1,7 @@
 package api
 
+import "time"
+
 const (
 	RESPONSE_SUCCESS_STATUS = "success"
 	RESPONSE_ERROR_STATUS   = "error"
@@ -22,3 +24,14 @@ const (
 const (
 	REFRESH_TOKEN_COOKIE_NAME = "TOKEN"
 )
+
+const (
+	// keeps idle notification streams open through proxies
+	STREAM_HEARTBEAT_INTERVAL = 15 * time.Second
+)
+
+const (
+	// pings the board viewers, well within boards.PRESENCE_TTL
+	BOARD_PING_INTERVAL = 20 * time.Second
+	BOARD_WRITE_TIMEOUT = 5 * time.Second
+)
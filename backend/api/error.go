@@ -55,6 +55,18 @@ func (fn HTTPErrorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				Status:  RESPONSE_ERROR_STATUS,
 				Message: "Duplicate resource found while processing the request",
 			})
+		} else if errors.Is(err, core.ErrConflict) {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(HTTPErrorResponse{
+				Status:  RESPONSE_ERROR_STATUS,
+				Message: "Request conflicts with the current state of the resource",
+			})
+		} else if errors.Is(err, core.ErrPreconditionFailed) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			json.NewEncoder(w).Encode(HTTPErrorResponse{
+				Status:  RESPONSE_ERROR_STATUS,
+				Message: "Resource was changed since it was last read",
+			})
 		} else {
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(HTTPErrorResponse{
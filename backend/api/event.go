@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/events"
+)
+
+type ListedEvents struct {
+	Events     []events.Event `json:"events"`
+	NextCursor string         `json:"next_cursor"`
+	Limit      int            `json:"limit"`
+	HasNext    bool           `json:"has_next"`
+}
+
+type EventApi struct {
+	eventService *events.EventService
+}
+
+func NewEventApi(eventService *events.EventService) *EventApi {
+	return &EventApi{
+		eventService: eventService,
+	}
+}
+
+func (api *EventApi) History(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("project_id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	taskID := r.PathValue("task_id")
+	if taskID == "" {
+		return core.ErrInvalidValue
+	}
+
+	page, err := QueryPage(r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	events, nextCursor, err := api.eventService.History(
+		r.Context(),
+		projectID,
+		taskID,
+		userID,
+		page,
+	)
+	if err != nil {
+		return fmt.Errorf("event service history: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[ListedEvents]{
+		Status: RESPONSE_SUCCESS_STATUS,
+		Data: &ListedEvents{
+			Events:     events,
+			NextCursor: nextCursor,
+			Limit:      page.Limit,
+			HasNext:    nextCursor != "",
+		},
+	})
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/labels"
+)
+
+type CreateLabelRequest struct {
+	Name  string `json:"name" validate:"required"`
+	Color string `json:"color" validate:"required"`
+}
+
+type UpdateLabelRequest struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+type ListedLabels struct {
+	Labels []labels.Label `json:"labels"`
+}
+
+type LabelApi struct {
+	labelService *labels.LabelService
+}
+
+func NewLabelApi(labelService *labels.LabelService) *LabelApi {
+	return &LabelApi{
+		labelService: labelService,
+	}
+}
+
+func (api *LabelApi) Create(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	var payload CreateLabelRequest
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&payload); err != nil {
+		return fmt.Errorf("payload decode: %w", core.ErrInvalidValue)
+	}
+	if err := validator.New().Struct(payload); err != nil {
+		return fmt.Errorf("payload validation: %w", core.ErrInvalidValue)
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	labelID, err := api.labelService.Create(r.Context(),
+		projectID,
+		userID,
+		payload.Name,
+		payload.Color,
+	)
+	if err != nil {
+		return fmt.Errorf("label service create: %w", err)
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[string]{
+		Status: RESPONSE_SUCCESS_STATUS,
+		Data:   &labelID,
+	})
+
+	return nil
+}
+
+func (api *LabelApi) List(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	labels, err := api.labelService.List(r.Context(), projectID, userID)
+	if err != nil {
+		return fmt.Errorf("label service list: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[ListedLabels]{
+		Status: RESPONSE_SUCCESS_STATUS,
+		Data: &ListedLabels{
+			Labels: labels,
+		},
+	})
+
+	return nil
+}
+
+func (api *LabelApi) Update(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	labelID := r.PathValue("label_id")
+	if labelID == "" {
+		return core.ErrInvalidValue
+	}
+
+	var payload UpdateLabelRequest
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&payload); err != nil {
+		return fmt.Errorf("payload decode: %w", core.ErrInvalidValue)
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	err = api.labelService.Update(r.Context(),
+		projectID,
+		labelID,
+		userID,
+		payload.Name,
+		payload.Color,
+	)
+	if err != nil {
+		return fmt.Errorf("label service update: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Label updated successfully",
+	})
+
+	return nil
+}
+
+func (api *LabelApi) Delete(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	labelID := r.PathValue("label_id")
+	if labelID == "" {
+		return core.ErrInvalidValue
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	err = api.labelService.Delete(r.Context(), projectID, labelID, userID)
+	if err != nil {
+		return fmt.Errorf("label service delete: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Label deleted successfully",
+	})
+
+	return nil
+}
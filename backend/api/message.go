@@ -4,37 +4,55 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/ptracker/core"
 	"github.com/ptracker/notifications"
 )
 
 type ListedMessages struct {
-	Messages []notifications.Notification `json:"messages"`
+	Messages   []notifications.Notification `json:"messages"`
+	NextCursor string                       `json:"next_cursor"`
+	Limit      int                          `json:"limit"`
+	HasNext    bool                         `json:"has_next"`
+}
+
+type UpdatePreferencesRequest struct {
+	Channels map[string]string `json:"channels" validate:"required"`
 }
 
 type MessageApi struct {
 	notificationService *notifications.NotificationService
+	broker              *notifications.Broker
 }
 
 func NewMessageApi(
 	notificationService *notifications.NotificationService,
+	broker *notifications.Broker,
 ) *MessageApi {
 	return &MessageApi{
 		notificationService: notificationService,
+		broker:              broker,
 	}
 }
 
 func (api *MessageApi) List(w http.ResponseWriter, r *http.Request) error {
 
+	page, err := QueryPage(r)
+	if err != nil {
+		return err
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		return fmt.Errorf("get user Id: %w", err)
 	}
 
-	notifications, err := api.notificationService.List(
+	notifications, nextCursor, err := api.notificationService.List(
 		r.Context(),
 		userID,
+		page,
 	)
 	if err != nil {
 		return fmt.Errorf("notification service List: %w", err)
@@ -42,13 +60,108 @@ func (api *MessageApi) List(w http.ResponseWriter, r *http.Request) error {
 
 	json.NewEncoder(w).Encode(HTTPSuccessResponse[ListedMessages]{
 		Data: &ListedMessages{
-			Messages: notifications,
+			Messages:   notifications,
+			NextCursor: nextCursor,
+			Limit:      page.Limit,
+			HasNext:    nextCursor != "",
 		},
 	})
 
 	return nil
 }
 
+// Writes the notification as a server-sent event, its ID is the event id
+func writeMessageEvent(w http.ResponseWriter, n notifications.Notification) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("json marshal: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: message\ndata: %s\n\n", n.ID, data)
+	return err
+}
+
+/*
+Streams the notifications of the user as server-sent events
+
+A client reconnecting with the Last-Event-ID header first receives the
+notifications it missed. A comment is sent every STREAM_HEARTBEAT_INTERVAL
+to keep the connection open.
+*/
+func (api *MessageApi) Stream(w http.ResponseWriter, r *http.Request) error {
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get user Id: %w", err)
+	}
+
+	// subscribed before reading the missed ones, so none falls in between
+	live, unsubscribe := api.broker.Subscribe(userID)
+	defer unsubscribe()
+
+	sent := map[string]bool{}
+	missed := []notifications.Notification{}
+	if lastID := r.Header.Get("Last-Event-ID"); lastID != "" {
+		missed, err = api.notificationService.Missed(r.Context(), userID, lastID)
+		if err != nil {
+			return fmt.Errorf("notification service Missed: %w", err)
+		}
+	}
+
+	rc := http.NewResponseController(w)
+
+	// the stream outlives the server write timeout
+	err = rc.SetWriteDeadline(time.Time{})
+	if err != nil {
+		return fmt.Errorf("response controller SetWriteDeadline: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, n := range missed {
+		if writeMessageEvent(w, n) != nil {
+			return nil
+		}
+		sent[n.ID] = true
+	}
+	if rc.Flush() != nil {
+		return nil
+	}
+
+	heartbeat := time.NewTicker(STREAM_HEARTBEAT_INTERVAL)
+	defer heartbeat.Stop()
+
+	// write errors mean the client is gone, the response is already started
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		case n, ok := <-live:
+			if !ok {
+				return nil
+			}
+			if sent[n.ID] {
+				continue
+			}
+			if writeMessageEvent(w, n) != nil {
+				return nil
+			}
+		}
+
+		if rc.Flush() != nil {
+			return nil
+		}
+	}
+}
+
 func (api *MessageApi) MarkAsRead(w http.ResponseWriter, r *http.Request) error {
 
 	notificationID := r.PathValue("id")
@@ -76,3 +189,116 @@ func (api *MessageApi) MarkAsRead(w http.ResponseWriter, r *http.Request) error
 
 	return nil
 }
+
+func (api *MessageApi) GetPreferences(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get user Id: %w", err)
+	}
+
+	preferences, err := api.notificationService.Preferences(
+		r.Context(),
+		projectID,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("notification service Preferences: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[notifications.Preferences]{
+		Status: RESPONSE_SUCCESS_STATUS,
+		Data:   preferences,
+	})
+
+	return nil
+}
+
+func (api *MessageApi) UpdatePreferences(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	var payload UpdatePreferencesRequest
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&payload); err != nil {
+		return core.ErrInvalidValue
+	}
+	if err := validator.New().Struct(payload); err != nil {
+		return core.ErrInvalidValue
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get user Id: %w", err)
+	}
+
+	err = api.notificationService.UpdatePreferences(
+		r.Context(),
+		projectID,
+		userID,
+		payload.Channels,
+	)
+	if err != nil {
+		return fmt.Errorf("notification service UpdatePreferences: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Notification preferences updated",
+	})
+
+	return nil
+}
+
+func (api *MessageApi) Mute(w http.ResponseWriter, r *http.Request) error {
+	return api.setMuted(w, r, true)
+}
+
+func (api *MessageApi) Unmute(w http.ResponseWriter, r *http.Request) error {
+	return api.setMuted(w, r, false)
+}
+
+func (api *MessageApi) setMuted(w http.ResponseWriter, r *http.Request, muted bool) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get user Id: %w", err)
+	}
+
+	err = api.notificationService.SetMuted(
+		r.Context(),
+		projectID,
+		userID,
+		muted,
+	)
+	if err != nil {
+		return fmt.Errorf("notification service SetMuted: %w", err)
+	}
+
+	message := "Project muted"
+	if !muted {
+		message = "Project unmuted"
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: message,
+	})
+
+	return nil
+}
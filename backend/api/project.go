@@ -4,9 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
-	"strconv"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/ptracker/core"
@@ -14,7 +12,6 @@ import (
 	"github.com/ptracker/core/projects"
 	"github.com/ptracker/core/requests"
 	"github.com/ptracker/core/users"
-	"github.com/ptracker/notifications"
 )
 
 type CreateProjectRequest struct {
@@ -23,6 +20,12 @@ type CreateProjectRequest struct {
 	Skills      *string `json:"skills"`
 }
 
+type UpdateProjectRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Skills      *string `json:"skills"`
+}
+
 type ProjectDetail struct {
 	projects.ProjectSummary
 	Role        string      `json:"role"`
@@ -37,17 +40,17 @@ type PublicProjectDetail struct {
 }
 
 type ListedProjectSummaries struct {
-	Projects []projects.ProjectSummary `json:"projects"`
-	Page     int                       `json:"page"`
-	Limit    int                       `json:"limit"`
-	HasNext  bool                      `json:"has_next"`
+	Projects   []projects.ProjectSummary `json:"projects"`
+	NextCursor string                    `json:"next_cursor"`
+	Limit      int                       `json:"limit"`
+	HasNext    bool                      `json:"has_next"`
 }
 
 type ListedProjectPreviews struct {
-	Projects []projects.ProjectPreview `json:"projects"`
-	Page     int                       `json:"page"`
-	Limit    int                       `json:"limit"`
-	HasNext  bool                      `json:"has_next"`
+	Projects   []projects.ProjectPreview `json:"projects"`
+	NextCursor string                    `json:"next_cursor"`
+	Limit      int                       `json:"limit"`
+	HasNext    bool                      `json:"has_next"`
 }
 
 type ListedJoinRequests struct {
@@ -58,17 +61,28 @@ type ListedMembers struct {
 	Members []members.Member `json:"members"`
 }
 
+type TransferOwnershipRequest struct {
+	UserID string `json:"user_id" validate:"required"`
+}
+
+type ChangeRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+type ListedBans struct {
+	Bans []members.Ban `json:"bans"`
+}
+
 type UpdateJoinRequest struct {
 	UserID     string `json:"user_id" validate:"required"`
 	JoinStatus string `json:"join_status" validate:"required"`
 }
 
 type ProjectApi struct {
-	projectService      *projects.ProjectService
-	userService         *users.UserService
-	memberService       *members.MemberService
-	joinRequestService  *requests.JoinRequestService
-	notificationService *notifications.NotificationService
+	projectService     *projects.ProjectService
+	userService        *users.UserService
+	memberService      *members.MemberService
+	joinRequestService *requests.JoinRequestService
 }
 
 func NewProjectApi(
@@ -76,14 +90,12 @@ func NewProjectApi(
 	userService *users.UserService,
 	memberService *members.MemberService,
 	joinRequestService *requests.JoinRequestService,
-	notificationService *notifications.NotificationService,
 ) *ProjectApi {
 	return &ProjectApi{
-		projectService:      projectService,
-		userService:         userService,
-		memberService:       memberService,
-		joinRequestService:  joinRequestService,
-		notificationService: notificationService,
+		projectService:     projectService,
+		userService:        userService,
+		memberService:      memberService,
+		joinRequestService: joinRequestService,
 	}
 }
 
@@ -146,7 +158,9 @@ func (api *ProjectApi) Get(w http.ResponseWriter, r *http.Request) error {
 		return fmt.Errorf("member service get role: %w", err)
 	}
 
-	if role == core.ROLE_MEMBER || role == core.ROLE_OWNER {
+	SetETag(w, projectSummary.Version)
+
+	if core.HasPermission(role, core.PERMISSION_VIEW_PROJECT) {
 		memberCount, err := api.memberService.Count(r.Context(), projectID, userID)
 		if err != nil {
 			return fmt.Errorf("member service count: %w", err)
@@ -194,27 +208,9 @@ func (api *ProjectApi) Get(w http.ResponseWriter, r *http.Request) error {
 }
 
 func (api *ProjectApi) ListMyProjects(w http.ResponseWriter, r *http.Request) error {
-	queryPage := r.URL.Query().Get("page")
-	queryLimit := r.URL.Query().Get("limit")
-
-	var page, limit int
-	if queryPage != "" {
-		var err error
-		page, err = strconv.Atoi(queryPage)
-		if err != nil {
-			return core.ErrInvalidValue
-		}
-	} else {
-		page = 1
-	}
-	if queryLimit != "" {
-		var err error
-		limit, err = strconv.Atoi(queryLimit)
-		if err != nil {
-			return core.ErrInvalidValue
-		}
-	} else {
-		limit = 10
+	page, err := QueryPage(r)
+	if err != nil {
+		return err
 	}
 
 	userID, err := GetUserID(r)
@@ -222,7 +218,7 @@ func (api *ProjectApi) ListMyProjects(w http.ResponseWriter, r *http.Request) er
 		return fmt.Errorf("get userID: %w", err)
 	}
 
-	summaries, err := api.projectService.MyProjects(r.Context(), userID)
+	summaries, nextCursor, err := api.projectService.MyProjects(r.Context(), userID, page)
 	if err != nil {
 		return fmt.Errorf("project service my projects: %w", err)
 	}
@@ -230,9 +226,10 @@ func (api *ProjectApi) ListMyProjects(w http.ResponseWriter, r *http.Request) er
 	json.NewEncoder(w).Encode(HTTPSuccessResponse[ListedProjectSummaries]{
 		Status: RESPONSE_SUCCESS_STATUS,
 		Data: &ListedProjectSummaries{
-			Projects: summaries,
-			Page:     page,
-			Limit:    limit,
+			Projects:   summaries,
+			NextCursor: nextCursor,
+			Limit:      page.Limit,
+			HasNext:    nextCursor != "",
 		},
 	})
 
@@ -240,27 +237,9 @@ func (api *ProjectApi) ListMyProjects(w http.ResponseWriter, r *http.Request) er
 }
 
 func (api *ProjectApi) ListPublic(w http.ResponseWriter, r *http.Request) error {
-	queryPage := r.URL.Query().Get("page")
-	queryLimit := r.URL.Query().Get("limit")
-
-	var page, limit int
-	if queryPage != "" {
-		var err error
-		page, err = strconv.Atoi(queryPage)
-		if err != nil {
-			return core.ErrInvalidValue
-		}
-	} else {
-		page = 1
-	}
-	if queryLimit != "" {
-		var err error
-		limit, err = strconv.Atoi(queryLimit)
-		if err != nil {
-			return core.ErrInvalidValue
-		}
-	} else {
-		limit = 10
+	page, err := QueryPage(r)
+	if err != nil {
+		return err
 	}
 
 	userID, err := GetUserID(r)
@@ -268,7 +247,7 @@ func (api *ProjectApi) ListPublic(w http.ResponseWriter, r *http.Request) error
 		return fmt.Errorf("get userID: %w", err)
 	}
 
-	projects, err := api.projectService.ListPublic(r.Context(), userID)
+	projects, nextCursor, err := api.projectService.ListPublic(r.Context(), userID, page)
 	if err != nil {
 		return fmt.Errorf("project service list public: %w", err)
 	}
@@ -276,9 +255,10 @@ func (api *ProjectApi) ListPublic(w http.ResponseWriter, r *http.Request) error
 	json.NewEncoder(w).Encode(HTTPSuccessResponse[ListedProjectPreviews]{
 		Status: RESPONSE_SUCCESS_STATUS,
 		Data: &ListedProjectPreviews{
-			Projects: projects,
-			Page:     page,
-			Limit:    limit,
+			Projects:   projects,
+			NextCursor: nextCursor,
+			Limit:      page.Limit,
+			HasNext:    nextCursor != "",
 		},
 	})
 	return nil
@@ -373,15 +353,6 @@ func (api *ProjectApi) AddJoinRequest(w http.ResponseWriter, r *http.Request) er
 		return fmt.Errorf("join request service create: %w", err)
 	}
 
-	err = api.notificationService.JoinRequested(
-		r.Context(),
-		projectID,
-		userID,
-	)
-	if err != nil {
-		log.Printf("[ERROR] notification service JoinRequested: %s", err)
-	}
-
 	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
 		Status:  RESPONSE_SUCCESS_STATUS,
 		Message: "Join request created",
@@ -460,19 +431,278 @@ func (api *ProjectApi) RespondToJoinRequest(w http.ResponseWriter, r *http.Reque
 		return fmt.Errorf("join request service respond: %w", err)
 	}
 
-	err = api.notificationService.JoinResponded(
-		r.Context(),
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Join request status updated",
+	})
+
+	return nil
+}
+
+func (api *ProjectApi) Update(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	var payload UpdateProjectRequest
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&payload); err != nil {
+		return fmt.Errorf("payload decode: %w", core.ErrInvalidValue)
+	}
+
+	version, err := IfMatchVersion(r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	newVersion, err := api.projectService.Update(r.Context(),
+		projectID,
+		userID,
+		payload.Name,
+		payload.Description,
+		payload.Skills,
+		version,
+	)
+	if err != nil {
+		return fmt.Errorf("project service update: %w", err)
+	}
+
+	SetETag(w, newVersion)
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Project updated successfully",
+	})
+
+	return nil
+}
+
+func (api *ProjectApi) RemoveMember(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	memberID := r.PathValue("user_id")
+	if memberID == "" {
+		return core.ErrInvalidValue
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	err = api.memberService.Remove(r.Context(), projectID, userID, memberID)
+	if err != nil {
+		return fmt.Errorf("member service remove: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Member removed successfully",
+	})
+
+	return nil
+}
+
+func (api *ProjectApi) ChangeRole(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	memberID := r.PathValue("user_id")
+	if memberID == "" {
+		return core.ErrInvalidValue
+	}
+
+	var payload ChangeRoleRequest
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&payload); err != nil {
+		return fmt.Errorf("payload decode: %w", core.ErrInvalidValue)
+	}
+	if err := validator.New().Struct(payload); err != nil {
+		return fmt.Errorf("payload validation: %w", core.ErrInvalidValue)
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	err = api.memberService.ChangeRole(r.Context(),
+		projectID,
+		userID,
+		memberID,
+		payload.Role,
+	)
+	if err != nil {
+		return fmt.Errorf("member service change role: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Member role updated successfully",
+	})
+
+	return nil
+}
+
+func (api *ProjectApi) Leave(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	err = api.memberService.Leave(r.Context(), projectID, userID)
+	if err != nil {
+		return fmt.Errorf("member service leave: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Left the project successfully",
+	})
+
+	return nil
+}
+
+func (api *ProjectApi) ListBans(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	bans, err := api.memberService.Bans(r.Context(), projectID, userID)
+	if err != nil {
+		return fmt.Errorf("member service bans: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[ListedBans]{
+		Status: RESPONSE_SUCCESS_STATUS,
+		Data: &ListedBans{
+			Bans: bans,
+		},
+	})
+
+	return nil
+}
+
+func (api *ProjectApi) Unban(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	bannedID := r.PathValue("user_id")
+	if bannedID == "" {
+		return core.ErrInvalidValue
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	err = api.memberService.Unban(r.Context(), projectID, userID, bannedID)
+	if err != nil {
+		return fmt.Errorf("member service unban: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Ban lifted successfully",
+	})
+
+	return nil
+}
+
+func (api *ProjectApi) TransferOwnership(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	var payload TransferOwnershipRequest
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&payload); err != nil {
+		return fmt.Errorf("payload decode: %w", core.ErrInvalidValue)
+	}
+	if err := validator.New().Struct(payload); err != nil {
+		return fmt.Errorf("payload validation: %w", core.ErrInvalidValue)
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	err = api.projectService.TransferOwnership(r.Context(),
 		projectID,
+		userID,
 		payload.UserID,
-		payload.JoinStatus,
 	)
 	if err != nil {
-		log.Printf("[ERROR] notification service JoinResponded: %s", err)
+		return fmt.Errorf("project service transfer ownership: %w", err)
 	}
 
 	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
 		Status:  RESPONSE_SUCCESS_STATUS,
-		Message: "Join request status updated",
+		Message: "Project ownership transferred successfully",
+	})
+
+	return nil
+}
+
+func (api *ProjectApi) Delete(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	_, err = api.projectService.Delete(r.Context(), projectID, userID)
+	if err != nil {
+		return fmt.Errorf("project service delete: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Project deleted successfully",
 	})
 
 	return nil
@@ -5,45 +5,60 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/ptracker/boards"
 	"github.com/ptracker/core"
 	"github.com/ptracker/core/assignees"
 	"github.com/ptracker/core/comments"
 	"github.com/ptracker/core/tasks"
-	"github.com/ptracker/notifications"
 )
 
 type CreateTaskRequest struct {
-	Title       string   `json:"title" validate:"required"`
-	Description string   `json:"description" validate:"required"`
-	Assignees   []string `json:"assignees" validate:"required"`
-	Status      string   `json:"status" validate:"required"`
+	Title       string     `json:"title" validate:"required"`
+	Description string     `json:"description" validate:"required"`
+	Assignees   []string   `json:"assignees" validate:"required"`
+	Status      string     `json:"status" validate:"required"`
+	ParentID    *string    `json:"parent_id"`
+	Priority    string     `json:"priority"`
+	Estimate    int        `json:"estimate"`
+	StartDate   *time.Time `json:"start_date"`
+	DueDate     *time.Time `json:"due_date"`
 }
 
 type CreatedTaskResponse struct {
-	TaskID   string   `json:"task_id"`
-	Warnings []string `json:"warnings"`
+	TaskID string `json:"task_id"`
 }
 
 type UpdateTaskRequest struct {
-	Title             *string  `json:"title"`
-	Description       *string  `json:"description"`
-	Status            *string  `json:"status"`
-	AssigneesToAdd    []string `json:"assignees_to_add"`
-	AssigneesToRemove []string `json:"assignees_to_remove"`
-}
-
-type UpdateTaskResponse struct {
-	Warnings []string `json:"warnings"`
+	Title             *string    `json:"title"`
+	Description       *string    `json:"description"`
+	Status            *string    `json:"status"`
+	ParentID          *string    `json:"parent_id"` // empty string moves the task to the top level
+	Priority          *string    `json:"priority"`
+	Estimate          *int       `json:"estimate"`
+	StartDate         *time.Time `json:"start_date"`
+	DueDate           *time.Time `json:"due_date"`
+	ClearStartDate    bool       `json:"clear_start_date"` // removes the start date
+	ClearDueDate      bool       `json:"clear_due_date"`   // removes the due date
+	AssigneesToAdd    []string   `json:"assignees_to_add"`
+	AssigneesToRemove []string   `json:"assignees_to_remove"`
+	LabelsToAdd       []string   `json:"labels_to_add"`
+	LabelsToRemove    []string   `json:"labels_to_remove"`
+	BlockersToAdd     []string   `json:"blockers_to_add"`
+	BlockersToRemove  []string   `json:"blockers_to_remove"`
+
+	// completes the task even if some of its subtasks are still Ongoing
+	Force bool `json:"force"`
 }
 
 type ListedTasks struct {
-	Tasks   []tasks.ProjectTaskItem `json:"tasks"`
-	Page    int                     `json:"page"`
-	Limit   int                     `json:"limit"`
-	HasNext bool                    `json:"has_next"`
+	Tasks      []tasks.ProjectTaskItem `json:"tasks"`
+	NextCursor string                  `json:"next_cursor"`
+	Limit      int                     `json:"limit"`
+	HasNext    bool                    `json:"has_next"`
 }
 
 type ListedDashboardTasks struct {
@@ -54,35 +69,40 @@ type ListedDashboardTasks struct {
 }
 
 type AddCommentRequest struct {
-	UserId  string `json:"user_id"`
+	UserId   string  `json:"user_id"`
+	ParentID *string `json:"parent_id"`
+	Comment  string  `json:"comment"`
+}
+
+type UpdateCommentRequest struct {
 	Comment string `json:"comment"`
 }
 
 type ListedComments struct {
-	Comments []comments.Comment `json:"comments"`
-	Page     int                `json:"page"`
-	Limit    int                `json:"limit"`
-	HasNext  bool               `json:"has_next"`
+	Comments   []comments.Comment `json:"comments"`
+	NextCursor string             `json:"next_cursor"`
+	Limit      int                `json:"limit"`
+	HasNext    bool               `json:"has_next"`
 }
 
 type TaskApi struct {
-	taskService         *tasks.TaskService
-	assigneeService     *assignees.AssigneeService
-	commentService      *comments.CommentService
-	notificationService *notifications.NotificationService
+	taskService     *tasks.TaskService
+	assigneeService *assignees.AssigneeService
+	commentService  *comments.CommentService
+	hub             *boards.Hub
 }
 
 func NewTaskApi(
 	taskService *tasks.TaskService,
 	assigneeService *assignees.AssigneeService,
 	commentService *comments.CommentService,
-	notificationService *notifications.NotificationService,
+	hub *boards.Hub,
 ) *TaskApi {
 	return &TaskApi{
-		taskService:         taskService,
-		assigneeService:     assigneeService,
-		commentService:      commentService,
-		notificationService: notificationService,
+		taskService:     taskService,
+		assigneeService: assigneeService,
+		commentService:  commentService,
+		hub:             hub,
 	}
 }
 
@@ -115,40 +135,25 @@ func (api *TaskApi) Create(w http.ResponseWriter, r *http.Request) error {
 		userID,
 		payload.Title,
 		payload.Description,
-		payload.Status)
+		payload.Status,
+		payload.Priority,
+		payload.Estimate,
+		payload.ParentID,
+		payload.StartDate,
+		payload.DueDate,
+		payload.Assignees)
 	if err != nil {
 		return fmt.Errorf("service create task: %w", err)
 	}
 
-	err = api.notificationService.TaskAdded(
-		r.Context(),
-		projectID,
-		taskID,
-	)
+	// the board gets the task with its assignees
+	task, err := api.taskService.Get(r.Context(), projectID, taskID, userID)
 	if err != nil {
-		log.Printf("[ERROR] notification service TaskAdded: %s", err)
-	}
-
-	warnings := []string{}
-	for _, assignee := range payload.Assignees {
-		err = api.assigneeService.AddAssignee(r.Context(),
-			projectID,
-			taskID,
-			userID,
-			assignee)
+		log.Printf("[ERROR] task service Get: %s", err)
+	} else {
+		err = api.hub.TaskCreated(r.Context(), task, userID)
 		if err != nil {
-			warnings = append(warnings, err.Error())
-		} else {
-			err = api.notificationService.AssigneeUpdated(
-				r.Context(),
-				projectID,
-				taskID,
-				assignee,
-				true,
-			)
-			if err != nil {
-				log.Printf("[ERROR] notification service AssigneeUpdated: %s", err)
-			}
+			log.Printf("[ERROR] hub TaskCreated: %s", err)
 		}
 	}
 
@@ -156,8 +161,7 @@ func (api *TaskApi) Create(w http.ResponseWriter, r *http.Request) error {
 	json.NewEncoder(w).Encode(HTTPSuccessResponse[CreatedTaskResponse]{
 		Status: RESPONSE_SUCCESS_STATUS,
 		Data: &CreatedTaskResponse{
-			TaskID:   taskID,
-			Warnings: warnings,
+			TaskID: taskID,
 		},
 	})
 
@@ -190,6 +194,7 @@ func (api *TaskApi) Get(w http.ResponseWriter, r *http.Request) error {
 		return fmt.Errorf("service get task: %w", err)
 	}
 
+	SetETag(w, task.Version)
 	json.NewEncoder(w).Encode(HTTPSuccessResponse[tasks.ProjectTaskItem]{
 		Status: RESPONSE_SUCCESS_STATUS,
 		Data:   task,
@@ -221,138 +226,177 @@ func (api *TaskApi) Update(w http.ResponseWriter, r *http.Request) error {
 		return core.ErrInvalidValue
 	}
 
+	version, err := IfMatchVersion(r)
+	if err != nil {
+		return err
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		return fmt.Errorf("get userID: %w", err)
 	}
 
-	if payload.Title != nil || payload.Description != nil || payload.Status != nil {
-		err = api.taskService.Update(r.Context(),
-			projectID,
-			taskID,
-			userID,
-			payload.Title,
-			payload.Description,
-			payload.Status,
-		)
+	// the fields, assignees, labels and blockers change together or not at all
+	updated, err := api.taskService.Update(r.Context(),
+		projectID,
+		taskID,
+		userID,
+		tasks.TaskUpdate{
+			Title:             payload.Title,
+			Description:       payload.Description,
+			Status:            payload.Status,
+			Priority:          payload.Priority,
+			Estimate:          payload.Estimate,
+			ParentID:          payload.ParentID,
+			StartDate:         payload.StartDate,
+			DueDate:           payload.DueDate,
+			ClearStartDate:    payload.ClearStartDate,
+			ClearDueDate:      payload.ClearDueDate,
+			AssigneesToAdd:    payload.AssigneesToAdd,
+			AssigneesToRemove: payload.AssigneesToRemove,
+			LabelsToAdd:       payload.LabelsToAdd,
+			LabelsToRemove:    payload.LabelsToRemove,
+			BlockersToAdd:     payload.BlockersToAdd,
+			BlockersToRemove:  payload.BlockersToRemove,
+			Force:             payload.Force,
+			Version:           version,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("service task update: %w", err)
+	}
 
-		if err != nil {
-			return fmt.Errorf("service task update: %w", err)
-		} else {
-			err = api.notificationService.TaskUpdated(
-				r.Context(),
-				projectID,
-				taskID,
-				payload.Title,
-				payload.Description,
-				payload.Status,
-				userID,
-			)
-			if err != nil {
-				log.Printf("[ERROR] notification service TaskUpdated: %s", err)
-			}
-		}
+	err = api.hub.TaskUpdated(r.Context(), updated, userID)
+	if err != nil {
+		log.Printf("[ERROR] hub TaskUpdated: %s", err)
 	}
 
-	warnings := []string{}
 	for _, assignee := range payload.AssigneesToAdd {
-		err = api.assigneeService.AddAssignee(r.Context(),
-			projectID, taskID, userID, assignee)
+		err = api.hub.AssigneeUpdated(r.Context(), projectID, taskID, assignee, true, userID)
 		if err != nil {
-			warnings = append(warnings, err.Error())
-		} else {
-			err = api.notificationService.AssigneeUpdated(
-				r.Context(),
-				projectID,
-				taskID,
-				assignee,
-				true,
-			)
-			if err != nil {
-				log.Printf("[ERROR] notification service AssigneeUpdated: %s", err)
-			}
+			log.Printf("[ERROR] hub AssigneeUpdated: %s", err)
 		}
 	}
 	for _, assignee := range payload.AssigneesToRemove {
-		err = api.assigneeService.RemoveAssignee(r.Context(),
-			projectID, taskID, userID, assignee)
+		err = api.hub.AssigneeUpdated(r.Context(), projectID, taskID, assignee, false, userID)
 		if err != nil {
-			warnings = append(warnings, err.Error())
-		} else {
-			err = api.notificationService.AssigneeUpdated(
-				r.Context(),
-				projectID,
-				taskID,
-				assignee,
-				false,
-			)
-			if err != nil {
-				log.Printf("[ERROR] notification service AssigneeUpdated: %s", err)
-			}
+			log.Printf("[ERROR] hub AssigneeUpdated: %s", err)
 		}
 	}
 
-	json.NewEncoder(w).Encode(HTTPSuccessResponse[UpdateTaskResponse]{
-		Status: RESPONSE_SUCCESS_STATUS,
-		Data: &UpdateTaskResponse{
-			Warnings: warnings,
-		},
+	SetETag(w, updated.Task.Version)
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[tasks.UpdatedTask]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Data:    updated,
 		Message: "Task updated successfully",
 	})
 
 	return nil
 }
 
-func (api *TaskApi) List(w http.ResponseWriter, r *http.Request) error {
+func (api *TaskApi) Delete(w http.ResponseWriter, r *http.Request) error {
 
-	queryPage := r.URL.Query().Get("page")
-	queryLimit := r.URL.Query().Get("limit")
+	projectID := r.PathValue("project_id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
 
-	var page, limit int
-	if queryPage != "" {
-		var err error
-		page, err = strconv.Atoi(queryPage)
-		if err != nil {
-			return core.ErrInvalidValue
-		}
-	} else {
-		page = 1
+	taskID := r.PathValue("task_id")
+	if taskID == "" {
+		return core.ErrInvalidValue
 	}
-	if queryLimit != "" {
-		var err error
-		limit, err = strconv.Atoi(queryLimit)
-		if err != nil {
-			return core.ErrInvalidValue
-		}
-	} else {
-		limit = 10
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	_, err = api.taskService.Delete(r.Context(),
+		projectID,
+		taskID,
+		userID)
+	if err != nil {
+		return fmt.Errorf("service task delete: %w", err)
 	}
 
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Task deleted successfully",
+	})
+
+	return nil
+}
+
+func (api *TaskApi) List(w http.ResponseWriter, r *http.Request) error {
+
 	projectID := r.PathValue("project_id")
 	if projectID == "" {
 		return core.ErrInvalidValue
 	}
 
+	query, err := queryTaskList(r)
+	if err != nil {
+		return err
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		return fmt.Errorf("get context user: %w", err)
 	}
 
-	tasks, err := api.taskService.List(r.Context(), projectID, userID)
+	tasks, nextCursor, err := api.taskService.List(r.Context(), projectID, userID, query)
 	if err != nil {
 		return fmt.Errorf("service list tasks: %w", err)
 	}
 
-	// TODO: count total tasks and check has next
-	hasNext := false
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[ListedTasks]{
+		Status: RESPONSE_SUCCESS_STATUS,
+		Data: &ListedTasks{
+			Tasks:      tasks,
+			NextCursor: nextCursor,
+			Limit:      query.Limit,
+			HasNext:    nextCursor != "",
+		},
+	})
+
+	return nil
+}
+
+func (api *TaskApi) ListSubtasks(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("project_id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	taskID := r.PathValue("task_id")
+	if taskID == "" {
+		return core.ErrInvalidValue
+	}
+
+	query, err := queryTaskList(r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get context user: %w", err)
+	}
+
+	tasks, nextCursor, err := api.taskService.Subtasks(r.Context(),
+		projectID, taskID, userID, query)
+	if err != nil {
+		return fmt.Errorf("service list subtasks: %w", err)
+	}
 
 	json.NewEncoder(w).Encode(HTTPSuccessResponse[ListedTasks]{
 		Status: RESPONSE_SUCCESS_STATUS,
 		Data: &ListedTasks{
-			Tasks:   tasks,
-			Page:    page,
-			Limit:   limit,
-			HasNext: hasNext,
+			Tasks:      tasks,
+			NextCursor: nextCursor,
+			Limit:      query.Limit,
+			HasNext:    nextCursor != "",
 		},
 	})
 
@@ -392,26 +436,108 @@ func (api *TaskApi) AddComment(w http.ResponseWriter, r *http.Request) error {
 		projectID,
 		taskID,
 		userID,
+		payload.ParentID,
 		payload.Comment,
 	)
 	if err != nil {
 		return fmt.Errorf("comment service create: %w", err)
 	}
 
-	err = api.notificationService.CommentAdded(
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[string]{
+		Status: RESPONSE_SUCCESS_STATUS,
+		Data:   &commentId,
+	})
+
+	return nil
+}
+
+func (api *TaskApi) UpdateComment(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("project_id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	taskID := r.PathValue("task_id")
+	if taskID == "" {
+		return core.ErrInvalidValue
+	}
+
+	commentID := r.PathValue("comment_id")
+	if commentID == "" {
+		return core.ErrInvalidValue
+	}
+
+	var payload UpdateCommentRequest
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&payload); err != nil {
+		return core.ErrInvalidValue
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	err = api.commentService.Update(
 		r.Context(),
 		projectID,
 		taskID,
+		commentID,
 		userID,
+		payload.Comment,
 	)
 	if err != nil {
-		log.Printf("[ERROR] notification service CommentAdded: %s", err)
+		return fmt.Errorf("comment service update: %w", err)
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(HTTPSuccessResponse[string]{
-		Status: RESPONSE_SUCCESS_STATUS,
-		Data:   &commentId,
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Comment updated successfully",
+	})
+
+	return nil
+}
+
+func (api *TaskApi) DeleteComment(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("project_id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	taskID := r.PathValue("task_id")
+	if taskID == "" {
+		return core.ErrInvalidValue
+	}
+
+	commentID := r.PathValue("comment_id")
+	if commentID == "" {
+		return core.ErrInvalidValue
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	err = api.commentService.Delete(
+		r.Context(),
+		projectID,
+		taskID,
+		commentID,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("comment service delete: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Comment deleted successfully",
 	})
 
 	return nil
@@ -429,16 +555,29 @@ func (api *TaskApi) ListComments(w http.ResponseWriter, r *http.Request) error {
 		return core.ErrInvalidValue
 	}
 
+	page, err := QueryPage(r)
+	if err != nil {
+		return err
+	}
+
+	// replies of the comment, top level comments otherwise
+	var parentID *string
+	if parent := r.URL.Query().Get("parent"); parent != "" {
+		parentID = &parent
+	}
+
 	userID, err := GetUserID(r)
 	if err != nil {
 		return fmt.Errorf("get userID: %w", err)
 	}
 
-	comments, err := api.commentService.List(
+	comments, nextCursor, err := api.commentService.List(
 		r.Context(),
 		projectID,
 		taskID,
 		userID,
+		parentID,
+		page,
 	)
 	if err != nil {
 		return fmt.Errorf("comment service list: %w", err)
@@ -447,10 +586,10 @@ func (api *TaskApi) ListComments(w http.ResponseWriter, r *http.Request) error {
 	json.NewEncoder(w).Encode(HTTPSuccessResponse[ListedComments]{
 		Status: RESPONSE_SUCCESS_STATUS,
 		Data: &ListedComments{
-			Comments: comments,
-			Page:     1,
-			Limit:    len(comments),
-			HasNext:  false,
+			Comments:   comments,
+			NextCursor: nextCursor,
+			Limit:      page.Limit,
+			HasNext:    nextCursor != "",
 		},
 	})
 
@@ -508,3 +647,91 @@ func (api *TaskApi) ListUnassignedTasks(w http.ResponseWriter, r *http.Request)
 
 	return nil
 }
+
+func (api *TaskApi) ListDueTasks(w http.ResponseWriter, r *http.Request) error {
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	due, err := api.taskService.Due(r.Context(),
+		userID)
+	if err != nil {
+		return fmt.Errorf("task service due tasks: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[tasks.DueTasks]{
+		Status: RESPONSE_SUCCESS_STATUS,
+		Data:   due,
+	})
+
+	return nil
+}
+
+// Reads the pagination, filter and sort query parameters of a task list
+func queryTaskList(r *http.Request) (tasks.TaskListQuery, error) {
+	values := r.URL.Query()
+
+	page, err := QueryPage(r)
+	if err != nil {
+		return tasks.TaskListQuery{}, err
+	}
+
+	query := tasks.TaskListQuery{
+		Cursor: page.Cursor,
+		Limit:  page.Limit,
+		SortBy: values.Get("sort"),
+		Order:  values.Get("order"),
+	}
+
+	for _, status := range values["status"] {
+		for s := range strings.SplitSeq(status, ",") {
+			if s != "" {
+				query.Statuses = append(query.Statuses, s)
+			}
+		}
+	}
+	for _, priority := range values["priority"] {
+		for p := range strings.SplitSeq(priority, ",") {
+			if p != "" {
+				query.Priorities = append(query.Priorities, p)
+			}
+		}
+	}
+	for _, label := range values["label"] {
+		for l := range strings.SplitSeq(label, ",") {
+			if l != "" {
+				query.LabelIDs = append(query.LabelIDs, l)
+			}
+		}
+	}
+	if assignee := values.Get("assignee"); assignee != "" {
+		query.AssigneeID = &assignee
+	}
+	if parent := values.Get("parent"); parent != "" {
+		query.ParentID = &parent
+	}
+
+	if query.MinEstimate, err = QueryInt(r, "min_estimate"); err != nil {
+		return query, err
+	}
+	if query.MaxEstimate, err = QueryInt(r, "max_estimate"); err != nil {
+		return query, err
+	}
+
+	if query.CreatedAfter, err = QueryTime(r, "created_after"); err != nil {
+		return query, err
+	}
+	if query.CreatedBefore, err = QueryTime(r, "created_before"); err != nil {
+		return query, err
+	}
+	if query.UpdatedAfter, err = QueryTime(r, "updated_after"); err != nil {
+		return query, err
+	}
+	if query.UpdatedBefore, err = QueryTime(r, "updated_before"); err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
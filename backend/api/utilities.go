@@ -3,6 +3,11 @@ package api
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ptracker/core"
 )
 
 func GetUserID(req *http.Request) (string, error) {
@@ -13,3 +18,86 @@ func GetUserID(req *http.Request) (string, error) {
 	}
 	return userId, nil
 }
+
+// Reads the `limit` query parameter, defaults to core.DEFAULT_LIST_LIMIT
+func QueryLimit(req *http.Request) (int, error) {
+	queryLimit := req.URL.Query().Get("limit")
+	if queryLimit == "" {
+		return core.DEFAULT_LIST_LIMIT, nil
+	}
+
+	limit, err := strconv.Atoi(queryLimit)
+	if err != nil || limit <= 0 || limit > core.MAX_LIST_LIMIT {
+		return 0, core.ErrInvalidValue
+	}
+
+	return limit, nil
+}
+
+// Reads the `cursor` and `limit` query parameters of a list request
+func QueryPage(req *http.Request) (core.PageQuery, error) {
+	limit, err := QueryLimit(req)
+	if err != nil {
+		return core.PageQuery{}, err
+	}
+
+	return core.PageQuery{
+		Cursor: req.URL.Query().Get("cursor"),
+		Limit:  limit,
+	}, nil
+}
+
+// Reads an optional RFC3339 timestamp from the query parameter key
+func QueryTime(req *http.Request, key string) (*time.Time, error) {
+	value := req.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, core.ErrInvalidValue
+	}
+
+	return &t, nil
+}
+
+// Reads an optional integer from the query parameter key
+func QueryInt(req *http.Request, key string) (*int, error) {
+	value := req.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, core.ErrInvalidValue
+	}
+
+	return &i, nil
+}
+
+// Sets the ETag header to the version of the resource
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+/*
+Reads the version from the If-Match header, as set by SetETag
+
+Returns nil when the header is missing or is `*`, so the update goes ahead
+whatever the version is.
+*/
+func IfMatchVersion(req *http.Request) (*int, error) {
+	value := strings.TrimSpace(req.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(value, "W/"), `"`))
+	if err != nil {
+		return nil, core.ErrInvalidValue
+	}
+
+	return &version, nil
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/workflows"
+)
+
+type WorkflowApi struct {
+	workflowService *workflows.WorkflowService
+}
+
+func NewWorkflowApi(workflowService *workflows.WorkflowService) *WorkflowApi {
+	return &WorkflowApi{
+		workflowService: workflowService,
+	}
+}
+
+func (api *WorkflowApi) Get(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	workflow, err := api.workflowService.Get(r.Context(), projectID, userID)
+	if err != nil {
+		return fmt.Errorf("workflow service get: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[workflows.Workflow]{
+		Status: RESPONSE_SUCCESS_STATUS,
+		Data:   workflow,
+	})
+
+	return nil
+}
+
+func (api *WorkflowApi) Replace(w http.ResponseWriter, r *http.Request) error {
+
+	projectID := r.PathValue("id")
+	if projectID == "" {
+		return core.ErrInvalidValue
+	}
+
+	var payload workflows.Workflow
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&payload); err != nil {
+		return fmt.Errorf("payload decode: %w", core.ErrInvalidValue)
+	}
+	if err := validator.New().Struct(payload); err != nil {
+		return fmt.Errorf("payload validation: %w", core.ErrInvalidValue)
+	}
+
+	userID, err := GetUserID(r)
+	if err != nil {
+		return fmt.Errorf("get userID: %w", err)
+	}
+
+	err = api.workflowService.Replace(r.Context(), projectID, userID, payload)
+	if err != nil {
+		return fmt.Errorf("workflow service replace: %w", err)
+	}
+
+	json.NewEncoder(w).Encode(HTTPSuccessResponse[any]{
+		Status:  RESPONSE_SUCCESS_STATUS,
+		Message: "Workflow updated successfully",
+	})
+
+	return nil
+}
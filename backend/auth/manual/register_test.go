@@ -40,7 +40,7 @@ func (suite *registerServiceTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
-	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{})
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
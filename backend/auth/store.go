@@ -13,6 +13,10 @@ func getTokenKey(id string) string {
 	return "token:" + id
 }
 
+func getTicketKey(id string) string {
+	return "ticket:" + id
+}
+
 type TokenStore struct {
 	redis *redis.Client
 }
@@ -68,3 +72,30 @@ func (s *TokenStore) Revoke(ctx context.Context,
 
 	return nil
 }
+
+// Saves ticket id of user userID until expiresAt
+func (s *TokenStore) SaveTicket(ctx context.Context,
+	id string, userID string, expiresAt time.Time) error {
+
+	err := s.redis.Set(ctx, getTicketKey(id), userID, time.Until(expiresAt)).Err()
+	if err != nil {
+		return fmt.Errorf("redis set: %w", err)
+	}
+
+	return nil
+}
+
+// Deletes ticket id and returns its user, a ticket can be used once
+func (s *TokenStore) ConsumeTicket(ctx context.Context,
+	id string) (string, error) {
+
+	userID, err := s.redis.GetDel(ctx, getTicketKey(id)).Result()
+	if err == redis.Nil {
+		return "", fmt.Errorf("ticket not found: %w", core.ErrUnauthorized)
+	}
+	if err != nil {
+		return "", fmt.Errorf("redis getdel: %w", err)
+	}
+
+	return userID, nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ptracker/core"
 	"github.com/ptracker/testhelpers"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/suite"
@@ -86,3 +87,24 @@ func (suite *tokenStoreTestSuite) TestRevoke() {
 		suite.Delete(getTokenKey("123"))
 	})
 }
+
+func (suite *tokenStoreTestSuite) TestConsumeTicket() {
+	t := suite.T()
+
+	t.Run("should return user of ticket", func(t *testing.T) {
+		suite.store.SaveTicket(suite.ctx, "123", "user", time.Now().Add(1*time.Minute))
+
+		userID, err := suite.store.ConsumeTicket(suite.ctx, "123")
+
+		suite.Require().NoError(err)
+		suite.Require().Equal("user", userID)
+	})
+	t.Run("should return ErrUnauthorized when ticket is used again", func(t *testing.T) {
+		suite.store.SaveTicket(suite.ctx, "123", "user", time.Now().Add(1*time.Minute))
+		suite.store.ConsumeTicket(suite.ctx, "123")
+
+		_, err := suite.store.ConsumeTicket(suite.ctx, "123")
+
+		suite.Require().ErrorIs(err, core.ErrUnauthorized)
+	})
+}
@@ -5,11 +5,14 @@ import (
 	"crypto/rsa"
 	"fmt"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 const (
 	ACCESS_TOKEN_DURATION_DEFAULT  = 15 * time.Minute
 	REFRESH_TOKEN_DURATION_DEFAULT = 7 * 24 * time.Hour
+	TICKET_DURATION_DEFAULT        = 30 * time.Second
 )
 
 type Token struct {
@@ -24,6 +27,7 @@ type TokenService struct {
 
 	AccessTokenDuration  time.Duration
 	RefreshTokenDuration time.Duration
+	TicketDuration       time.Duration
 }
 
 func NewTokenService(store *TokenStore,
@@ -36,6 +40,7 @@ func NewTokenService(store *TokenStore,
 
 		AccessTokenDuration:  ACCESS_TOKEN_DURATION_DEFAULT,
 		RefreshTokenDuration: REFRESH_TOKEN_DURATION_DEFAULT,
+		TicketDuration:       TICKET_DURATION_DEFAULT,
 	}
 }
 
@@ -84,6 +89,39 @@ func (s *TokenService) CreateRefreshToken(ctx context.Context,
 	}, nil
 }
 
+/*
+Creates a short-lived ticket of user userID, for the requests that can not
+carry the access token in a header, such as the handshake of a websocket
+*/
+func (s *TokenService) CreateTicket(ctx context.Context,
+	userID string) (*Token, error) {
+
+	ticketExpiry := time.Now().Add(s.TicketDuration)
+	ticket := uuid.NewString()
+
+	err := s.store.SaveTicket(ctx, ticket, userID, ticketExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("token store save ticket: %w", err)
+	}
+
+	return &Token{
+		Value:     ticket,
+		ExpiresAt: ticketExpiry,
+	}, nil
+}
+
+// Get user ID from ticket, which can not be used again
+func (s *TokenService) RedeemTicket(ctx context.Context,
+	ticket string) (string, error) {
+
+	userID, err := s.store.ConsumeTicket(ctx, ticket)
+	if err != nil {
+		return "", fmt.Errorf("token store consume ticket: %w", err)
+	}
+
+	return userID, nil
+}
+
 // Get user ID from refresh token
 func (s *TokenService) GetUserID(ctx context.Context,
 	token string) (string, error) {
@@ -0,0 +1,330 @@
+package boards
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/members"
+	"github.com/ptracker/core/tasks"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	ET_TASK_CREATED     = "task_created"
+	ET_TASK_UPDATED     = "task_updated"
+	ET_ASSIGNEE_ADDED   = "assignee_added"
+	ET_ASSIGNEE_REMOVED = "assignee_removed"
+	ET_PRESENCE         = "presence"
+)
+
+const (
+	BOARD_CHANNEL_PREFIX = "board:"
+	PRESENCE_KEY_PREFIX  = "board-presence:"
+
+	// a viewer not refreshed within PRESENCE_TTL is no longer present, so
+	// the viewers of a crashed instance expire on their own
+	PRESENCE_TTL = 60 * time.Second
+
+	// events buffered for a slow viewer before it is dropped
+	VIEWER_BUFFER = 32
+)
+
+func getBoardChannel(projectID string) string {
+	return BOARD_CHANNEL_PREFIX + projectID
+}
+
+func getPresenceKey(projectID string) string {
+	return PRESENCE_KEY_PREFIX + projectID
+}
+
+type Event struct {
+	Type      string `json:"type"`
+	ProjectID string `json:"project_id"`
+	Data      any    `json:"data"`
+}
+
+type TaskChanged struct {
+	Task    *tasks.ProjectTaskItem `json:"task"`
+	Changed []string               `json:"changed,omitempty"`
+	ActorID string                 `json:"actor_id"`
+}
+
+type AssigneeChanged struct {
+	TaskID     string `json:"task_id"`
+	AssigneeID string `json:"assignee_id"`
+	ActorID    string `json:"actor_id"`
+}
+
+type Presence struct {
+	UserIDs []string `json:"user_ids"`
+}
+
+// Connection of a member to the board of a project
+type Viewer struct {
+	ID        string
+	ProjectID string
+	UserID    string
+
+	// closed when the viewer leaves or does not keep up with the events
+	Events chan Event
+}
+
+/*
+Broadcasts the changes of the project boards to their viewers
+
+Events are published to the redis channel of the project, every server
+instance runs a single pattern subscription and fans them out to the
+viewers connected to it. The present viewers are kept in a redis sorted set
+per project, scored by the time they expire.
+*/
+type Hub struct {
+	redis      *redis.Client
+	memberRepo *members.MemberRepository
+
+	mu      sync.Mutex
+	viewers map[string]map[*Viewer]struct{}
+}
+
+func NewHub(redis *redis.Client,
+	memberRepo *members.MemberRepository) *Hub {
+	return &Hub{
+		redis:      redis,
+		memberRepo: memberRepo,
+		viewers:    map[string]map[*Viewer]struct{}{},
+	}
+}
+
+func (h *Hub) publish(ctx context.Context,
+	projectID, eventType string,
+	data any) error {
+
+	payload, err := json.Marshal(Event{
+		Type:      eventType,
+		ProjectID: projectID,
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("json marshal: %w", err)
+	}
+
+	err = h.redis.Publish(ctx, getBoardChannel(projectID), payload).Err()
+	if err != nil {
+		return fmt.Errorf("redis publish: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Hub) TaskCreated(ctx context.Context,
+	task *tasks.ProjectTaskItem,
+	actorID string) error {
+
+	return h.publish(ctx, task.ProjectID, ET_TASK_CREATED, TaskChanged{
+		Task:    task,
+		ActorID: actorID,
+	})
+}
+
+func (h *Hub) TaskUpdated(ctx context.Context,
+	updated *tasks.UpdatedTask,
+	actorID string) error {
+
+	return h.publish(ctx, updated.Task.ProjectID, ET_TASK_UPDATED, TaskChanged{
+		Task:    updated.Task,
+		Changed: updated.Changed,
+		ActorID: actorID,
+	})
+}
+
+func (h *Hub) AssigneeUpdated(ctx context.Context,
+	projectID, taskID, assigneeID string,
+	added bool,
+	actorID string) error {
+
+	eventType := ET_ASSIGNEE_REMOVED
+	if added {
+		eventType = ET_ASSIGNEE_ADDED
+	}
+
+	return h.publish(ctx, projectID, eventType, AssigneeChanged{
+		TaskID:     taskID,
+		AssigneeID: assigneeID,
+		ActorID:    actorID,
+	})
+}
+
+/*
+Connects user userID to the board of the project projectID
+
+Returns ErrForbidden if the user is not a member of the project. The
+viewer has to Refresh its presence within PRESENCE_TTL and Leave when done.
+*/
+func (h *Hub) Join(ctx context.Context,
+	projectID, userID string) (*Viewer, error) {
+
+	err := core.NeedsToBeAMember(ctx, h.memberRepo, projectID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("needs to be a member: %w", err)
+	}
+
+	v := &Viewer{
+		ID:        uuid.NewString(),
+		ProjectID: projectID,
+		UserID:    userID,
+		Events:    make(chan Event, VIEWER_BUFFER),
+	}
+
+	h.mu.Lock()
+	if h.viewers[projectID] == nil {
+		h.viewers[projectID] = map[*Viewer]struct{}{}
+	}
+	h.viewers[projectID][v] = struct{}{}
+	h.mu.Unlock()
+
+	err = h.Refresh(ctx, v)
+	if err != nil {
+		h.Leave(ctx, v)
+		return nil, fmt.Errorf("refresh: %w", err)
+	}
+
+	err = h.publishPresence(ctx, projectID)
+	if err != nil {
+		log.Printf("[ERROR] hub publish presence: %s", err)
+	}
+
+	return v, nil
+}
+
+// Keeps the viewer present for another PRESENCE_TTL
+func (h *Hub) Refresh(ctx context.Context, v *Viewer) error {
+	err := h.redis.ZAdd(ctx, getPresenceKey(v.ProjectID), redis.Z{
+		Score:  float64(time.Now().Add(PRESENCE_TTL).Unix()),
+		Member: v.UserID + ":" + v.ID,
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("redis zadd: %w", err)
+	}
+
+	err = h.redis.Expire(ctx, getPresenceKey(v.ProjectID), PRESENCE_TTL).Err()
+	if err != nil {
+		return fmt.Errorf("redis expire: %w", err)
+	}
+
+	return nil
+}
+
+func (h *Hub) Leave(ctx context.Context, v *Viewer) {
+	h.mu.Lock()
+	h.remove(v)
+	h.mu.Unlock()
+
+	err := h.redis.ZRem(ctx, getPresenceKey(v.ProjectID), v.UserID+":"+v.ID).Err()
+	if err != nil {
+		log.Printf("[ERROR] hub redis zrem: %s", err)
+	}
+
+	err = h.publishPresence(ctx, v.ProjectID)
+	if err != nil {
+		log.Printf("[ERROR] hub publish presence: %s", err)
+	}
+}
+
+// Removes and closes the viewer, mu must be held
+func (h *Hub) remove(v *Viewer) {
+	if _, ok := h.viewers[v.ProjectID][v]; !ok {
+		return
+	}
+
+	delete(h.viewers[v.ProjectID], v)
+	if len(h.viewers[v.ProjectID]) == 0 {
+		delete(h.viewers, v.ProjectID)
+	}
+	close(v.Events)
+}
+
+// Lists the users viewing the board of the project on any instance
+func (h *Hub) Present(ctx context.Context,
+	projectID string) ([]string, error) {
+
+	key := getPresenceKey(projectID)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	err := h.redis.ZRemRangeByScore(ctx, key, "-inf", "("+now).Err()
+	if err != nil {
+		return nil, fmt.Errorf("redis zremrangebyscore: %w", err)
+	}
+
+	entries, err := h.redis.ZRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis zrange: %w", err)
+	}
+
+	// a user viewing the board from several tabs is present once
+	userIDs := []string{}
+	for _, e := range entries {
+		userID, _, _ := strings.Cut(e, ":")
+		if !slices.Contains(userIDs, userID) {
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	return userIDs, nil
+}
+
+func (h *Hub) publishPresence(ctx context.Context, projectID string) error {
+	userIDs, err := h.Present(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("present: %w", err)
+	}
+
+	return h.publish(ctx, projectID, ET_PRESENCE, Presence{UserIDs: userIDs})
+}
+
+func (h *Hub) deliver(projectID string, e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for v := range h.viewers[projectID] {
+		select {
+		case v.Events <- e:
+		default:
+			h.remove(v)
+		}
+	}
+}
+
+// Receives the events of all boards until ctx is done
+func (h *Hub) Run(ctx context.Context) error {
+	pubsub := h.redis.PSubscribe(ctx, BOARD_CHANNEL_PREFIX+"*")
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return fmt.Errorf("redis pubsub channel closed")
+			}
+
+			var e Event
+			err := json.Unmarshal([]byte(msg.Payload), &e)
+			if err != nil {
+				log.Printf("[ERROR] hub json unmarshal: %s", err)
+				continue
+			}
+
+			h.deliver(strings.TrimPrefix(msg.Channel, BOARD_CHANNEL_PREFIX), e)
+		}
+	}
+}
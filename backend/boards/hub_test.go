@@ -0,0 +1,210 @@
+package boards
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/members"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/ptracker/testhelpers/fixtures"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var USER_ONE, USER_TWO, USER_THREE string
+
+type hubTestSuite struct {
+	suite.Suite
+	ctx         context.Context
+	cancel      context.CancelFunc
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	redis       *redis.Client
+	fixtures    *fixtures.Fixtures
+	hub         *Hub
+}
+
+func TestHub(t *testing.T) {
+	suite.Run(t, new(hubTestSuite))
+}
+
+func (suite *hubTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx, suite.cancel = context.WithCancel(context.Background())
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	redisContainer, err := testhelpers.CreateRedisContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	connString, err := redisContainer.ConnectionString(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opt, err := redis.ParseURL(connString)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.redis = redis.NewClient(opt)
+
+	suite.hub = NewHub(suite.redis, members.NewMemberRepository(suite.db))
+	go suite.hub.Run(suite.ctx)
+
+	// the pattern subscription is ready once redis counts it
+	for range 50 {
+		n, _ := suite.redis.PubSubNumPat(suite.ctx).Result()
+		if n > 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	suite.fixtures = fixtures.New(suite.ctx, suite.db)
+
+	USER_ONE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_TWO = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_THREE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+}
+
+func (suite *hubTestSuite) TearDownSuite() {
+	suite.cancel()
+}
+
+func (suite *hubTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM projects").Error
+	suite.Require().NoError(err)
+
+	err = suite.redis.FlushAll(suite.ctx).Err()
+	suite.Require().NoError(err)
+}
+
+// Waits for the next event of the type, skipping the others
+func (suite *hubTestSuite) next(v *Viewer, eventType string) (Event, bool) {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e, ok := <-v.Events:
+			if !ok {
+				return Event{}, false
+			}
+			if e.Type == eventType {
+				return e, true
+			}
+		case <-timeout:
+			return Event{}, false
+		}
+	}
+}
+
+func (suite *hubTestSuite) TestJoin() {
+	t := suite.T()
+
+	t.Run("should return forbidden when user is not a member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, err := suite.hub.Join(suite.ctx, p, USER_THREE)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+
+	t.Run("should list present users once", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_VIEWER))
+
+		v1, err1 := suite.hub.Join(suite.ctx, p, USER_ONE)
+		v2, err2 := suite.hub.Join(suite.ctx, p, USER_ONE)
+		v3, err3 := suite.hub.Join(suite.ctx, p, USER_TWO)
+		present, err := suite.hub.Present(suite.ctx, p)
+
+		suite.hub.Leave(suite.ctx, v1)
+		suite.hub.Leave(suite.ctx, v2)
+		suite.hub.Leave(suite.ctx, v3)
+		suite.Cleanup()
+
+		suite.Require().NoError(err1)
+		suite.Require().NoError(err2)
+		suite.Require().NoError(err3)
+		suite.Require().NoError(err)
+		suite.Require().ElementsMatch([]string{USER_ONE, USER_TWO}, present)
+	})
+
+	t.Run("should broadcast presence when a viewer leaves", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		v1, _ := suite.hub.Join(suite.ctx, p, USER_ONE)
+		v2, _ := suite.hub.Join(suite.ctx, p, USER_TWO)
+		suite.hub.Leave(suite.ctx, v2)
+
+		// the last presence event lists the viewers left
+		var last Event
+		for {
+			e, ok := suite.next(v1, ET_PRESENCE)
+			if !ok {
+				break
+			}
+			last = e
+			if len(e.Data.(map[string]any)["user_ids"].([]any)) == 1 {
+				break
+			}
+		}
+
+		suite.hub.Leave(suite.ctx, v1)
+		suite.Cleanup()
+
+		suite.Require().Equal([]any{USER_ONE}, last.Data.(map[string]any)["user_ids"])
+	})
+}
+
+func (suite *hubTestSuite) TestAssigneeUpdated() {
+	t := suite.T()
+
+	t.Run("should deliver event to the viewers of the project only", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		other := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_TWO))
+
+		v1, _ := suite.hub.Join(suite.ctx, p, USER_ONE)
+		v2, _ := suite.hub.Join(suite.ctx, other, USER_TWO)
+		err := suite.hub.AssigneeUpdated(suite.ctx, p, "task", USER_TWO, true, USER_ONE)
+
+		e, received := suite.next(v1, ET_ASSIGNEE_ADDED)
+		_, leaked := suite.next(v2, ET_ASSIGNEE_ADDED)
+
+		suite.hub.Leave(suite.ctx, v1)
+		suite.hub.Leave(suite.ctx, v2)
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().True(received)
+		suite.Require().False(leaked)
+		suite.Require().Equal(p, e.ProjectID)
+		suite.Require().Equal("task", e.Data.(map[string]any)["task_id"])
+	})
+}
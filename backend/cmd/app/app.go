@@ -1,25 +1,35 @@
 package app
 
 import (
+	"context"
 	"crypto/rsa"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/ptracker/activity"
 	"github.com/ptracker/api"
 	"github.com/ptracker/auth"
 	"github.com/ptracker/auth/manual"
 	"github.com/ptracker/auth/openid"
+	"github.com/ptracker/boards"
 	"github.com/ptracker/core"
 	"github.com/ptracker/core/assignees"
+	"github.com/ptracker/core/bans"
 	"github.com/ptracker/core/comments"
+	"github.com/ptracker/core/events"
+	"github.com/ptracker/core/labels"
 	"github.com/ptracker/core/members"
+	"github.com/ptracker/core/mentions"
 	"github.com/ptracker/core/projects"
 	"github.com/ptracker/core/requests"
 	"github.com/ptracker/core/tasks"
 	"github.com/ptracker/core/users"
+	"github.com/ptracker/core/workflows"
 	"github.com/ptracker/middlewares"
 	"github.com/ptracker/notifications"
+	"github.com/ptracker/outbox"
 	"github.com/redis/go-redis/v9"
 	"github.com/resend/resend-go/v3"
 	"github.com/rs/cors"
@@ -35,8 +45,12 @@ type App struct {
 	// CORS allowed origins
 	AllowedCrossOrigins []string
 
-	config  *Config
-	handler *http.ServeMux
+	config     *Config
+	handler    *http.ServeMux
+	broker     *notifications.Broker
+	hub        *boards.Hub
+	dispatcher *outbox.Dispatcher
+	mailer     *notifications.Mailer
 }
 
 func NewApp(
@@ -56,46 +70,101 @@ func NewApp(
 
 	memberRepo := members.NewMemberRepository(db)
 	joinRepo := requests.NewJoinRepository(db)
+	banRepo := bans.NewBanRepository(db)
 	accountRepo := manual.NewManualAccountRepository(db)
 	oauthRepo := openid.NewOauthRepository(db)
 	userRepo := users.NewUserRepository(db)
 	assigneeRepo := assignees.NewAssigneeRepository(db)
 	commentRepo := comments.NewCommentRepository(db)
+	labelRepo := labels.NewLabelRepository(db)
+	workflowRepo := workflows.NewWorkflowRepository(db)
+	eventRepo := events.NewEventRepository(db)
 	projectRepo := projects.NewProjectRepository(db)
 	taskRepo := tasks.NewTaskRepository(db)
-	notificationRepo := notifications.NewNotificationRepository(db)
+	broker := notifications.NewBroker(redis)
+	hub := boards.NewHub(redis, memberRepo)
+	notificationRepo := notifications.NewNotificationRepository(db, broker)
+	activityRepo := activity.NewActivityRepository(db)
+	mentionRepo := mentions.NewMentionRepository(db)
+	preferenceRepo := notifications.NewPreferenceRepository(db)
+	outboxRepo := outbox.NewOutboxRepository(db)
 	txManager := core.NewTxManager(db)
 	tokenStore := auth.NewTokenStore(redis)
 	stringStore := openid.NewStringStore(redis)
 
-	memberService := members.NewMemberService(memberRepo)
+	memberService := members.NewMemberService(
+		txManager,
+		memberRepo,
+		banRepo,
+		outboxRepo,
+	)
 	joinService := requests.NewJoinRequestService(
 		txManager,
 		joinRepo,
 		memberRepo,
+		banRepo,
+		activityRepo,
+		outboxRepo,
 	)
 	userService := users.NewUserService(userRepo)
 	assigneeService := assignees.NewAssigneeService(
+		txManager,
 		memberRepo,
-		assigneeRepo)
+		assigneeRepo,
+		eventRepo,
+		outboxRepo,
+		taskRepo)
 	commentService := comments.NewCommentService(
+		txManager,
 		commentRepo,
-		memberRepo)
+		memberRepo,
+		eventRepo,
+		activityRepo,
+		outboxRepo,
+		taskRepo)
+	eventService := events.NewEventService(
+		eventRepo,
+		memberRepo,
+		taskRepo)
+	labelService := labels.NewLabelService(
+		memberRepo,
+		labelRepo,
+		taskRepo)
 	projectService := projects.NewProjectService(
 		txManager,
 		projectRepo,
-		memberRepo)
+		memberRepo,
+		outboxRepo)
 	taskService := tasks.NewTaskService(
+		txManager,
 		taskRepo,
 		memberRepo,
 		assigneeRepo,
+		labelRepo,
+		workflowRepo,
+		eventRepo,
+		activityRepo,
+		outboxRepo,
+	)
+	workflowService := workflows.NewWorkflowService(
+		txManager,
+		workflowRepo,
+		memberRepo,
 	)
 	notificationService := notifications.NewNotificationService(
+		txManager,
 		projectRepo,
 		taskRepo,
 		memberRepo,
 		userRepo,
 		notificationRepo,
+		mentionRepo,
+		preferenceRepo,
+		outboxRepo,
+	)
+	activityService := activity.NewActivityService(
+		activityRepo,
+		memberRepo,
 	)
 	registerService := manual.NewRegisterService(txManager, accountRepo, userRepo)
 	tokenService := auth.NewTokenService(tokenStore, TOKEN_ISSUER, privateKey)
@@ -114,6 +183,24 @@ func NewApp(
 	authenticator := middlewares.NewAuthenticator(tokenService)
 
 	resendClient := resend.NewClient(config.ResendApiKey)
+
+	var mailSender notifications.MailSender = notifications.NewResendSender(resendClient)
+	if config.MailSender == MAIL_SENDER_LOG {
+		mailSender = notifications.NewLogSender()
+	}
+	mailer := notifications.NewMailer(
+		txManager,
+		notificationRepo,
+		userRepo,
+		notifications.NewRenderer(frontendHomeUrl),
+		mailSender,
+	)
+
+	// notifications are sent from the outbox events written with the changes
+	dispatcher := outbox.NewDispatcher(txManager, outboxRepo)
+	notificationService.Register(dispatcher)
+	mailer.Register(dispatcher)
+
 	authApi := api.NewAuthApi(
 		registerService,
 		tokenService,
@@ -136,15 +223,25 @@ func NewApp(
 		userService,
 		memberService,
 		joinService,
-		notificationService,
 	)
 	taskApi := api.NewTaskApi(
 		taskService,
 		assigneeService,
 		commentService,
-		notificationService,
+		hub,
 	)
-	messageApi := api.NewMessageApi(notificationService)
+	labelApi := api.NewLabelApi(labelService)
+	workflowApi := api.NewWorkflowApi(workflowService)
+	eventApi := api.NewEventApi(eventService)
+	activityApi := api.NewActivityApi(activityService)
+	messageApi := api.NewMessageApi(notificationService, broker)
+
+	// the board websocket is opened from the frontend
+	originPatterns := []string{}
+	if frontendUrl, err := url.Parse(frontendHomeUrl); err == nil {
+		originPatterns = append(originPatterns, frontendUrl.Host)
+	}
+	boardApi := api.NewBoardApi(hub, tokenService, originPatterns)
 
 	patternWithHandlers := []patternWithHandler{
 		// Auth
@@ -225,6 +322,11 @@ func NewApp(
 			pattern: "/projects/{project_id}/tasks",
 			handler: authenticator.IsAuthenticated(taskApi.List),
 		},
+		{
+			method:  "GET",
+			pattern: "/projects/{project_id}/tasks/{task_id}/subtasks",
+			handler: authenticator.IsAuthenticated(taskApi.ListSubtasks),
+		},
 		{
 			method:  "GET",
 			pattern: "/dashboard/tasks/assigned",
@@ -235,11 +337,26 @@ func NewApp(
 			pattern: "/dashboard/tasks/unassigned",
 			handler: authenticator.IsAuthenticated(taskApi.ListUnassignedTasks),
 		},
+		{
+			method:  "GET",
+			pattern: "/dashboard/tasks/due",
+			handler: authenticator.IsAuthenticated(taskApi.ListDueTasks),
+		},
 		{
 			method:  "GET",
 			pattern: "/projects/{id}/members",
 			handler: authenticator.IsAuthenticated(projectApi.ListMembers),
 		},
+		{
+			method:  "GET",
+			pattern: "/projects/{id}/labels",
+			handler: authenticator.IsAuthenticated(labelApi.List),
+		},
+		{
+			method:  "GET",
+			pattern: "/projects/{id}/bans",
+			handler: authenticator.IsAuthenticated(projectApi.ListBans),
+		},
 		{
 			method:  "GET",
 			pattern: "/projects/{id}/join-requests",
@@ -250,6 +367,26 @@ func NewApp(
 			pattern: "/projects/{project_id}/tasks/{task_id}/comments",
 			handler: authenticator.IsAuthenticated(taskApi.ListComments),
 		},
+		{
+			method:  "GET",
+			pattern: "/projects/{project_id}/tasks/{task_id}/history",
+			handler: authenticator.IsAuthenticated(eventApi.History),
+		},
+		{
+			method:  "GET",
+			pattern: "/projects/{id}/activity",
+			handler: authenticator.IsAuthenticated(activityApi.List),
+		},
+		{
+			method:  "POST",
+			pattern: "/projects/{id}/board/tickets",
+			handler: authenticator.IsAuthenticated(boardApi.Ticket),
+		},
+		{
+			method:  "GET",
+			pattern: "/projects/{id}/board",
+			handler: boardApi.Connect,
+		},
 		{
 			method:  "GET",
 			pattern: "/public/projects",
@@ -260,12 +397,27 @@ func NewApp(
 			pattern: "/messages",
 			handler: authenticator.IsAuthenticated(messageApi.List),
 		},
+		{
+			method:  "GET",
+			pattern: "/messages/stream",
+			handler: authenticator.IsAuthenticated(messageApi.Stream),
+		},
 		// Get Single Instance APIs
 		{
 			method:  "GET",
 			pattern: "/projects/{id}",
 			handler: authenticator.IsAuthenticated(projectApi.Get),
 		},
+		{
+			method:  "GET",
+			pattern: "/projects/{id}/notification-preferences",
+			handler: authenticator.IsAuthenticated(messageApi.GetPreferences),
+		},
+		{
+			method:  "GET",
+			pattern: "/projects/{id}/workflow",
+			handler: authenticator.IsAuthenticated(workflowApi.Get),
+		},
 		{
 			method:  "GET",
 			pattern: "/projects/{project_id}/tasks/{task_id}",
@@ -277,6 +429,11 @@ func NewApp(
 			pattern: "/projects",
 			handler: authenticator.IsAuthenticated(projectApi.Create),
 		},
+		{
+			method:  "POST",
+			pattern: "/projects/{id}/labels",
+			handler: authenticator.IsAuthenticated(labelApi.Create),
+		},
 		{
 			method:  "POST",
 			pattern: "/projects/{project_id}/tasks",
@@ -292,7 +449,37 @@ func NewApp(
 			pattern: "/projects/{project_id}/tasks/{task_id}/comments",
 			handler: authenticator.IsAuthenticated(taskApi.AddComment),
 		},
+		{
+			method:  "POST",
+			pattern: "/projects/{id}/leave",
+			handler: authenticator.IsAuthenticated(projectApi.Leave),
+		},
 		// Update Instance APIs
+		{
+			method:  "PATCH",
+			pattern: "/projects/{id}",
+			handler: authenticator.IsAuthenticated(projectApi.Update),
+		},
+		{
+			method:  "PATCH",
+			pattern: "/projects/{id}/labels/{label_id}",
+			handler: authenticator.IsAuthenticated(labelApi.Update),
+		},
+		{
+			method:  "PUT",
+			pattern: "/projects/{id}/workflow",
+			handler: authenticator.IsAuthenticated(workflowApi.Replace),
+		},
+		{
+			method:  "PATCH",
+			pattern: "/projects/{id}/owner",
+			handler: authenticator.IsAuthenticated(projectApi.TransferOwnership),
+		},
+		{
+			method:  "PATCH",
+			pattern: "/projects/{id}/members/{user_id}",
+			handler: authenticator.IsAuthenticated(projectApi.ChangeRole),
+		},
 		{
 			method:  "PATCH",
 			pattern: "/projects/{id}/join-requests",
@@ -303,11 +490,62 @@ func NewApp(
 			pattern: "/projects/{project_id}/tasks/{task_id}",
 			handler: authenticator.IsAuthenticated(taskApi.Update),
 		},
+		{
+			method:  "PATCH",
+			pattern: "/projects/{project_id}/tasks/{task_id}/comments/{comment_id}",
+			handler: authenticator.IsAuthenticated(taskApi.UpdateComment),
+		},
+		{
+			method:  "PATCH",
+			pattern: "/projects/{id}/notification-preferences",
+			handler: authenticator.IsAuthenticated(messageApi.UpdatePreferences),
+		},
+		{
+			method:  "PUT",
+			pattern: "/projects/{id}/mute",
+			handler: authenticator.IsAuthenticated(messageApi.Mute),
+		},
 		{
 			method:  "PATCH",
 			pattern: "/messages/{id}",
 			handler: authenticator.IsAuthenticated(messageApi.MarkAsRead),
 		},
+		// Delete Instance APIs
+		{
+			method:  "DELETE",
+			pattern: "/projects/{id}/labels/{label_id}",
+			handler: authenticator.IsAuthenticated(labelApi.Delete),
+		},
+		{
+			method:  "DELETE",
+			pattern: "/projects/{id}",
+			handler: authenticator.IsAuthenticated(projectApi.Delete),
+		},
+		{
+			method:  "DELETE",
+			pattern: "/projects/{project_id}/tasks/{task_id}",
+			handler: authenticator.IsAuthenticated(taskApi.Delete),
+		},
+		{
+			method:  "DELETE",
+			pattern: "/projects/{project_id}/tasks/{task_id}/comments/{comment_id}",
+			handler: authenticator.IsAuthenticated(taskApi.DeleteComment),
+		},
+		{
+			method:  "DELETE",
+			pattern: "/projects/{id}/members/{user_id}",
+			handler: authenticator.IsAuthenticated(projectApi.RemoveMember),
+		},
+		{
+			method:  "DELETE",
+			pattern: "/projects/{id}/mute",
+			handler: authenticator.IsAuthenticated(messageApi.Unmute),
+		},
+		{
+			method:  "DELETE",
+			pattern: "/projects/{id}/bans/{user_id}",
+			handler: authenticator.IsAuthenticated(projectApi.Unban),
+		},
 	}
 
 	for _, h := range patternWithHandlers {
@@ -318,8 +556,12 @@ func NewApp(
 	}
 
 	return &App{
-		config:  config,
-		handler: handler,
+		config:     config,
+		handler:    handler,
+		broker:     broker,
+		hub:        hub,
+		dispatcher: dispatcher,
+		mailer:     mailer,
 	}
 }
 
@@ -329,7 +571,8 @@ func (app *App) Start() error {
 		AllowedOrigins:   app.AllowedCrossOrigins,
 		AllowedMethods:   []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
 		AllowCredentials: true,
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "If-Match", "Last-Event-ID"},
+		ExposedHeaders:   []string{"ETag"},
 	})
 
 	// server
@@ -340,6 +583,28 @@ func (app *App) Start() error {
 		WriteTimeout: 10 * time.Second,
 	}
 
+	// live notifications of the users connected to this instance
+	go func() {
+		err := app.broker.Run(context.Background())
+		if err != nil {
+			fmt.Printf("[ERROR] broker run: %s\n", err)
+		}
+	}()
+
+	// live boards of the projects viewed on this instance
+	go func() {
+		err := app.hub.Run(context.Background())
+		if err != nil {
+			fmt.Printf("[ERROR] hub run: %s\n", err)
+		}
+	}()
+
+	// outbox events, shared with the other instances
+	go app.dispatcher.Run(context.Background())
+
+	// daily digests, an instance skips the ones another is sending
+	go app.mailer.RunDigests(context.Background())
+
 	fmt.Printf("[INFO] server starting at %s:%s\n", app.config.Host, app.config.Port)
 
 	err := server.ListenAndServe()
@@ -10,6 +10,7 @@ type Config struct {
 	DBHost, DBPort, DBPass, DBUser, DBName                string
 	ResendApiKey                                          string
 	GoogleClientID, GoogleClientSecret, GoogleRedirectURI string
+	MailSender                                            string
 }
 
 func (c *Config) LoadFromEnv() error {
@@ -59,5 +60,12 @@ func (c *Config) LoadFromEnv() error {
 		return fmt.Errorf("environment variable '%s' missing", ENV_GOOGLE_REDIRECT_URI)
 	}
 
+	c.MailSender = os.Getenv(ENV_MAIL_SENDER)
+	if c.MailSender == "" {
+		c.MailSender = MAIL_SENDER_RESEND
+	} else if c.MailSender != MAIL_SENDER_RESEND && c.MailSender != MAIL_SENDER_LOG {
+		return fmt.Errorf("environment variable '%s' invalid: %s", ENV_MAIL_SENDER, c.MailSender)
+	}
+
 	return nil
 }
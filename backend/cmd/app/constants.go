@@ -13,6 +13,13 @@ const (
 	ENV_GOOGLE_CLIENT_ID     = "GOOGLE_CLIENT_ID"
 	ENV_GOOGLE_CLIENT_SECRET = "GOOGLE_CLIENT_SECRET"
 	ENV_GOOGLE_REDIRECT_URI  = "GOOGLE_REDIRECT_URI"
+	ENV_MAIL_SENDER          = "MAIL_SENDER" // optional
+)
+
+// Mail senders, resend unless MAIL_SENDER says otherwise
+const (
+	MAIL_SENDER_RESEND = "resend"
+	MAIL_SENDER_LOG    = "log" // writes the notification mails to the log
 )
 
 const (
@@ -52,7 +52,7 @@ func main() {
 	db, err := gorm.Open(postgres.Open(fmt.Sprintf("host=%s port=%s user=%s "+
 		"password=%s dbname=%s sslmode=disable", config.DBHost, config.DBPort,
 		config.DBUser, config.DBPass, config.DBName)),
-		&gorm.Config{})
+		&gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatalf("[ERROR] gorm open: %s", err)
 	}
@@ -19,6 +19,10 @@ func NewAssigneeRepository(db *gorm.DB) *AssigneeRepository {
 	}
 }
 
+func (r *AssigneeRepository) WithTx(tx *gorm.DB) *AssigneeRepository {
+	return NewAssigneeRepository(tx)
+}
+
 func (r *AssigneeRepository) Create(ctx context.Context,
 	projectID, taskID, userID string) error {
 
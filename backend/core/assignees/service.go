@@ -7,7 +7,10 @@ import (
 	"time"
 
 	"github.com/ptracker/core"
+	"github.com/ptracker/core/events"
 	"github.com/ptracker/core/members"
+	"github.com/ptracker/outbox"
+	"gorm.io/gorm"
 )
 
 type Assignee struct {
@@ -19,15 +22,27 @@ type Assignee struct {
 }
 
 type AssigneeService struct {
+	txManager    *core.TxManager
 	memberRepo   *members.MemberRepository
 	assigneeRepo *AssigneeRepository
+	eventRepo    *events.EventRepository
+	outboxRepo   *outbox.OutboxRepository
+	taskChecker  core.TaskChecker
 }
 
-func NewAssigneeService(memberRepo *members.MemberRepository,
-	assigneeRepo *AssigneeRepository) *AssigneeService {
+func NewAssigneeService(txManager *core.TxManager,
+	memberRepo *members.MemberRepository,
+	assigneeRepo *AssigneeRepository,
+	eventRepo *events.EventRepository,
+	outboxRepo *outbox.OutboxRepository,
+	taskChecker core.TaskChecker) *AssigneeService {
 	return &AssigneeService{
+		txManager:    txManager,
 		memberRepo:   memberRepo,
 		assigneeRepo: assigneeRepo,
+		eventRepo:    eventRepo,
+		outboxRepo:   outboxRepo,
+		taskChecker:  taskChecker,
 	}
 }
 
@@ -36,18 +51,49 @@ func (s *AssigneeService) AddAssignee(ctx context.Context,
 
 	var err error
 
-	err = core.NeedsToBeAnOwner(ctx, s.memberRepo, projectID, userID)
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_ASSIGNEES)
 	if err != nil {
-		return fmt.Errorf("needs to be a owner: %w", err)
+		return fmt.Errorf("authorize manage assignees: %w", err)
+	}
+
+	err = core.NeedsToBeAProjectTask(ctx, s.taskChecker, projectID, taskID)
+	if err != nil {
+		return fmt.Errorf("needs to be a project task: %w", err)
 	}
 
 	if err = s.assigneeRepo.Is(ctx, projectID, taskID, assigneeID); err == nil {
 		return core.ErrDuplicate
 	}
 
-	err = s.assigneeRepo.Create(ctx, projectID, taskID, assigneeID)
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		err := s.assigneeRepo.WithTx(tx).Create(ctx, projectID, taskID, assigneeID)
+		if err != nil {
+			return fmt.Errorf("assignee repository create: %w", err)
+		}
+
+		field := "assignee"
+		err = s.eventRepo.WithTx(tx).Create(ctx, projectID, taskID, userID,
+			core.TASK_EVENT_ASSIGNEE_ADDED, &field, nil, &assigneeID)
+		if err != nil {
+			return fmt.Errorf("event repository create: %w", err)
+		}
+
+		err = s.outboxRepo.WithTx(tx).Create(ctx, outbox.EV_ASSIGNEE_UPDATED,
+			outbox.AssigneeUpdated{
+				ProjectID:  projectID,
+				TaskID:     taskID,
+				AssigneeID: assigneeID,
+				Added:      true,
+			})
+		if err != nil {
+			return fmt.Errorf("outbox repository create: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("assignee repository create: %w", err)
+		return fmt.Errorf("txManager WithTx: %w", err)
 	}
 
 	return nil
@@ -58,18 +104,44 @@ func (s *AssigneeService) RemoveAssignee(ctx context.Context,
 
 	var err error
 
-	err = core.NeedsToBeAnOwner(ctx, s.memberRepo, projectID, userID)
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_ASSIGNEES)
 	if err != nil {
-		return fmt.Errorf("needs to be a owner: %w", err)
+		return fmt.Errorf("authorize manage assignees: %w", err)
 	}
 
 	if err = s.assigneeRepo.Is(ctx, projectID, taskID, assigneeID); errors.Is(err, core.ErrNotFound) {
 		return core.ErrNotFound
 	}
 
-	err = s.assigneeRepo.Delete(ctx, projectID, taskID, assigneeID)
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		err := s.assigneeRepo.WithTx(tx).Delete(ctx, projectID, taskID, assigneeID)
+		if err != nil {
+			return fmt.Errorf("assignee repository delete: %w", err)
+		}
+
+		field := "assignee"
+		err = s.eventRepo.WithTx(tx).Create(ctx, projectID, taskID, userID,
+			core.TASK_EVENT_ASSIGNEE_REMOVED, &field, &assigneeID, nil)
+		if err != nil {
+			return fmt.Errorf("event repository create: %w", err)
+		}
+
+		err = s.outboxRepo.WithTx(tx).Create(ctx, outbox.EV_ASSIGNEE_UPDATED,
+			outbox.AssigneeUpdated{
+				ProjectID:  projectID,
+				TaskID:     taskID,
+				AssigneeID: assigneeID,
+				Added:      false,
+			})
+		if err != nil {
+			return fmt.Errorf("outbox repository create: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("assignee repository create: %w", err)
+		return fmt.Errorf("txManager WithTx: %w", err)
 	}
 
 	return nil
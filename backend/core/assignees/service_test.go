@@ -6,7 +6,10 @@ import (
 	"testing"
 
 	"github.com/ptracker/core"
+	"github.com/ptracker/core/events"
 	"github.com/ptracker/core/members"
+	"github.com/ptracker/models"
+	"github.com/ptracker/outbox"
 	"github.com/ptracker/testdata"
 	"github.com/ptracker/testhelpers"
 	"github.com/ptracker/testhelpers/fixtures"
@@ -15,6 +18,24 @@ import (
 	"gorm.io/gorm"
 )
 
+// tasks package imports assignees, so the tasks are checked directly
+type taskCheckerStub struct {
+	db *gorm.DB
+}
+
+func (c taskCheckerStub) Exists(ctx context.Context,
+	projectID, taskID string) error {
+
+	_, err := gorm.G[models.Task](c.db).
+		Where("project_id = ? AND id = ?", projectID, taskID).
+		First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return core.ErrNotFound
+	}
+
+	return err
+}
+
 type assigneeServiceTestSuite struct {
 	suite.Suite
 	ctx         context.Context
@@ -38,7 +59,7 @@ func (suite *assigneeServiceTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
-	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{})
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -50,7 +71,10 @@ func (suite *assigneeServiceTestSuite) SetupSuite() {
 
 	memberRepo := members.NewMemberRepository(suite.db)
 	assigneeRepo := NewAssigneeRepository(suite.db)
-	service := NewAssigneeService(memberRepo, assigneeRepo)
+	eventRepo := events.NewEventRepository(suite.db)
+	txManager := core.NewTxManager(suite.db)
+	outboxRepo := outbox.NewOutboxRepository(suite.db)
+	service := NewAssigneeService(txManager, memberRepo, assigneeRepo, eventRepo, outboxRepo, taskCheckerStub{db: suite.db})
 	suite.service = service
 
 	suite.fixtures = fixtures.New(suite.ctx, suite.db)
@@ -79,6 +103,21 @@ func (suite *assigneeServiceTestSuite) TestAddAssignee() {
 		suite.Require().NoError(err)
 	})
 
+	t.Run("should record assignee added event", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		err := suite.service.AddAssignee(suite.ctx, p, task, USER_ONE, USER_TWO)
+		event, _ := gorm.G[models.TaskEvent](suite.db).Where("task_id = ?", task).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(core.TASK_EVENT_ASSIGNEE_ADDED, event.Type)
+		suite.Require().Equal(USER_ONE, event.ActorID)
+		suite.Require().Equal(USER_TWO, *event.NewValue)
+	})
+
 	t.Run("should return duplicate when assignee already exists", func(t *testing.T) {
 		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
@@ -102,6 +141,18 @@ func (suite *assigneeServiceTestSuite) TestAddAssignee() {
 
 		suite.Require().ErrorIs(err, core.ErrForbidden)
 	})
+
+	t.Run("should add assignee when maintainer requests", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MAINTAINER))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		err := suite.service.AddAssignee(suite.ctx, p, task, USER_TWO, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+	})
 }
 
 func (suite *assigneeServiceTestSuite) TestRemoveAssignee() {
@@ -142,3 +193,31 @@ func (suite *assigneeServiceTestSuite) TestRemoveAssignee() {
 		suite.Require().ErrorIs(err, core.ErrForbidden)
 	})
 }
+
+func (suite *assigneeServiceTestSuite) TestCrossProjectAccess() {
+	t := suite.T()
+
+	t.Run("should not add assignee to task of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_TWO))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_ONGOING))
+
+		err := suite.service.AddAssignee(suite.ctx, p2, task, USER_TWO, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+	t.Run("should not remove assignee of task of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_TWO))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p1, task, USER_ONE))
+
+		err := suite.service.RemoveAssignee(suite.ctx, p2, task, USER_TWO, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
@@ -0,0 +1,108 @@
+package bans
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"gorm.io/gorm"
+)
+
+type BanRow struct {
+	ProjectID string    `gorm:"column:project_id"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+
+	UserID      string  `gorm:"column:banned_user_id"`
+	Username    string  `gorm:"column:banned_username"`
+	DisplayName *string `gorm:"column:banned_display_name"`
+	Email       string  `gorm:"column:banned_email"`
+	AvatarURL   *string `gorm:"column:banned_avatar_url"`
+}
+
+type BanRepository struct {
+	db *gorm.DB
+}
+
+func NewBanRepository(db *gorm.DB) *BanRepository {
+	return &BanRepository{
+		db: db,
+	}
+}
+
+func (r *BanRepository) WithTx(tx *gorm.DB) *BanRepository {
+	return NewBanRepository(tx)
+}
+
+func (r *BanRepository) Create(ctx context.Context,
+	projectID, userID string) error {
+
+	ban := models.Ban{
+		ProjectID: projectID,
+		UserID:    userID,
+	}
+	err := gorm.G[models.Ban](r.db).Create(ctx, &ban)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return core.ErrDuplicate
+	} else if err != nil {
+		return fmt.Errorf("gorm create: %w", err)
+	}
+
+	return nil
+}
+
+func (r *BanRepository) Is(ctx context.Context,
+	projectID, userID string) error {
+
+	_, err := gorm.G[models.Ban](r.db).Where(
+		"project_id = ? AND user_id = ?",
+		projectID, userID).First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return core.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("gorm query: %w", err)
+	}
+
+	return nil
+}
+
+func (r *BanRepository) List(ctx context.Context,
+	projectID string) ([]BanRow, error) {
+
+	var rows = []BanRow{}
+	err := r.db.WithContext(ctx).
+		Table("bans b").
+		Select(`b.project_id, b.created_at, 
+				u.id as banned_user_id, 
+				u.username as banned_username, 
+				u.display_name as banned_display_name, 
+				u.email as banned_email, 
+				u.avatar_url as banned_avatar_url`).
+		Joins("INNER JOIN users AS u ON b.user_id=u.id").
+		Where("b.project_id = ?", projectID).
+		Order("b.created_at DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("gorm db scan: %w", err)
+	}
+
+	return rows, nil
+}
+
+func (r *BanRepository) Delete(ctx context.Context,
+	projectID, userID string) error {
+
+	rows, err := gorm.G[models.Ban](r.db).Where(
+		"project_id = ? AND user_id = ?",
+		projectID, userID).Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("gorm delete: %w", err)
+	}
+	if rows == 0 {
+		return core.ErrNotFound
+	}
+
+	return nil
+}
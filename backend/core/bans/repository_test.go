@@ -0,0 +1,147 @@
+package bans
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/ptracker/testhelpers/fixtures"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var USER_ONE, USER_TWO, USER_THREE string
+
+type banRepositoryTestSuite struct {
+	suite.Suite
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	fixtures    *fixtures.Fixtures
+	repo        *BanRepository
+	ctx         context.Context
+}
+
+func (suite *banRepositoryTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx = context.Background()
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.repo = NewBanRepository(suite.db)
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.fixtures = fixtures.New(suite.ctx, suite.db)
+
+	USER_ONE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_TWO = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_THREE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+}
+
+func (suite *banRepositoryTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM projects").Error
+	suite.Require().NoError(err)
+}
+
+func TestBanRepository(t *testing.T) {
+	suite.Run(t, new(banRepositoryTestSuite))
+}
+
+func (suite *banRepositoryTestSuite) TestCreate() {
+	t := suite.T()
+
+	t.Run("should ban user", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.repo.Create(suite.ctx, p, USER_TWO)
+
+		isErr := suite.repo.Is(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().NoError(isErr)
+	})
+	t.Run("should get duplicate error when banning user twice", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.repo.Create(suite.ctx, p, USER_TWO)
+
+		err := suite.repo.Create(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrDuplicate)
+	})
+	t.Run("should not find ban of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.repo.Create(suite.ctx, p1, USER_TWO)
+
+		err := suite.repo.Is(suite.ctx, p2, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
+
+func (suite *banRepositoryTestSuite) TestList() {
+	t := suite.T()
+
+	t.Run("should list banned users", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.repo.Create(suite.ctx, p, USER_TWO)
+		suite.repo.Create(suite.ctx, p, USER_THREE)
+
+		bans, err := suite.repo.List(suite.ctx, p)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, len(bans))
+	})
+}
+
+func (suite *banRepositoryTestSuite) TestDelete() {
+	t := suite.T()
+
+	t.Run("should lift ban", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.repo.Create(suite.ctx, p, USER_TWO)
+
+		err := suite.repo.Delete(suite.ctx, p, USER_TWO)
+
+		isErr := suite.repo.Is(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().ErrorIs(isErr, core.ErrNotFound)
+	})
+	t.Run("should return not found for user without ban", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.repo.Delete(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
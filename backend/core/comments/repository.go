@@ -6,17 +6,22 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/ptracker/core"
 	"github.com/ptracker/models"
 	"gorm.io/gorm"
 )
 
 type CommentRow struct {
-	ID        string    `gorm:"column:id"`
-	ProjectID string    `gorm:"column:project_id"`
-	TaskID    string    `gorm:"column:task_id"`
-	Content   string    `gorm:"column:content"`
-	CreatedAt time.Time `gorm:"column:created_at"`
-	UpdatedAt time.Time `gorm:"column:updated_at"`
+	ID        string     `gorm:"column:id"`
+	ProjectID string     `gorm:"column:project_id"`
+	TaskID    string     `gorm:"column:task_id"`
+	ParentID  *string    `gorm:"column:parent_id"`
+	Content   string     `gorm:"column:content"`
+	EditedAt  *time.Time `gorm:"column:edited_at"`
+	DeletedAt *time.Time `gorm:"column:deleted_at"`
+	CreatedAt time.Time  `gorm:"column:created_at"`
+	UpdatedAt time.Time  `gorm:"column:updated_at"`
+	Replies   int64      `gorm:"column:replies"`
 
 	UserID      string  `gorm:"column:user_id"`
 	Username    string  `gorm:"column:username"`
@@ -35,8 +40,13 @@ func NewCommentRepository(db *gorm.DB) *CommentRepository {
 	}
 }
 
+func (r *CommentRepository) WithTx(tx *gorm.DB) *CommentRepository {
+	return NewCommentRepository(tx)
+}
+
 func (r *CommentRepository) Create(ctx context.Context,
 	projectId, taskId, userId string,
+	parentId *string,
 	content string) (string, error) {
 
 	var err error
@@ -46,6 +56,7 @@ func (r *CommentRepository) Create(ctx context.Context,
 		ID:        id,
 		ProjectID: projectId,
 		TaskID:    taskId,
+		ParentID:  parentId,
 		UserID:    userId,
 		Content:   content,
 	}
@@ -58,25 +69,120 @@ func (r *CommentRepository) Create(ctx context.Context,
 	return comment.ID, nil
 }
 
+func (r *CommentRepository) Get(ctx context.Context,
+	projectId, taskId, id string) (models.Comment, error) {
+
+	comment, err := gorm.G[models.Comment](r.db).
+		Where("project_id = ? AND task_id = ? AND id = ?", projectId, taskId, id).
+		First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return comment, core.ErrNotFound
+	} else if err != nil {
+		return comment, fmt.Errorf("gorm query: %w", err)
+	}
+
+	return comment, nil
+}
+
+func (r *CommentRepository) Update(ctx context.Context,
+	projectId, taskId, id string,
+	content string) error {
+
+	editedAt := time.Now()
+	rows, err := gorm.G[models.Comment](r.db).
+		Where("project_id = ? AND task_id = ? AND id = ? AND deleted_at IS NULL",
+			projectId, taskId, id).
+		Updates(ctx, models.Comment{
+			Content:  content,
+			EditedAt: &editedAt,
+		})
+	if err != nil {
+		return fmt.Errorf("gorm updates: %w", err)
+	}
+	if rows == 0 {
+		return core.ErrNotFound
+	}
+
+	return nil
+}
+
+// Soft deletes the comment, its content is cleared and its replies are kept
+func (r *CommentRepository) Delete(ctx context.Context,
+	projectId, taskId, id string) error {
+
+	result := r.db.WithContext(ctx).
+		Model(&models.Comment{}).
+		Where("project_id = ? AND task_id = ? AND id = ? AND deleted_at IS NULL",
+			projectId, taskId, id).
+		Updates(map[string]any{
+			"content":    "",
+			"deleted_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("gorm db updates: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return core.ErrNotFound
+	}
+
+	return nil
+}
+
+/*
+Lists the top level comments of the task, or the replies of the comment
+parentId when it is given
+*/
 func (r *CommentRepository) List(ctx context.Context,
-	projectId, taskId string) ([]CommentRow, error) {
+	projectId, taskId string,
+	parentId *string,
+	page core.PageQuery) ([]CommentRow, string, error) {
 
-	var rows = []CommentRow{}
-	err := r.db.WithContext(ctx).
+	cursor, err := core.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := r.db.WithContext(ctx).
 		Table("comments c").
-		Select(`c.id, c.project_id, c.task_id, c.content, 
-				c.created_at, c.updated_at, 
+		Select(`c.id, c.project_id, c.task_id, c.parent_id, c.content, 
+				c.edited_at, c.deleted_at, c.created_at, c.updated_at, 
+				(SELECT COUNT(*) FROM comments AS rc WHERE rc.parent_id=c.id) AS replies, 
 				u.id as user_id, 
 				u.username as username, 
 				u.display_name as display_name, 
 				u.email as email, 
 				u.avatar_url as avatar_url`).
 		Joins("INNER JOIN users as u ON u.id=c.user_id").
-		Where("c.project_id = ? AND c.task_id = ?", projectId, taskId).
+		Where("c.project_id = ? AND c.task_id = ?", projectId, taskId)
+
+	if parentId != nil {
+		query = query.Where("c.parent_id = ?", *parentId)
+	} else {
+		query = query.Where("c.parent_id IS NULL")
+	}
+
+	// comments are read as a conversation, oldest first
+	if cursor != nil {
+		createdAt, err := cursor.Time()
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(c.created_at, c.id) > (?, ?)", createdAt, cursor.ID)
+	}
+
+	var rows = []CommentRow{}
+	err = query.
+		Order("c.created_at ASC, c.id ASC").
+		Limit(page.Limit + 1).
 		Scan(&rows).Error
 	if err != nil {
-		return nil, fmt.Errorf("db query context: %w", err)
+		return nil, "", fmt.Errorf("db query context: %w", err)
 	}
 
-	return rows, nil
+	rows, nextCursor := core.Paginate(rows, page.Limit,
+		func(row CommentRow) core.Cursor {
+			return core.NewTimeCursor(row.CreatedAt, row.ID)
+		})
+
+	return rows, nextCursor, nil
 }
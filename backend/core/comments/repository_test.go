@@ -5,6 +5,7 @@ import (
 	"log"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/ptracker/core"
 	"github.com/ptracker/testdata"
 	"github.com/ptracker/testhelpers"
@@ -35,7 +36,7 @@ func (suite *commentRepositoryTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
-	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{})
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -70,7 +71,7 @@ func (suite *commentRepositoryTestSuite) TestCreate() {
 		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
 
-		id, err := suite.repo.Create(suite.ctx, p, task, USER_ONE, "hello")
+		id, err := suite.repo.Create(suite.ctx, p, task, USER_ONE, nil, "hello")
 
 		suite.Cleanup()
 
@@ -86,7 +87,7 @@ func (suite *commentRepositoryTestSuite) TestList() {
 		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
 
-		rows, err := suite.repo.List(suite.ctx, p, task)
+		rows, _, err := suite.repo.List(suite.ctx, p, task, nil, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -101,7 +102,7 @@ func (suite *commentRepositoryTestSuite) TestList() {
 		suite.fixtures.InsertComment(fixtures.GetCommentRow(p, task, USER_ONE, "hello"))
 		suite.fixtures.InsertComment(fixtures.GetCommentRow(p, task, USER_TWO, "world"))
 
-		rows, err := suite.repo.List(suite.ctx, p, task)
+		rows, _, err := suite.repo.List(suite.ctx, p, task, nil, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -115,7 +116,7 @@ func (suite *commentRepositoryTestSuite) TestList() {
 		suite.fixtures.InsertComment(fixtures.GetCommentRow(p, task, USER_ONE, "hello"))
 		suite.fixtures.InsertComment(fixtures.GetCommentRow(p, task, USER_TWO, "world"))
 
-		rows, _ := suite.repo.List(suite.ctx, p, task)
+		rows, _, _ := suite.repo.List(suite.ctx, p, task, nil, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -124,4 +125,129 @@ func (suite *commentRepositoryTestSuite) TestList() {
 			[]string{rows[0].UserID, rows[1].UserID},
 		)
 	})
+
+	t.Run("should return oldest comment first with next cursor", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		first := fixtures.GetCommentRow(p, task, USER_ONE, "hello")
+		second := fixtures.GetCommentRow(p, task, USER_TWO, "world")
+		suite.fixtures.InsertComment(first)
+		suite.fixtures.InsertComment(second)
+
+		rows, cursor, _ := suite.repo.List(suite.ctx, p, task, nil, core.PageQuery{Limit: 1})
+		next, last, err := suite.repo.List(suite.ctx, p, task, nil, core.PageQuery{Limit: 1, Cursor: cursor})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(first.ID, rows[0].ID)
+		suite.Require().Equal(second.ID, next[0].ID)
+		suite.Require().Empty(last)
+	})
+}
+
+func (suite *commentRepositoryTestSuite) TestReplies() {
+	t := suite.T()
+
+	t.Run("should list only top level comments without parent", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		parent := fixtures.GetCommentRow(p, task, USER_ONE, "hello")
+		suite.fixtures.InsertComment(parent)
+		suite.fixtures.InsertComment(fixtures.GetReplyRow(parent, USER_TWO, "world"))
+
+		rows, _, err := suite.repo.List(suite.ctx, p, task, nil, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(rows))
+		suite.Require().Equal(parent.ID, rows[0].ID)
+		suite.Require().Equal(int64(1), rows[0].Replies)
+	})
+
+	t.Run("should list replies of the parent", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		parent := fixtures.GetCommentRow(p, task, USER_ONE, "hello")
+		reply := fixtures.GetReplyRow(parent, USER_TWO, "world")
+		suite.fixtures.InsertComment(parent)
+		suite.fixtures.InsertComment(reply)
+
+		rows, _, err := suite.repo.List(suite.ctx, p, task, &parent.ID, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(rows))
+		suite.Require().Equal(reply.ID, rows[0].ID)
+	})
+}
+
+func (suite *commentRepositoryTestSuite) TestUpdate() {
+	t := suite.T()
+
+	t.Run("should update content and set edited at", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		c := fixtures.GetCommentRow(p, task, USER_ONE, "hello")
+		suite.fixtures.InsertComment(c)
+
+		err := suite.repo.Update(suite.ctx, p, task, c.ID, "hi")
+		row, _ := suite.repo.Get(suite.ctx, p, task, c.ID)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal("hi", row.Content)
+		suite.Require().NotNil(row.EditedAt)
+	})
+
+	t.Run("should return not found for deleted comment", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		c := fixtures.GetCommentRow(p, task, USER_ONE, "hello")
+		suite.fixtures.InsertComment(c)
+
+		_ = suite.repo.Delete(suite.ctx, p, task, c.ID)
+		err := suite.repo.Update(suite.ctx, p, task, c.ID, "hi")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
+
+func (suite *commentRepositoryTestSuite) TestDelete() {
+	t := suite.T()
+
+	t.Run("should clear content and keep the comment in the list", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		parent := fixtures.GetCommentRow(p, task, USER_ONE, "hello")
+		suite.fixtures.InsertComment(parent)
+		suite.fixtures.InsertComment(fixtures.GetReplyRow(parent, USER_TWO, "world"))
+
+		err := suite.repo.Delete(suite.ctx, p, task, parent.ID)
+		rows, _, _ := suite.repo.List(suite.ctx, p, task, nil, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(rows))
+		suite.Require().Empty(rows[0].Content)
+		suite.Require().NotNil(rows[0].DeletedAt)
+		suite.Require().Equal(int64(1), rows[0].Replies)
+	})
+
+	t.Run("should return not found for unknown comment", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		err := suite.repo.Delete(suite.ctx, p, task, uuid.NewString())
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
 }
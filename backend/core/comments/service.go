@@ -2,73 +2,302 @@ package comments
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/ptracker/activity"
 	"github.com/ptracker/core"
+	"github.com/ptracker/core/events"
 	"github.com/ptracker/core/members"
+	"github.com/ptracker/outbox"
+	"gorm.io/gorm"
 )
 
 type Comment struct {
-	ID          string    `json:"id"`
-	ProjectID   string    `json:"project_id"`
-	TaskID      string    `json:"task_id"`
-	Content     string    `json:"content"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string     `json:"id"`
+	ProjectID   string     `json:"project_id"`
+	TaskID      string     `json:"task_id"`
+	ParentID    *string    `json:"parent_id"`
+	Content     string     `json:"content"`
+	EditedAt    *time.Time `json:"edited_at"`
+	Deleted     bool       `json:"deleted"`
+	Replies     int64      `json:"replies"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 	core.Avatar `json:"avatar"`
 }
 
 type CommentService struct {
-	commentRepo *CommentRepository
-	memberRepo  *members.MemberRepository
+	txManager    *core.TxManager
+	commentRepo  *CommentRepository
+	memberRepo   *members.MemberRepository
+	eventRepo    *events.EventRepository
+	activityRepo *activity.ActivityRepository
+	outboxRepo   *outbox.OutboxRepository
+	taskChecker  core.TaskChecker
 }
 
-func NewCommentService(commentRepo *CommentRepository,
-	memberRepo *members.MemberRepository) *CommentService {
+func NewCommentService(txManager *core.TxManager,
+	commentRepo *CommentRepository,
+	memberRepo *members.MemberRepository,
+	eventRepo *events.EventRepository,
+	activityRepo *activity.ActivityRepository,
+	outboxRepo *outbox.OutboxRepository,
+	taskChecker core.TaskChecker) *CommentService {
 	return &CommentService{
-		commentRepo: commentRepo,
-		memberRepo:  memberRepo,
+		txManager:    txManager,
+		commentRepo:  commentRepo,
+		memberRepo:   memberRepo,
+		eventRepo:    eventRepo,
+		activityRepo: activityRepo,
+		outboxRepo:   outboxRepo,
+		taskChecker:  taskChecker,
 	}
 }
 
+// Creates a comment on the task, or a reply to the top level comment parentID
 func (s *CommentService) Create(ctx context.Context,
 	projectID, taskID, userID string,
+	parentID *string,
 	comment string) (string, error) {
 
 	var err error
 
-	err = core.NeedsToBeAMember(ctx, s.memberRepo, projectID, userID)
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_COMMENT)
 	if err != nil {
-		return "", fmt.Errorf("needs to be a member: %w", err)
+		return "", fmt.Errorf("authorize comment: %w", err)
+	}
+
+	err = core.NeedsToBeAProjectTask(ctx, s.taskChecker, projectID, taskID)
+	if err != nil {
+		return "", fmt.Errorf("needs to be a project task: %w", err)
 	}
 
 	if strings.Trim(comment, " ") == "" {
 		return "", core.ErrInvalidValue
 	}
 
-	commentID, err := s.commentRepo.Create(ctx, projectID, taskID, userID, comment)
+	// replies go to the live top level comments of the same task
+	if parentID != nil {
+		parent, err := s.commentRepo.Get(ctx, projectID, taskID, *parentID)
+		if errors.Is(err, core.ErrNotFound) {
+			return "", core.ErrInvalidValue
+		} else if err != nil {
+			return "", fmt.Errorf("comment repository get: %w", err)
+		}
+		if parent.ParentID != nil || parent.DeletedAt != nil {
+			return "", core.ErrInvalidValue
+		}
+	}
+
+	var commentID string
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		var err error
+
+		commentID, err = s.commentRepo.WithTx(tx).Create(ctx, projectID, taskID, userID,
+			parentID, comment)
+		if err != nil {
+			return fmt.Errorf("comment repository create: %w", err)
+		}
+
+		err = s.eventRepo.WithTx(tx).Create(ctx, projectID, taskID, userID,
+			core.TASK_EVENT_COMMENTED, nil, nil, &commentID)
+		if err != nil {
+			return fmt.Errorf("event repository create: %w", err)
+		}
+
+		err = s.activityRepo.WithTx(tx).CommentAdded(ctx, projectID, taskID, commentID, userID)
+		if err != nil {
+			return fmt.Errorf("activity repository comment added: %w", err)
+		}
+
+		outboxRepo := s.outboxRepo.WithTx(tx)
+
+		err = outboxRepo.Create(ctx, outbox.EV_COMMENT_ADDED, outbox.CommentAdded{
+			ProjectID:   projectID,
+			TaskID:      taskID,
+			CommenterID: userID,
+		})
+		if err != nil {
+			return fmt.Errorf("outbox repository create comment added: %w", err)
+		}
+
+		if strings.Contains(comment, "@") {
+			err = outboxRepo.Create(ctx, outbox.EV_MENTIONED, outbox.Mentioned{
+				ProjectID:   projectID,
+				TaskID:      taskID,
+				SourceID:    commentID,
+				MentionerID: userID,
+				Content:     comment,
+			})
+			if err != nil {
+				return fmt.Errorf("outbox repository create mentioned: %w", err)
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("comment repository create: %w", err)
+		return "", fmt.Errorf("txManager WithTx: %w", err)
 	}
 
 	return commentID, nil
 }
 
+/*
+Checks that user userID can change the comment, the author can as long as
+they can comment, the owner can moderate any comment
+*/
+func (s *CommentService) authorizeChange(ctx context.Context,
+	projectID, taskID, commentID, userID string) error {
+
+	comment, err := s.commentRepo.Get(ctx, projectID, taskID, commentID)
+	if err != nil {
+		return fmt.Errorf("comment repository get: %w", err)
+	}
+	if comment.DeletedAt != nil {
+		return core.ErrNotFound
+	}
+
+	if comment.UserID == userID {
+		err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+			core.PERMISSION_COMMENT)
+		if err != nil {
+			return fmt.Errorf("authorize comment: %w", err)
+		}
+	} else {
+		err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+			core.PERMISSION_MODERATE_COMMENTS)
+		if err != nil {
+			return fmt.Errorf("authorize moderate comments: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *CommentService) Update(ctx context.Context,
+	projectID, taskID, commentID, userID string,
+	comment string) error {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_VIEW_PROJECT)
+	if err != nil {
+		return fmt.Errorf("authorize view project: %w", err)
+	}
+
+	err = core.NeedsToBeAProjectTask(ctx, s.taskChecker, projectID, taskID)
+	if err != nil {
+		return fmt.Errorf("needs to be a project task: %w", err)
+	}
+
+	err = s.authorizeChange(ctx, projectID, taskID, commentID, userID)
+	if err != nil {
+		return fmt.Errorf("authorize change: %w", err)
+	}
+
+	if strings.Trim(comment, " ") == "" {
+		return core.ErrInvalidValue
+	}
+
+	// only the members newly mentioned by the edit are notified
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		err := s.commentRepo.WithTx(tx).Update(ctx, projectID, taskID, commentID, comment)
+		if err != nil {
+			return fmt.Errorf("comment repository update: %w", err)
+		}
+
+		if !strings.Contains(comment, "@") {
+			return nil
+		}
+
+		err = s.outboxRepo.WithTx(tx).Create(ctx, outbox.EV_MENTIONED, outbox.Mentioned{
+			ProjectID:   projectID,
+			TaskID:      taskID,
+			SourceID:    commentID,
+			MentionerID: userID,
+			Content:     comment,
+		})
+		if err != nil {
+			return fmt.Errorf("outbox repository create: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("txManager WithTx: %w", err)
+	}
+
+	return nil
+}
+
+func (s *CommentService) Delete(ctx context.Context,
+	projectID, taskID, commentID, userID string) error {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_VIEW_PROJECT)
+	if err != nil {
+		return fmt.Errorf("authorize view project: %w", err)
+	}
+
+	err = core.NeedsToBeAProjectTask(ctx, s.taskChecker, projectID, taskID)
+	if err != nil {
+		return fmt.Errorf("needs to be a project task: %w", err)
+	}
+
+	err = s.authorizeChange(ctx, projectID, taskID, commentID, userID)
+	if err != nil {
+		return fmt.Errorf("authorize change: %w", err)
+	}
+
+	err = s.commentRepo.Delete(ctx, projectID, taskID, commentID)
+	if err != nil {
+		return fmt.Errorf("comment repository delete: %w", err)
+	}
+
+	return nil
+}
+
+// Lists the top level comments of the task, or the replies of the comment parentID
 func (s *CommentService) List(ctx context.Context,
-	projectID, taskID, userID string) ([]Comment, error) {
+	projectID, taskID, userID string,
+	parentID *string,
+	page core.PageQuery) ([]Comment, string, error) {
 
 	var err error
 
-	err = core.NeedsToBeAMember(ctx, s.memberRepo, projectID, userID)
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_VIEW_PROJECT)
+	if err != nil {
+		return nil, "", fmt.Errorf("authorize view project: %w", err)
+	}
+
+	err = core.NeedsToBeAProjectTask(ctx, s.taskChecker, projectID, taskID)
 	if err != nil {
-		return nil, fmt.Errorf("needs to be a member: %w", err)
+		return nil, "", fmt.Errorf("needs to be a project task: %w", err)
+	}
+
+	if page.Limit <= 0 || page.Limit > core.MAX_LIST_LIMIT {
+		return nil, "", core.ErrInvalidValue
+	}
+
+	if parentID != nil {
+		_, err = s.commentRepo.Get(ctx, projectID, taskID, *parentID)
+		if err != nil {
+			return nil, "", fmt.Errorf("comment repository get: %w", err)
+		}
 	}
 
-	rows, err := s.commentRepo.List(ctx, projectID, taskID)
+	rows, nextCursor, err := s.commentRepo.List(ctx, projectID, taskID, parentID, page)
 	if err != nil {
-		return nil, fmt.Errorf("comment repository comments: %w", err)
+		return nil, "", fmt.Errorf("comment repository comments: %w", err)
 	}
 
 	comments := []Comment{}
@@ -77,7 +306,11 @@ func (s *CommentService) List(ctx context.Context,
 			ID:        r.ID,
 			ProjectID: r.ProjectID,
 			TaskID:    r.TaskID,
+			ParentID:  r.ParentID,
 			Content:   r.Content,
+			EditedAt:  r.EditedAt,
+			Deleted:   r.DeletedAt != nil,
+			Replies:   r.Replies,
 			CreatedAt: r.CreatedAt,
 			UpdatedAt: r.UpdatedAt,
 			Avatar: core.Avatar{
@@ -90,5 +323,5 @@ func (s *CommentService) List(ctx context.Context,
 		})
 	}
 
-	return comments, nil
+	return comments, nextCursor, nil
 }
@@ -5,8 +5,13 @@ import (
 	"log"
 	"testing"
 
+	"github.com/ptracker/activity"
 	"github.com/ptracker/core"
+	"github.com/ptracker/core/events"
 	"github.com/ptracker/core/members"
+	"github.com/ptracker/core/tasks"
+	"github.com/ptracker/models"
+	"github.com/ptracker/outbox"
 	"github.com/ptracker/testdata"
 	"github.com/ptracker/testhelpers"
 	"github.com/ptracker/testhelpers/fixtures"
@@ -40,7 +45,7 @@ func (suite *commentServiceTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
-	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{})
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -52,7 +57,12 @@ func (suite *commentServiceTestSuite) SetupSuite() {
 
 	commentRepo := NewCommentRepository(suite.db)
 	memberRepo := members.NewMemberRepository(suite.db)
-	service := NewCommentService(commentRepo, memberRepo)
+	taskRepo := tasks.NewTaskRepository(suite.db)
+	eventRepo := events.NewEventRepository(suite.db)
+	txManager := core.NewTxManager(suite.db)
+	activityRepo := activity.NewActivityRepository(suite.db)
+	outboxRepo := outbox.NewOutboxRepository(suite.db)
+	service := NewCommentService(txManager, commentRepo, memberRepo, eventRepo, activityRepo, outboxRepo, taskRepo)
 	suite.service = service
 
 	suite.fixtures = fixtures.New(suite.ctx, suite.db)
@@ -75,7 +85,7 @@ func (suite *commentServiceTestSuite) TestCreate() {
 		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
 
-		id, err := suite.service.Create(suite.ctx, p, task, USER_ONE, "hello")
+		id, err := suite.service.Create(suite.ctx, p, task, USER_ONE, nil, "hello")
 
 		suite.Cleanup()
 
@@ -83,11 +93,25 @@ func (suite *commentServiceTestSuite) TestCreate() {
 		suite.Require().NotEmpty(id)
 	})
 
+	t.Run("should record commented event", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		id, err := suite.service.Create(suite.ctx, p, task, USER_ONE, nil, "hello")
+		event, _ := gorm.G[models.TaskEvent](suite.db).Where("task_id = ?", task).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(core.TASK_EVENT_COMMENTED, event.Type)
+		suite.Require().Equal(id, *event.NewValue)
+	})
+
 	t.Run("should return invalid value for empty comment", func(t *testing.T) {
 		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
 
-		_, err := suite.service.Create(suite.ctx, p, task, USER_ONE, "    ")
+		_, err := suite.service.Create(suite.ctx, p, task, USER_ONE, nil, "    ")
 
 		suite.Cleanup()
 
@@ -98,7 +122,19 @@ func (suite *commentServiceTestSuite) TestCreate() {
 		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
 
-		_, err := suite.service.Create(suite.ctx, p, task, USER_TWO, "hi")
+		_, err := suite.service.Create(suite.ctx, p, task, USER_TWO, nil, "hi")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+
+	t.Run("should return forbidden when requester is a viewer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_VIEWER))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		_, err := suite.service.Create(suite.ctx, p, task, USER_TWO, nil, "hi")
 
 		suite.Cleanup()
 
@@ -113,7 +149,7 @@ func (suite *commentServiceTestSuite) TestList() {
 		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
 
-		rows, err := suite.service.List(suite.ctx, p, task, USER_ONE)
+		rows, _, err := suite.service.List(suite.ctx, p, task, USER_ONE, nil, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -128,7 +164,7 @@ func (suite *commentServiceTestSuite) TestList() {
 		suite.fixtures.InsertComment(fixtures.GetCommentRow(p, task, USER_ONE, "hello"))
 		suite.fixtures.InsertComment(fixtures.GetCommentRow(p, task, USER_TWO, "world"))
 
-		rows, err := suite.service.List(suite.ctx, p, task, USER_ONE)
+		rows, _, err := suite.service.List(suite.ctx, p, task, USER_ONE, nil, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -141,10 +177,199 @@ func (suite *commentServiceTestSuite) TestList() {
 		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
 		suite.fixtures.InsertComment(fixtures.GetCommentRow(p, task, USER_ONE, "hello"))
 
-		_, err := suite.service.List(suite.ctx, p, task, USER_THREE)
+		_, _, err := suite.service.List(suite.ctx, p, task, USER_THREE, nil, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 
 		suite.Require().ErrorIs(err, core.ErrForbidden)
 	})
+	t.Run("should list comments for viewer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_VIEWER))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		_, _, err := suite.service.List(suite.ctx, p, task, USER_TWO, nil, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+	})
+}
+
+func (suite *commentServiceTestSuite) TestReply() {
+	t := suite.T()
+
+	t.Run("should reply to top level comment", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		parent := fixtures.GetCommentRow(p, task, USER_ONE, "hello")
+		suite.fixtures.InsertComment(parent)
+
+		id, err := suite.service.Create(suite.ctx, p, task, USER_ONE, &parent.ID, "world")
+		rows, _, _ := suite.service.List(suite.ctx, p, task, USER_ONE, &parent.ID, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(rows))
+		suite.Require().Equal(id, rows[0].ID)
+		suite.Require().Equal(parent.ID, *rows[0].ParentID)
+	})
+
+	t.Run("should not reply to a reply", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		parent := fixtures.GetCommentRow(p, task, USER_ONE, "hello")
+		reply := fixtures.GetReplyRow(parent, USER_ONE, "world")
+		suite.fixtures.InsertComment(parent)
+		suite.fixtures.InsertComment(reply)
+
+		_, err := suite.service.Create(suite.ctx, p, task, USER_ONE, &reply.ID, "again")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+
+	t.Run("should not reply to comment of another task", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		other := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		parent := fixtures.GetCommentRow(p, other, USER_ONE, "hello")
+		suite.fixtures.InsertComment(parent)
+
+		_, err := suite.service.Create(suite.ctx, p, task, USER_ONE, &parent.ID, "world")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+}
+
+func (suite *commentServiceTestSuite) TestUpdate() {
+	t := suite.T()
+
+	t.Run("should let the author edit the comment", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		c := fixtures.GetCommentRow(p, task, USER_TWO, "hello")
+		suite.fixtures.InsertComment(c)
+
+		err := suite.service.Update(suite.ctx, p, task, c.ID, USER_TWO, "hi")
+		rows, _, _ := suite.service.List(suite.ctx, p, task, USER_TWO, nil, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal("hi", rows[0].Content)
+		suite.Require().NotNil(rows[0].EditedAt)
+	})
+
+	t.Run("should not let another member edit the comment", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MAINTAINER))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		c := fixtures.GetCommentRow(p, task, USER_ONE, "hello")
+		suite.fixtures.InsertComment(c)
+
+		err := suite.service.Update(suite.ctx, p, task, c.ID, USER_TWO, "hi")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+
+	t.Run("should return invalid value for empty content", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		c := fixtures.GetCommentRow(p, task, USER_ONE, "hello")
+		suite.fixtures.InsertComment(c)
+
+		err := suite.service.Update(suite.ctx, p, task, c.ID, USER_ONE, "   ")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+}
+
+func (suite *commentServiceTestSuite) TestDelete() {
+	t := suite.T()
+
+	t.Run("should let the owner delete any comment", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		c := fixtures.GetCommentRow(p, task, USER_TWO, "hello")
+		suite.fixtures.InsertComment(c)
+		suite.fixtures.InsertComment(fixtures.GetReplyRow(c, USER_ONE, "world"))
+
+		err := suite.service.Delete(suite.ctx, p, task, c.ID, USER_ONE)
+		rows, _, _ := suite.service.List(suite.ctx, p, task, USER_ONE, nil, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(rows))
+		suite.Require().True(rows[0].Deleted)
+		suite.Require().Empty(rows[0].Content)
+		suite.Require().Equal(int64(1), rows[0].Replies)
+	})
+
+	t.Run("should not let another member delete the comment", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		c := fixtures.GetCommentRow(p, task, USER_ONE, "hello")
+		suite.fixtures.InsertComment(c)
+
+		err := suite.service.Delete(suite.ctx, p, task, c.ID, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+
+	t.Run("should return not found when comment is already deleted", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		c := fixtures.GetCommentRow(p, task, USER_ONE, "hello")
+		suite.fixtures.InsertComment(c)
+
+		_ = suite.service.Delete(suite.ctx, p, task, c.ID, USER_ONE)
+		err := suite.service.Delete(suite.ctx, p, task, c.ID, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
+
+func (suite *commentServiceTestSuite) TestCrossProjectAccess() {
+	t := suite.T()
+
+	t.Run("should not comment on task of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_TWO))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_ONGOING))
+
+		_, err := suite.service.Create(suite.ctx, p2, task, USER_TWO, nil, "hi")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+	t.Run("should not list comments of task of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_TWO))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertComment(fixtures.GetCommentRow(p1, task, USER_ONE, "secret"))
+
+		_, _, err := suite.service.List(suite.ctx, p2, task, USER_TWO, nil, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
 }
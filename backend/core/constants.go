@@ -1,8 +1,10 @@
 package core
 
 const (
-	ROLE_OWNER  = "Owner"
-	ROLE_MEMBER = "Member"
+	ROLE_OWNER      = "Owner"
+	ROLE_MAINTAINER = "Maintainer"
+	ROLE_MEMBER     = "Member"
+	ROLE_VIEWER     = "Viewer"
 )
 
 const (
@@ -17,3 +19,38 @@ const (
 	TASK_STATUS_COMPLETED  = "Completed"
 	TASK_STATUS_ABANDONED  = "Abandoned"
 )
+
+// Task priorities, in increasing order of urgency
+const (
+	TASK_PRIORITY_LOW    = "Low"
+	TASK_PRIORITY_MEDIUM = "Medium"
+	TASK_PRIORITY_HIGH   = "High"
+	TASK_PRIORITY_URGENT = "Urgent"
+)
+
+// Kinds of the task events in the task history
+const (
+	TASK_EVENT_CREATED          = "created"
+	TASK_EVENT_UPDATED          = "updated"
+	TASK_EVENT_ASSIGNEE_ADDED   = "assignee_added"
+	TASK_EVENT_ASSIGNEE_REMOVED = "assignee_removed"
+	TASK_EVENT_COMMENTED        = "commented"
+)
+
+const (
+	SORT_ORDER_ASC  = "asc"
+	SORT_ORDER_DESC = "desc"
+)
+
+const (
+	TASK_SORT_CREATED_AT = "created_at"
+	TASK_SORT_UPDATED_AT = "updated_at"
+	TASK_SORT_TITLE      = "title"
+	TASK_SORT_PRIORITY   = "priority"
+	TASK_SORT_ESTIMATE   = "estimate"
+)
+
+const (
+	DEFAULT_LIST_LIMIT = 10
+	MAX_LIST_LIMIT     = 100
+)
@@ -7,3 +7,5 @@ var ErrNotFound = errors.New("resource not found")
 var ErrDuplicate = errors.New("duplicate value")
 var ErrInvalidValue = errors.New("invalid value")
 var ErrForbidden = errors.New("forbidden")
+var ErrConflict = errors.New("conflicting state")
+var ErrPreconditionFailed = errors.New("precondition failed")
@@ -0,0 +1,112 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"gorm.io/gorm"
+)
+
+type EventRow struct {
+	ID        string    `gorm:"column:id"`
+	ProjectID string    `gorm:"column:project_id"`
+	TaskID    string    `gorm:"column:task_id"`
+	Type      string    `gorm:"column:type"`
+	Field     *string   `gorm:"column:field"`
+	OldValue  *string   `gorm:"column:old_value"`
+	NewValue  *string   `gorm:"column:new_value"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+
+	ActorID     string  `gorm:"column:actor_id"`
+	Username    string  `gorm:"column:username"`
+	DisplayName *string `gorm:"column:display_name"`
+	Email       string  `gorm:"column:email"`
+	AvatarURL   *string `gorm:"column:avatar_url"`
+}
+
+type EventRepository struct {
+	db *gorm.DB
+}
+
+func NewEventRepository(db *gorm.DB) *EventRepository {
+	return &EventRepository{
+		db: db,
+	}
+}
+
+func (r *EventRepository) WithTx(tx *gorm.DB) *EventRepository {
+	return NewEventRepository(tx)
+}
+
+func (r *EventRepository) Create(ctx context.Context,
+	projectID, taskID, actorID, eventType string,
+	field, oldValue, newValue *string) error {
+
+	event := models.TaskEvent{
+		ID:        uuid.NewString(),
+		ProjectID: projectID,
+		TaskID:    taskID,
+		ActorID:   actorID,
+		Type:      eventType,
+		Field:     field,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+	}
+	err := gorm.G[models.TaskEvent](r.db).Create(ctx, &event)
+	if err != nil {
+		return fmt.Errorf("gorm create: %w", err)
+	}
+
+	return nil
+}
+
+func (r *EventRepository) ListByTask(ctx context.Context,
+	projectID, taskID string,
+	page core.PageQuery) ([]EventRow, string, error) {
+
+	cursor, err := core.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := r.db.WithContext(ctx).
+		Table("task_events e").
+		Select(`e.id, e.project_id, e.task_id, e.type, 
+				e.field, e.old_value, e.new_value, e.created_at, 
+				e.actor_id, 
+				u.username as username, 
+				u.display_name as display_name, 
+				u.email as email, 
+				u.avatar_url as avatar_url`).
+		Joins("INNER JOIN users as u ON u.id=e.actor_id").
+		Where("e.project_id = ? AND e.task_id = ?", projectID, taskID)
+
+	// history is read from the latest change backwards
+	if cursor != nil {
+		createdAt, err := cursor.Time()
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(e.created_at, e.id) < (?, ?)", createdAt, cursor.ID)
+	}
+
+	var rows = []EventRow{}
+	err = query.
+		Order("e.created_at DESC, e.id DESC").
+		Limit(page.Limit + 1).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, "", fmt.Errorf("db query context: %w", err)
+	}
+
+	rows, nextCursor := core.Paginate(rows, page.Limit,
+		func(row EventRow) core.Cursor {
+			return core.NewTimeCursor(row.CreatedAt, row.ID)
+		})
+
+	return rows, nextCursor, nil
+}
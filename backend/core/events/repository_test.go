@@ -0,0 +1,157 @@
+package events
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/ptracker/testhelpers/fixtures"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var USER_ONE, USER_TWO string
+
+type eventRepositoryTestSuite struct {
+	suite.Suite
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	fixtures    *fixtures.Fixtures
+	repo        *EventRepository
+	ctx         context.Context
+}
+
+func (suite *eventRepositoryTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx = context.Background()
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.repo = NewEventRepository(suite.db)
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.fixtures = fixtures.New(suite.ctx, suite.db)
+
+	USER_ONE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_TWO = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+}
+
+func (suite *eventRepositoryTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM projects").Error
+	suite.Require().NoError(err)
+}
+
+func TestEventRepository(t *testing.T) {
+	suite.Run(t, new(eventRepositoryTestSuite))
+}
+
+func (suite *eventRepositoryTestSuite) TestCreate() {
+	t := suite.T()
+
+	t.Run("should create task event", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		field, oldValue, newValue := "status", core.TASK_STATUS_ONGOING, core.TASK_STATUS_ABANDONED
+
+		err := suite.repo.Create(suite.ctx, p, taskID, USER_ONE,
+			core.TASK_EVENT_UPDATED, &field, &oldValue, &newValue)
+		event, _ := gorm.G[models.TaskEvent](suite.db).Where("task_id = ?", taskID).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(USER_ONE, event.ActorID)
+		suite.Require().Equal(core.TASK_EVENT_UPDATED, event.Type)
+		suite.Require().Equal(core.TASK_STATUS_ABANDONED, *event.NewValue)
+	})
+}
+
+func (suite *eventRepositoryTestSuite) TestListByTask() {
+	t := suite.T()
+
+	t.Run("should get empty history", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		events, _, err := suite.repo.ListByTask(suite.ctx, p, taskID, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().NotNil(events)
+		suite.Require().Equal(0, len(events))
+	})
+	t.Run("should get latest events first with their actor", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		now := time.Now()
+		suite.fixtures.InsertTaskEvent(fixtures.GetTaskEventRow(p, taskID, USER_ONE,
+			core.TASK_EVENT_CREATED, now.Add(-time.Hour)))
+		suite.fixtures.InsertTaskEvent(fixtures.GetTaskEventRow(p, taskID, USER_TWO,
+			core.TASK_EVENT_COMMENTED, now))
+
+		events, _, err := suite.repo.ListByTask(suite.ctx, p, taskID, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, len(events))
+		suite.Require().Equal(core.TASK_EVENT_COMMENTED, events[0].Type)
+		suite.Require().Equal(USER_TWO, events[0].ActorID)
+		suite.Require().Equal(core.TASK_EVENT_CREATED, events[1].Type)
+	})
+	t.Run("should get next page of history", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		now := time.Now()
+		for i := range 3 {
+			suite.fixtures.InsertTaskEvent(fixtures.GetTaskEventRow(p, taskID, USER_ONE,
+				core.TASK_EVENT_UPDATED, now.Add(time.Duration(i)*time.Minute)))
+		}
+
+		first, cursor, err := suite.repo.ListByTask(suite.ctx, p, taskID, core.PageQuery{Limit: 2})
+		second, nextCursor, _ := suite.repo.ListByTask(suite.ctx, p, taskID, core.PageQuery{Cursor: cursor, Limit: 2})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, len(first))
+		suite.Require().NotEmpty(cursor)
+		suite.Require().Equal(1, len(second))
+		suite.Require().Empty(nextCursor)
+	})
+	t.Run("should not get events of task of another project", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p2, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertTaskEvent(fixtures.GetTaskEventRow(p2, taskID, USER_ONE,
+			core.TASK_EVENT_CREATED, time.Now()))
+
+		events, _, err := suite.repo.ListByTask(suite.ctx, p, taskID, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(0, len(events))
+	})
+}
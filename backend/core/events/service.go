@@ -0,0 +1,88 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/members"
+)
+
+type Event struct {
+	ID        string      `json:"id"`
+	ProjectID string      `json:"project_id"`
+	TaskID    string      `json:"task_id"`
+	Type      string      `json:"type"`
+	Field     *string     `json:"field"`
+	OldValue  *string     `json:"old_value"`
+	NewValue  *string     `json:"new_value"`
+	CreatedAt time.Time   `json:"created_at"`
+	Actor     core.Avatar `json:"actor"`
+}
+
+type EventService struct {
+	eventRepo   *EventRepository
+	memberRepo  *members.MemberRepository
+	taskChecker core.TaskChecker
+}
+
+func NewEventService(eventRepo *EventRepository,
+	memberRepo *members.MemberRepository,
+	taskChecker core.TaskChecker) *EventService {
+	return &EventService{
+		eventRepo:   eventRepo,
+		memberRepo:  memberRepo,
+		taskChecker: taskChecker,
+	}
+}
+
+func (s *EventService) History(ctx context.Context,
+	projectID, taskID, userID string,
+	page core.PageQuery) ([]Event, string, error) {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_VIEW_PROJECT)
+	if err != nil {
+		return nil, "", fmt.Errorf("authorize view project: %w", err)
+	}
+
+	err = core.NeedsToBeAProjectTask(ctx, s.taskChecker, projectID, taskID)
+	if err != nil {
+		return nil, "", fmt.Errorf("needs to be a project task: %w", err)
+	}
+
+	if page.Limit <= 0 || page.Limit > core.MAX_LIST_LIMIT {
+		return nil, "", core.ErrInvalidValue
+	}
+
+	rows, nextCursor, err := s.eventRepo.ListByTask(ctx, projectID, taskID, page)
+	if err != nil {
+		return nil, "", fmt.Errorf("event repository list by task: %w", err)
+	}
+
+	events := []Event{}
+	for _, r := range rows {
+		events = append(events, Event{
+			ID:        r.ID,
+			ProjectID: r.ProjectID,
+			TaskID:    r.TaskID,
+			Type:      r.Type,
+			Field:     r.Field,
+			OldValue:  r.OldValue,
+			NewValue:  r.NewValue,
+			CreatedAt: r.CreatedAt,
+			Actor: core.Avatar{
+				UserID:      r.ActorID,
+				Username:    r.Username,
+				DisplayName: r.DisplayName,
+				Email:       r.Email,
+				AvatarURL:   r.AvatarURL,
+			},
+		})
+	}
+
+	return events, nextCursor, nil
+}
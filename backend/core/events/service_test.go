@@ -0,0 +1,137 @@
+package events
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/members"
+	"github.com/ptracker/models"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/ptracker/testhelpers/fixtures"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// tasks package imports events, so the tasks are checked directly
+type taskCheckerStub struct {
+	db *gorm.DB
+}
+
+func (c taskCheckerStub) Exists(ctx context.Context,
+	projectID, taskID string) error {
+
+	_, err := gorm.G[models.Task](c.db).
+		Where("project_id = ? AND id = ?", projectID, taskID).
+		First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return core.ErrNotFound
+	}
+
+	return err
+}
+
+type eventServiceTestSuite struct {
+	suite.Suite
+	ctx         context.Context
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	fixtures    *fixtures.Fixtures
+	service     *EventService
+}
+
+func TestEventService(t *testing.T) {
+	suite.Run(t, new(eventServiceTestSuite))
+}
+
+func (suite *eventServiceTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx = context.Background()
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	eventRepo := NewEventRepository(suite.db)
+	memberRepo := members.NewMemberRepository(suite.db)
+	suite.service = NewEventService(eventRepo, memberRepo, taskCheckerStub{db: suite.db})
+
+	suite.fixtures = fixtures.New(suite.ctx, suite.db)
+
+	USER_ONE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_TWO = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+}
+
+func (suite *eventServiceTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM projects").Error
+	suite.Require().NoError(err)
+}
+
+func (suite *eventServiceTestSuite) TestHistory() {
+	t := suite.T()
+
+	t.Run("should get task history as viewer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_VIEWER))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertTaskEvent(fixtures.GetTaskEventRow(p, taskID, USER_ONE,
+			core.TASK_EVENT_CREATED, time.Now()))
+
+		events, _, err := suite.service.History(suite.ctx, p, taskID, USER_TWO, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(events))
+		suite.Require().Equal(USER_ONE, events[0].Actor.UserID)
+	})
+	t.Run("should be forbidden for non-member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		_, _, err := suite.service.History(suite.ctx, p, taskID, USER_TWO, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+	t.Run("should not find task of another project", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p2, core.TASK_STATUS_ONGOING))
+
+		_, _, err := suite.service.History(suite.ctx, p, taskID, USER_ONE, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+	t.Run("should be invalid with limit above maximum", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		_, _, err := suite.service.History(suite.ctx, p, taskID, USER_ONE,
+			core.PageQuery{Limit: core.MAX_LIST_LIMIT + 1})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+}
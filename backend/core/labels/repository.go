@@ -0,0 +1,183 @@
+package labels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"gorm.io/gorm"
+)
+
+type LabelRepository struct {
+	db *gorm.DB
+}
+
+func NewLabelRepository(db *gorm.DB) *LabelRepository {
+	return &LabelRepository{
+		db: db,
+	}
+}
+
+func (r *LabelRepository) WithTx(tx *gorm.DB) *LabelRepository {
+	return NewLabelRepository(tx)
+}
+
+func (r *LabelRepository) Create(ctx context.Context,
+	projectID, name, color string) (string, error) {
+
+	label := models.Label{
+		ID:        uuid.NewString(),
+		ProjectID: projectID,
+		Name:      name,
+		Color:     color,
+	}
+	err := gorm.G[models.Label](r.db).Create(ctx, &label)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return "", core.ErrDuplicate
+	} else if err != nil {
+		return "", fmt.Errorf("gorm create: %w", err)
+	}
+
+	return label.ID, nil
+}
+
+func (r *LabelRepository) Get(ctx context.Context,
+	projectID, id string) (models.Label, error) {
+
+	label, err := gorm.G[models.Label](r.db).
+		Where("project_id = ? AND id = ?", projectID, id).
+		First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return label, core.ErrNotFound
+	} else if err != nil {
+		return label, fmt.Errorf("gorm query: %w", err)
+	}
+
+	return label, nil
+}
+
+func (r *LabelRepository) GetByName(ctx context.Context,
+	projectID, name string) (models.Label, error) {
+
+	label, err := gorm.G[models.Label](r.db).
+		Where("project_id = ? AND name = ?", projectID, name).
+		First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return label, core.ErrNotFound
+	} else if err != nil {
+		return label, fmt.Errorf("gorm query: %w", err)
+	}
+
+	return label, nil
+}
+
+func (r *LabelRepository) List(ctx context.Context,
+	projectID string) ([]models.Label, error) {
+
+	labels, err := gorm.G[models.Label](r.db).
+		Where("project_id = ?", projectID).
+		Order("name ASC").
+		Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gorm find: %w", err)
+	}
+
+	return labels, nil
+}
+
+func (r *LabelRepository) Update(ctx context.Context,
+	projectID, id string,
+	name, color *string) error {
+
+	label, err := gorm.G[models.Label](r.db).
+		Where("project_id = ? AND id = ?", projectID, id).
+		First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return core.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("gorm query label: %w", err)
+	}
+
+	if name != nil {
+		label.Name = *name
+	}
+
+	if color != nil {
+		label.Color = *color
+	}
+
+	err = r.db.WithContext(ctx).Save(&label).Error
+	if err != nil {
+		return fmt.Errorf("gorm db save: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LabelRepository) Delete(ctx context.Context,
+	projectID, id string) error {
+
+	// task labels are removed by the ON DELETE CASCADE constraint
+	rows, err := gorm.G[models.Label](r.db).
+		Where("project_id = ? AND id = ?", projectID, id).
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("gorm delete: %w", err)
+	}
+	if rows == 0 {
+		return core.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *LabelRepository) AddToTask(ctx context.Context,
+	taskID, labelID string) error {
+
+	taskLabel := models.TaskLabel{
+		TaskID:  taskID,
+		LabelID: labelID,
+	}
+	err := gorm.G[models.TaskLabel](r.db).Create(ctx, &taskLabel)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return core.ErrDuplicate
+	} else if err != nil {
+		return fmt.Errorf("gorm create: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LabelRepository) IsOnTask(ctx context.Context,
+	taskID, labelID string) error {
+
+	_, err := gorm.G[models.TaskLabel](r.db).
+		Where("task_id = ? AND label_id = ?", taskID, labelID).
+		First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return core.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("gorm query: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LabelRepository) RemoveFromTask(ctx context.Context,
+	taskID, labelID string) error {
+
+	rows, err := gorm.G[models.TaskLabel](r.db).
+		Where("task_id = ? AND label_id = ?", taskID, labelID).
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("gorm delete: %w", err)
+	}
+	if rows == 0 {
+		return core.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package labels
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/ptracker/testhelpers/fixtures"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var USER_ONE, USER_TWO string
+
+type labelRepositoryTestSuite struct {
+	suite.Suite
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	fixtures    *fixtures.Fixtures
+	repo        *LabelRepository
+	ctx         context.Context
+}
+
+func (suite *labelRepositoryTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx = context.Background()
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.repo = NewLabelRepository(suite.db)
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.fixtures = fixtures.New(suite.ctx, suite.db)
+
+	USER_ONE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_TWO = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+}
+
+func (suite *labelRepositoryTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM projects").Error
+	suite.Require().NoError(err)
+}
+
+func TestLabelRepository(t *testing.T) {
+	suite.Run(t, new(labelRepositoryTestSuite))
+}
+
+func (suite *labelRepositoryTestSuite) TestCreate() {
+	t := suite.T()
+
+	t.Run("should create label", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		id, err := suite.repo.Create(suite.ctx, p, "bug", "#d73a4a")
+
+		label, _ := gorm.G[models.Label](suite.db).Where("id = ?", id).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal("bug", label.Name)
+		suite.Require().Equal("#d73a4a", label.Color)
+	})
+	t.Run("should get duplicate error for label name taken in project", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.repo.Create(suite.ctx, p, "bug", "#d73a4a")
+
+		_, err := suite.repo.Create(suite.ctx, p, "bug", "#0e8a16")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrDuplicate)
+	})
+}
+
+func (suite *labelRepositoryTestSuite) TestGet() {
+	t := suite.T()
+
+	t.Run("should not get label of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p1))
+
+		_, err := suite.repo.Get(suite.ctx, p2, l)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
+
+func (suite *labelRepositoryTestSuite) TestList() {
+	t := suite.T()
+
+	t.Run("should list labels of the project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p1))
+		suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p1))
+		suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p2))
+
+		labels, err := suite.repo.List(suite.ctx, p1)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, len(labels))
+	})
+}
+
+func (suite *labelRepositoryTestSuite) TestUpdate() {
+	t := suite.T()
+
+	t.Run("should update label color", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+		color := "#0e8a16"
+
+		err := suite.repo.Update(suite.ctx, p, l, nil, &color)
+
+		label, _ := gorm.G[models.Label](suite.db).Where("id = ?", l).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(color, label.Color)
+	})
+}
+
+func (suite *labelRepositoryTestSuite) TestDelete() {
+	t := suite.T()
+
+	t.Run("should delete label and its task labels", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+		suite.fixtures.InsertTaskLabel(fixtures.GetTaskLabelRow(task, l))
+
+		err := suite.repo.Delete(suite.ctx, p, l)
+
+		count, _ := gorm.G[models.TaskLabel](suite.db).Where("label_id = ?", l).Count(suite.ctx, "*")
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().EqualValues(0, count)
+	})
+	t.Run("should get not found error for missing label", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.repo.Delete(suite.ctx, p, "missing")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
+
+func (suite *labelRepositoryTestSuite) TestTaskLabels() {
+	t := suite.T()
+
+	t.Run("should add label to task", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+
+		err := suite.repo.AddToTask(suite.ctx, task, l)
+		isErr := suite.repo.IsOnTask(suite.ctx, task, l)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().NoError(isErr)
+	})
+	t.Run("should get duplicate error when adding label to task twice", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+		suite.fixtures.InsertTaskLabel(fixtures.GetTaskLabelRow(task, l))
+
+		err := suite.repo.AddToTask(suite.ctx, task, l)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrDuplicate)
+	})
+	t.Run("should remove label from task", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+		suite.fixtures.InsertTaskLabel(fixtures.GetTaskLabelRow(task, l))
+
+		err := suite.repo.RemoveFromTask(suite.ctx, task, l)
+		isErr := suite.repo.IsOnTask(suite.ctx, task, l)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().ErrorIs(isErr, core.ErrNotFound)
+	})
+}
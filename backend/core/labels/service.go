@@ -0,0 +1,223 @@
+package labels
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/members"
+)
+
+// Hex RGB color like #1f6feb
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type Label struct {
+	ID        string    `json:"id"`
+	ProjectID string    `json:"project_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type LabelService struct {
+	memberRepo  *members.MemberRepository
+	labelRepo   *LabelRepository
+	taskChecker core.TaskChecker
+}
+
+func NewLabelService(memberRepo *members.MemberRepository,
+	labelRepo *LabelRepository,
+	taskChecker core.TaskChecker) *LabelService {
+	return &LabelService{
+		memberRepo:  memberRepo,
+		labelRepo:   labelRepo,
+		taskChecker: taskChecker,
+	}
+}
+
+func (s *LabelService) Create(ctx context.Context,
+	projectID, userID string,
+	name, color string) (string, error) {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_LABELS)
+	if err != nil {
+		return "", fmt.Errorf("authorize manage labels: %w", err)
+	}
+
+	name = strings.Trim(name, " ")
+	if name == "" || !colorPattern.MatchString(color) {
+		return "", core.ErrInvalidValue
+	}
+
+	if _, err = s.labelRepo.GetByName(ctx, projectID, name); err == nil {
+		return "", core.ErrDuplicate
+	}
+
+	labelID, err := s.labelRepo.Create(ctx, projectID, name, color)
+	if err != nil {
+		return "", fmt.Errorf("label repository create: %w", err)
+	}
+
+	return labelID, nil
+}
+
+func (s *LabelService) List(ctx context.Context,
+	projectID, userID string) ([]Label, error) {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_VIEW_PROJECT)
+	if err != nil {
+		return nil, fmt.Errorf("authorize view project: %w", err)
+	}
+
+	rows, err := s.labelRepo.List(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("label repository list: %w", err)
+	}
+
+	labels := []Label{}
+	for _, r := range rows {
+		labels = append(labels, Label{
+			ID:        r.ID,
+			ProjectID: r.ProjectID,
+			Name:      r.Name,
+			Color:     r.Color,
+			CreatedAt: r.CreatedAt,
+			UpdatedAt: r.UpdatedAt,
+		})
+	}
+
+	return labels, nil
+}
+
+func (s *LabelService) Update(ctx context.Context,
+	projectID, labelID, userID string,
+	name, color *string) error {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_LABELS)
+	if err != nil {
+		return fmt.Errorf("authorize manage labels: %w", err)
+	}
+
+	if name == nil && color == nil {
+		return core.ErrInvalidValue
+	}
+
+	if name != nil {
+		trimmed := strings.Trim(*name, " ")
+		if trimmed == "" {
+			return core.ErrInvalidValue
+		}
+
+		label, err := s.labelRepo.GetByName(ctx, projectID, trimmed)
+		if err == nil && label.ID != labelID {
+			return core.ErrDuplicate
+		}
+		name = &trimmed
+	}
+
+	if color != nil && !colorPattern.MatchString(*color) {
+		return core.ErrInvalidValue
+	}
+
+	err = s.labelRepo.Update(ctx, projectID, labelID, name, color)
+	if err != nil {
+		return fmt.Errorf("label repository update: %w", err)
+	}
+
+	return nil
+}
+
+func (s *LabelService) Delete(ctx context.Context,
+	projectID, labelID, userID string) error {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_LABELS)
+	if err != nil {
+		return fmt.Errorf("authorize manage labels: %w", err)
+	}
+
+	err = s.labelRepo.Delete(ctx, projectID, labelID)
+	if err != nil {
+		return fmt.Errorf("label repository delete: %w", err)
+	}
+
+	return nil
+}
+
+func (s *LabelService) AddToTask(ctx context.Context,
+	projectID, taskID, userID, labelID string) error {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_TASKS)
+	if err != nil {
+		return fmt.Errorf("authorize manage tasks: %w", err)
+	}
+
+	err = core.NeedsToBeAProjectTask(ctx, s.taskChecker, projectID, taskID)
+	if err != nil {
+		return fmt.Errorf("needs to be a project task: %w", err)
+	}
+
+	// labels of other projects can not be put on the task
+	_, err = s.labelRepo.Get(ctx, projectID, labelID)
+	if err != nil {
+		return fmt.Errorf("label repository get: %w", err)
+	}
+
+	if err = s.labelRepo.IsOnTask(ctx, taskID, labelID); err == nil {
+		return core.ErrDuplicate
+	}
+
+	err = s.labelRepo.AddToTask(ctx, taskID, labelID)
+	if err != nil {
+		return fmt.Errorf("label repository add to task: %w", err)
+	}
+
+	return nil
+}
+
+func (s *LabelService) RemoveFromTask(ctx context.Context,
+	projectID, taskID, userID, labelID string) error {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_TASKS)
+	if err != nil {
+		return fmt.Errorf("authorize manage tasks: %w", err)
+	}
+
+	err = core.NeedsToBeAProjectTask(ctx, s.taskChecker, projectID, taskID)
+	if err != nil {
+		return fmt.Errorf("needs to be a project task: %w", err)
+	}
+
+	if err = s.labelRepo.IsOnTask(ctx, taskID, labelID); errors.Is(err, core.ErrNotFound) {
+		return core.ErrNotFound
+	}
+
+	err = s.labelRepo.RemoveFromTask(ctx, taskID, labelID)
+	if err != nil {
+		return fmt.Errorf("label repository remove from task: %w", err)
+	}
+
+	return nil
+}
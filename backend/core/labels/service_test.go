@@ -0,0 +1,245 @@
+package labels
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/members"
+	"github.com/ptracker/models"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/ptracker/testhelpers/fixtures"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// tasks package imports labels, so the tasks are checked directly
+type taskCheckerStub struct {
+	db *gorm.DB
+}
+
+func (c taskCheckerStub) Exists(ctx context.Context,
+	projectID, taskID string) error {
+
+	_, err := gorm.G[models.Task](c.db).
+		Where("project_id = ? AND id = ?", projectID, taskID).
+		First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return core.ErrNotFound
+	}
+
+	return err
+}
+
+type labelServiceTestSuite struct {
+	suite.Suite
+	ctx         context.Context
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	fixtures    *fixtures.Fixtures
+	service     *LabelService
+}
+
+func TestLabelService(t *testing.T) {
+	suite.Run(t, new(labelServiceTestSuite))
+}
+
+func (suite *labelServiceTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx = context.Background()
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	memberRepo := members.NewMemberRepository(suite.db)
+	labelRepo := NewLabelRepository(suite.db)
+	suite.service = NewLabelService(memberRepo, labelRepo, taskCheckerStub{db: suite.db})
+
+	suite.fixtures = fixtures.New(suite.ctx, suite.db)
+
+	USER_ONE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_TWO = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+}
+
+func (suite *labelServiceTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM projects").Error
+	suite.Require().NoError(err)
+}
+
+func (suite *labelServiceTestSuite) TestCreate() {
+	t := suite.T()
+
+	t.Run("should create label as owner", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, err := suite.service.Create(suite.ctx, p, USER_ONE, "bug", "#d73a4a")
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+	})
+	t.Run("should be forbidden for maintainer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MAINTAINER))
+
+		_, err := suite.service.Create(suite.ctx, p, USER_TWO, "bug", "#d73a4a")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+	t.Run("should get invalid value error for malformed color", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, err := suite.service.Create(suite.ctx, p, USER_ONE, "bug", "red")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should get duplicate error for existing name", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.service.Create(suite.ctx, p, USER_ONE, "bug", "#d73a4a")
+
+		_, err := suite.service.Create(suite.ctx, p, USER_ONE, "bug", "#0e8a16")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrDuplicate)
+	})
+}
+
+func (suite *labelServiceTestSuite) TestList() {
+	t := suite.T()
+
+	t.Run("should list labels as viewer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_VIEWER))
+		suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+
+		labels, err := suite.service.List(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(labels))
+	})
+}
+
+func (suite *labelServiceTestSuite) TestUpdate() {
+	t := suite.T()
+
+	t.Run("should rename label", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+		name := "feature"
+
+		err := suite.service.Update(suite.ctx, p, l, USER_ONE, &name, nil)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+	})
+	t.Run("should get duplicate error when renaming to another label name", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		other := fixtures.RandomLabelRow(p)
+		suite.fixtures.InsertLabel(other)
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+
+		err := suite.service.Update(suite.ctx, p, l, USER_ONE, &other.Name, nil)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrDuplicate)
+	})
+}
+
+func (suite *labelServiceTestSuite) TestDelete() {
+	t := suite.T()
+
+	t.Run("should be forbidden for member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+
+		err := suite.service.Delete(suite.ctx, p, l, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+}
+
+func (suite *labelServiceTestSuite) TestAddToTask() {
+	t := suite.T()
+
+	t.Run("should add label to task as maintainer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MAINTAINER))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+
+		err := suite.service.AddToTask(suite.ctx, p, task, USER_TWO, l)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+	})
+	t.Run("should get not found error for label of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_UNASSIGNED))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p2))
+
+		err := suite.service.AddToTask(suite.ctx, p1, task, USER_ONE, l)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+	t.Run("should get duplicate error when label is already on task", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+		suite.fixtures.InsertTaskLabel(fixtures.GetTaskLabelRow(task, l))
+
+		err := suite.service.AddToTask(suite.ctx, p, task, USER_ONE, l)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrDuplicate)
+	})
+}
+
+func (suite *labelServiceTestSuite) TestRemoveFromTask() {
+	t := suite.T()
+
+	t.Run("should get not found error when label is not on task", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+
+		err := suite.service.RemoveFromTask(suite.ctx, p, task, USER_ONE, l)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
@@ -103,3 +103,63 @@ func (r *MemberRepository) List(ctx context.Context,
 
 	return rows, nil
 }
+
+/*
+Ends the membership of user userID in project projectID
+
+The assignments of the user in the project and the join request are removed
+along with the membership, the user does not keep working on the tasks and
+can request to join again unless banned.
+*/
+func (r *MemberRepository) Delete(ctx context.Context,
+	projectID, userID string) error {
+
+	_, err := gorm.G[models.Assignee](r.db).Where(
+		"project_id = ? AND user_id = ?",
+		projectID, userID).Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("gorm delete assignees: %w", err)
+	}
+
+	_, err = gorm.G[models.JoinRequest](r.db).Where(
+		"project_id = ? AND user_id = ?",
+		projectID, userID).Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("gorm delete join request: %w", err)
+	}
+
+	rows, err := gorm.G[models.Member](r.db).Where(
+		"project_id = ? AND user_id = ?",
+		projectID, userID).Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("gorm delete member: %w", err)
+	}
+	if rows == 0 {
+		return core.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *MemberRepository) UpdateRole(ctx context.Context,
+	projectID, userID, userRole string) error {
+
+	member, err := gorm.G[models.Member](r.db).Where(
+		"project_id = ? AND user_id = ?",
+		projectID, userID).First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return core.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("gorm query: %w", err)
+	}
+
+	member.Role = models.UserRole{
+		String: userRole,
+	}
+	err = r.db.WithContext(ctx).Save(&member).Error
+	if err != nil {
+		return fmt.Errorf("gorm db save: %w", err)
+	}
+
+	return nil
+}
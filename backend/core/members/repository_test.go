@@ -36,7 +36,7 @@ func (suite *memberRepositoryTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
-	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{})
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -207,3 +207,94 @@ func (suite *memberRepositoryTestSuite) TestList() {
 		)
 	})
 }
+
+func (suite *memberRepositoryTestSuite) TestDelete() {
+	t := suite.T()
+
+	t.Run("should delete member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		err := suite.repo.Delete(suite.ctx, p, USER_TWO)
+
+		_, roleErr := suite.repo.Role(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().ErrorIs(roleErr, core.ErrNotFound)
+	})
+	t.Run("should delete assignments of member in the project only", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p1, USER_TWO, core.ROLE_MEMBER))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p2, USER_TWO, core.ROLE_MEMBER))
+		t1 := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_ONGOING))
+		t2 := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p2, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p1, t1, USER_TWO))
+		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p2, t2, USER_TWO))
+
+		suite.repo.Delete(suite.ctx, p1, USER_TWO)
+
+		var remaining []string
+		suite.db.Model(&models.Assignee{}).
+			Where("user_id = ?", USER_TWO).
+			Pluck("project_id", &remaining)
+
+		suite.Cleanup()
+
+		suite.Require().Equal([]string{p2}, remaining)
+	})
+	t.Run("should delete join request of member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertJoinRequest(fixtures.GetJoinRequest(p, USER_TWO))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		suite.repo.Delete(suite.ctx, p, USER_TWO)
+
+		var count int64
+		suite.db.Model(&models.JoinRequest{}).
+			Where("project_id = ? AND user_id = ?", p, USER_TWO).
+			Count(&count)
+
+		suite.Cleanup()
+
+		suite.Require().EqualValues(0, count)
+	})
+	t.Run("should return not found for non-member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.repo.Delete(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
+
+func (suite *memberRepositoryTestSuite) TestUpdateRole() {
+	t := suite.T()
+
+	t.Run("should update role", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		err := suite.repo.UpdateRole(suite.ctx, p, USER_TWO, core.ROLE_OWNER)
+
+		role, _ := suite.repo.Role(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(core.ROLE_OWNER, role)
+	})
+	t.Run("should return not found for non-member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.repo.UpdateRole(suite.ctx, p, USER_TWO, core.ROLE_OWNER)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
@@ -6,6 +6,9 @@ import (
 	"time"
 
 	"github.com/ptracker/core"
+	"github.com/ptracker/core/bans"
+	"github.com/ptracker/outbox"
+	"gorm.io/gorm"
 )
 
 type Member struct {
@@ -16,13 +19,28 @@ type Member struct {
 	core.Avatar `json:"avatar"`
 }
 
+type Ban struct {
+	ProjectID   string    `json:"project_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	core.Avatar `json:"avatar"`
+}
+
 type MemberService struct {
+	txManager  *core.TxManager
 	memberRepo *MemberRepository
+	banRepo    *bans.BanRepository
+	outboxRepo *outbox.OutboxRepository
 }
 
-func NewMemberService(memberRepo *MemberRepository) *MemberService {
+func NewMemberService(txManager *core.TxManager,
+	memberRepo *MemberRepository,
+	banRepo *bans.BanRepository,
+	outboxRepo *outbox.OutboxRepository) *MemberService {
 	return &MemberService{
+		txManager:  txManager,
 		memberRepo: memberRepo,
+		banRepo:    banRepo,
+		outboxRepo: outboxRepo,
 	}
 }
 
@@ -53,9 +71,10 @@ func (s *MemberService) AllMembers(ctx context.Context,
 
 	var err error
 
-	err = core.NeedsToBeAMember(ctx, s.memberRepo, projectID, userID)
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_VIEW_PROJECT)
 	if err != nil {
-		return nil, fmt.Errorf("needs to be a member: %w", err)
+		return nil, fmt.Errorf("authorize view project: %w", err)
 	}
 
 	rows, err := s.memberRepo.List(ctx, projectID)
@@ -82,3 +101,192 @@ func (s *MemberService) AllMembers(ctx context.Context,
 
 	return members, nil
 }
+
+// Owner userID removes member memberID from the project and bans the member
+// from requesting to join again
+func (s *MemberService) Remove(ctx context.Context,
+	projectID, userID, memberID string) error {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_MEMBERS)
+	if err != nil {
+		return fmt.Errorf("authorize manage members: %w", err)
+	}
+
+	if userID == memberID {
+		return core.ErrInvalidValue
+	}
+
+	role, err := s.memberRepo.Role(ctx, projectID, memberID)
+	if err != nil {
+		return fmt.Errorf("member repository role: %w", err)
+	}
+
+	if role == core.ROLE_OWNER {
+		return core.ErrForbidden
+	}
+
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		memberRepo := s.memberRepo.WithTx(tx)
+		banRepo := s.banRepo.WithTx(tx)
+
+		err = memberRepo.Delete(ctx, projectID, memberID)
+		if err != nil {
+			return fmt.Errorf("member repository delete: %w", err)
+		}
+
+		err = banRepo.Create(ctx, projectID, memberID)
+		if err != nil {
+			return fmt.Errorf("ban repository create: %w", err)
+		}
+
+		err = s.outboxRepo.WithTx(tx).Create(ctx, outbox.EV_MEMBER_REMOVED,
+			outbox.MemberRemoved{
+				ProjectID: projectID,
+				MemberID:  memberID,
+				RemoverID: userID,
+			})
+		if err != nil {
+			return fmt.Errorf("outbox repository create: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("txManager WithTx: %w", err)
+	}
+
+	return nil
+}
+
+/*
+Owner userID changes the role of member memberID
+
+The owner role can not be given or taken away here, ownership is handed over
+with a transfer.
+*/
+func (s *MemberService) ChangeRole(ctx context.Context,
+	projectID, userID, memberID, role string) error {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_MEMBERS)
+	if err != nil {
+		return fmt.Errorf("authorize manage members: %w", err)
+	}
+
+	if !core.IsValidRole(role) || role == core.ROLE_OWNER {
+		return core.ErrInvalidValue
+	}
+
+	current, err := s.memberRepo.Role(ctx, projectID, memberID)
+	if err != nil {
+		return fmt.Errorf("member repository role: %w", err)
+	}
+
+	if current == core.ROLE_OWNER {
+		return core.ErrForbidden
+	}
+
+	err = s.memberRepo.UpdateRole(ctx, projectID, memberID, role)
+	if err != nil {
+		return fmt.Errorf("member repository update role: %w", err)
+	}
+
+	return nil
+}
+
+// Member userID leaves the project, the owner can not leave the project
+func (s *MemberService) Leave(ctx context.Context,
+	projectID, userID string) error {
+
+	role, err := s.memberRepo.Role(ctx, projectID, userID)
+	if err == core.ErrNotFound {
+		return core.ErrForbidden
+	} else if err != nil {
+		return fmt.Errorf("member repository role: %w", err)
+	}
+
+	if role == core.ROLE_OWNER {
+		return core.ErrInvalidValue
+	}
+
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		err := s.memberRepo.WithTx(tx).Delete(ctx, projectID, userID)
+		if err != nil {
+			return fmt.Errorf("member repository delete: %w", err)
+		}
+
+		err = s.outboxRepo.WithTx(tx).Create(ctx, outbox.EV_MEMBER_LEFT,
+			outbox.MemberLeft{
+				ProjectID: projectID,
+				MemberID:  userID,
+			})
+		if err != nil {
+			return fmt.Errorf("outbox repository create: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("txManager WithTx: %w", err)
+	}
+
+	return nil
+}
+
+func (s *MemberService) Bans(ctx context.Context,
+	projectID, userID string) ([]Ban, error) {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_MEMBERS)
+	if err != nil {
+		return nil, fmt.Errorf("authorize manage members: %w", err)
+	}
+
+	rows, err := s.banRepo.List(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("ban repository list: %w", err)
+	}
+
+	banned := []Ban{}
+	for _, r := range rows {
+		banned = append(banned, Ban{
+			ProjectID: r.ProjectID,
+			CreatedAt: r.CreatedAt,
+			Avatar: core.Avatar{
+				UserID:      r.UserID,
+				Username:    r.Username,
+				Email:       r.Email,
+				DisplayName: r.DisplayName,
+				AvatarURL:   r.AvatarURL,
+			},
+		})
+	}
+
+	return banned, nil
+}
+
+func (s *MemberService) Unban(ctx context.Context,
+	projectID, userID, bannedID string) error {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_MEMBERS)
+	if err != nil {
+		return fmt.Errorf("authorize manage members: %w", err)
+	}
+
+	err = s.banRepo.Delete(ctx, projectID, bannedID)
+	if err != nil {
+		return fmt.Errorf("ban repository delete: %w", err)
+	}
+
+	return nil
+}
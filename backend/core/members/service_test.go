@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/ptracker/core"
+	"github.com/ptracker/core/bans"
+	"github.com/ptracker/outbox"
 	"github.com/ptracker/testdata"
 	"github.com/ptracker/testhelpers"
 	"github.com/ptracker/testhelpers/fixtures"
@@ -37,7 +39,7 @@ func (suite *memberServiceTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
-	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{})
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -47,8 +49,11 @@ func (suite *memberServiceTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
+	txManager := core.NewTxManager(suite.db)
 	memberRepo := NewMemberRepository(suite.db)
-	service := NewMemberService(memberRepo)
+	banRepo := bans.NewBanRepository(suite.db)
+	outboxRepo := outbox.NewOutboxRepository(suite.db)
+	service := NewMemberService(txManager, memberRepo, banRepo, outboxRepo)
 	suite.service = service
 
 	suite.fixtures = fixtures.New(suite.ctx, suite.db)
@@ -144,3 +149,199 @@ func (suite *memberServiceTestSuite) TestAllMembers() {
 		suite.Require().ErrorIs(err, core.ErrForbidden)
 	})
 }
+
+func (suite *memberServiceTestSuite) TestRemove() {
+	t := suite.T()
+
+	t.Run("should remove member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		err := suite.service.Remove(suite.ctx, p, USER_ONE, USER_TWO)
+
+		_, roleErr := suite.service.GetRole(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().ErrorIs(roleErr, core.ErrNotFound)
+	})
+	t.Run("should ban removed member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		suite.service.Remove(suite.ctx, p, USER_ONE, USER_TWO)
+
+		banned, err := suite.service.Bans(suite.ctx, p, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(banned))
+		suite.Require().Equal(USER_TWO, banned[0].UserID)
+	})
+	t.Run("should be forbidden for member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_THREE, core.ROLE_MEMBER))
+
+		err := suite.service.Remove(suite.ctx, p, USER_TWO, USER_THREE)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+	t.Run("should not remove owner", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.service.Remove(suite.ctx, p, USER_ONE, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should not find non-member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.service.Remove(suite.ctx, p, USER_ONE, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
+
+func (suite *memberServiceTestSuite) TestLeave() {
+	t := suite.T()
+
+	t.Run("should leave project", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		err := suite.service.Leave(suite.ctx, p, USER_TWO)
+
+		_, roleErr := suite.service.GetRole(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().ErrorIs(roleErr, core.ErrNotFound)
+	})
+	t.Run("should not ban member who left", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		suite.service.Leave(suite.ctx, p, USER_TWO)
+
+		banned, _ := suite.service.Bans(suite.ctx, p, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().Equal(0, len(banned))
+	})
+	t.Run("should not let owner leave", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.service.Leave(suite.ctx, p, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should be forbidden for non-member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.service.Leave(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+}
+
+func (suite *memberServiceTestSuite) TestUnban() {
+	t := suite.T()
+
+	t.Run("should lift ban", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		suite.service.Remove(suite.ctx, p, USER_ONE, USER_TWO)
+
+		err := suite.service.Unban(suite.ctx, p, USER_ONE, USER_TWO)
+
+		banned, _ := suite.service.Bans(suite.ctx, p, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(0, len(banned))
+	})
+	t.Run("should be forbidden for member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		err := suite.service.Unban(suite.ctx, p, USER_TWO, USER_THREE)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+}
+
+func (suite *memberServiceTestSuite) TestChangeRole() {
+	t := suite.T()
+
+	t.Run("should change member to maintainer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		err := suite.service.ChangeRole(suite.ctx, p, USER_ONE, USER_TWO, core.ROLE_MAINTAINER)
+
+		role, _ := suite.service.GetRole(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(core.ROLE_MAINTAINER, role)
+	})
+	t.Run("should not give owner role", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		err := suite.service.ChangeRole(suite.ctx, p, USER_ONE, USER_TWO, core.ROLE_OWNER)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should fail with unknown role", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		err := suite.service.ChangeRole(suite.ctx, p, USER_ONE, USER_TWO, "Admin")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should not change role of owner", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.service.ChangeRole(suite.ctx, p, USER_ONE, USER_ONE, core.ROLE_VIEWER)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+	t.Run("should be forbidden for maintainer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MAINTAINER))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_THREE, core.ROLE_MEMBER))
+
+		err := suite.service.ChangeRole(suite.ctx, p, USER_TWO, USER_THREE, core.ROLE_VIEWER)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+}
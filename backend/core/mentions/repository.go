@@ -0,0 +1,65 @@
+package mentions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ptracker/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MentionRepository struct {
+	db *gorm.DB
+}
+
+func NewMentionRepository(db *gorm.DB) *MentionRepository {
+	return &MentionRepository{
+		db: db,
+	}
+}
+
+func (r *MentionRepository) WithTx(tx *gorm.DB) *MentionRepository {
+	return NewMentionRepository(tx)
+}
+
+/*
+Stores the mention of user userID in the source sourceID
+
+Returns false when the user was already mentioned in the source.
+*/
+func (r *MentionRepository) Create(ctx context.Context,
+	projectID, taskID, sourceID, userID string) (bool, error) {
+
+	mention := models.Mention{
+		SourceID:  sourceID,
+		UserID:    userID,
+		ProjectID: projectID,
+		TaskID:    taskID,
+	}
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&mention)
+	if result.Error != nil {
+		return false, fmt.Errorf("gorm db create: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// Lists the ids of the users mentioned in the source sourceID
+func (r *MentionRepository) List(ctx context.Context,
+	sourceID string) ([]string, error) {
+
+	userIDs := []string{}
+	err := r.db.WithContext(ctx).
+		Model(&models.Mention{}).
+		Where("source_id = ?", sourceID).
+		Order("created_at ASC").
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("gorm db pluck: %w", err)
+	}
+
+	return userIDs, nil
+}
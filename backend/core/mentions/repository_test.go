@@ -0,0 +1,98 @@
+package mentions
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/ptracker/testhelpers/fixtures"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var USER_ONE, USER_TWO string
+
+type mentionRepositoryTestSuite struct {
+	suite.Suite
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	fixtures    *fixtures.Fixtures
+	repo        *MentionRepository
+	ctx         context.Context
+}
+
+func (suite *mentionRepositoryTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx = context.Background()
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.repo = NewMentionRepository(suite.db)
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.fixtures = fixtures.New(suite.ctx, suite.db)
+
+	USER_ONE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_TWO = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+}
+
+func (suite *mentionRepositoryTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM projects").Error
+	suite.Require().NoError(err)
+}
+
+func TestMentionRepository(t *testing.T) {
+	suite.Run(t, new(mentionRepositoryTestSuite))
+}
+
+func (suite *mentionRepositoryTestSuite) TestCreate() {
+	t := suite.T()
+
+	t.Run("should create mention once per source", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		first, err1 := suite.repo.Create(suite.ctx, p, task, task, USER_TWO)
+		second, err2 := suite.repo.Create(suite.ctx, p, task, task, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err1)
+		suite.Require().NoError(err2)
+		suite.Require().True(first)
+		suite.Require().False(second)
+	})
+
+	t.Run("should list mentioned users of the source", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		other := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.repo.Create(suite.ctx, p, task, task, USER_ONE)
+		suite.repo.Create(suite.ctx, p, other, other, USER_TWO)
+
+		userIDs, err := suite.repo.List(suite.ctx, task)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal([]string{USER_ONE}, userIDs)
+	})
+}
@@ -0,0 +1,109 @@
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+/*
+Requested page of a list
+
+Cursor is the opaque value returned with the previous page, empty for the
+first page. Limit is the maximum number of items in the page.
+*/
+type PageQuery struct {
+	Cursor string
+	Limit  int
+}
+
+/*
+Keyset pagination cursor
+
+It holds the sort value and the id of the last row of a page, the next
+page starts strictly after (Value, ID) in the list order. Clients only
+see the encoded form, so the payload can change without breaking them.
+*/
+type Cursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func NewTimeCursor(t time.Time, id string) Cursor {
+	return Cursor{
+		Value: t.Format(time.RFC3339Nano),
+		ID:    id,
+	}
+}
+
+func NewIntCursor(v int, id string) Cursor {
+	return Cursor{
+		Value: strconv.Itoa(v),
+		ID:    id,
+	}
+}
+
+// Returns the opaque string form of the cursor
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Returns the sort value as time for the cursors created with NewTimeCursor
+// Returns ErrInvalidValue if the value is not a timestamp
+func (c Cursor) Time() (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, c.Value)
+	if err != nil {
+		return t, ErrInvalidValue
+	}
+
+	return t, nil
+}
+
+// Returns the sort value as integer for the cursors created with NewIntCursor
+// Returns ErrInvalidValue if the value is not an integer
+func (c Cursor) Int() (int, error) {
+	v, err := strconv.Atoi(c.Value)
+	if err != nil {
+		return v, ErrInvalidValue
+	}
+
+	return v, nil
+}
+
+// Decodes the opaque cursor, returns nil for empty cursor
+// Returns ErrInvalidValue if the cursor is malformed
+func DecodeCursor(cursor string) (*Cursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidValue
+	}
+
+	var c Cursor
+	if err = json.Unmarshal(b, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidValue
+	}
+
+	return &c, nil
+}
+
+/*
+Builds a page from rows queried with limit+1
+
+The extra row only tells that there is a next page, it is trimmed and the
+next cursor is built from the last row that is kept. The next cursor is
+empty on the last page.
+*/
+func Paginate[T any](rows []T, limit int, cursorOf func(T) Cursor) ([]T, string) {
+	if len(rows) <= limit {
+		return rows, ""
+	}
+
+	rows = rows[:limit]
+	return rows, cursorOf(rows[len(rows)-1]).Encode()
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorEncodeDecode(t *testing.T) {
+	cursor := Cursor{Value: "value", ID: "id"}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, *decoded)
+}
+
+func TestDecodeCursor_Empty(t *testing.T) {
+	decoded, err := DecodeCursor("")
+
+	assert.NoError(t, err)
+	assert.Nil(t, decoded)
+}
+
+func TestDecodeCursor_Malformed(t *testing.T) {
+	_, err := DecodeCursor("not a cursor")
+
+	assert.ErrorIs(t, err, ErrInvalidValue)
+}
+
+func TestDecodeCursor_MissingID(t *testing.T) {
+	_, err := DecodeCursor(Cursor{Value: "value"}.Encode())
+
+	assert.ErrorIs(t, err, ErrInvalidValue)
+}
+
+func TestTimeCursor(t *testing.T) {
+	now := time.Now()
+
+	decoded, _ := DecodeCursor(NewTimeCursor(now, "id").Encode())
+	value, err := decoded.Time()
+
+	assert.NoError(t, err)
+	assert.True(t, now.Equal(value))
+}
+
+func TestCursorTime_Invalid(t *testing.T) {
+	_, err := Cursor{Value: "title", ID: "id"}.Time()
+
+	assert.ErrorIs(t, err, ErrInvalidValue)
+}
+
+func TestIntCursor(t *testing.T) {
+	decoded, _ := DecodeCursor(NewIntCursor(3, "id").Encode())
+	value, err := decoded.Int()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, value)
+}
+
+func TestCursorInt_Invalid(t *testing.T) {
+	_, err := Cursor{Value: "title", ID: "id"}.Int()
+
+	assert.ErrorIs(t, err, ErrInvalidValue)
+}
+
+func TestPaginate_LastPage(t *testing.T) {
+	rows, next := Paginate([]string{"a", "b"}, 2, func(s string) Cursor {
+		return Cursor{Value: s, ID: s}
+	})
+
+	assert.Equal(t, []string{"a", "b"}, rows)
+	assert.Empty(t, next)
+}
+
+func TestPaginate_HasNext(t *testing.T) {
+	rows, next := Paginate([]string{"a", "b", "c"}, 2, func(s string) Cursor {
+		return Cursor{Value: s, ID: s}
+	})
+
+	decoded, _ := DecodeCursor(next)
+
+	assert.Equal(t, []string{"a", "b"}, rows)
+	assert.Equal(t, "b", decoded.ID)
+}
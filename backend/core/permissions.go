@@ -0,0 +1,75 @@
+package core
+
+import "slices"
+
+// Action a member can perform in a project
+type Permission string
+
+const (
+	PERMISSION_VIEW_PROJECT         Permission = "view_project"
+	PERMISSION_EDIT_PROJECT         Permission = "edit_project"
+	PERMISSION_DELETE_PROJECT       Permission = "delete_project"
+	PERMISSION_TRANSFER_OWNERSHIP   Permission = "transfer_ownership"
+	PERMISSION_MANAGE_MEMBERS       Permission = "manage_members"
+	PERMISSION_MANAGE_LABELS        Permission = "manage_labels"
+	PERMISSION_MANAGE_WORKFLOW      Permission = "manage_workflow"
+	PERMISSION_MANAGE_JOIN_REQUESTS Permission = "manage_join_requests"
+	PERMISSION_MANAGE_TASKS         Permission = "manage_tasks"
+	PERMISSION_MANAGE_ASSIGNEES     Permission = "manage_assignees"
+	PERMISSION_EDIT_ASSIGNED_TASKS  Permission = "edit_assigned_tasks"
+	PERMISSION_COMMENT              Permission = "comment"
+	PERMISSION_MODERATE_COMMENTS    Permission = "moderate_comments"
+)
+
+/*
+Permission matrix of the project roles
+
+Owner: everything
+Maintainer: manages tasks, assignees and join requests
+Member: works on the tasks assigned to them and comments
+Viewer: read-only
+*/
+var rolePermissions = map[string][]Permission{
+	ROLE_OWNER: {
+		PERMISSION_VIEW_PROJECT,
+		PERMISSION_EDIT_PROJECT,
+		PERMISSION_DELETE_PROJECT,
+		PERMISSION_TRANSFER_OWNERSHIP,
+		PERMISSION_MANAGE_MEMBERS,
+		PERMISSION_MANAGE_LABELS,
+		PERMISSION_MANAGE_WORKFLOW,
+		PERMISSION_MANAGE_JOIN_REQUESTS,
+		PERMISSION_MANAGE_TASKS,
+		PERMISSION_MANAGE_ASSIGNEES,
+		PERMISSION_EDIT_ASSIGNED_TASKS,
+		PERMISSION_COMMENT,
+		PERMISSION_MODERATE_COMMENTS,
+	},
+	ROLE_MAINTAINER: {
+		PERMISSION_VIEW_PROJECT,
+		PERMISSION_MANAGE_JOIN_REQUESTS,
+		PERMISSION_MANAGE_TASKS,
+		PERMISSION_MANAGE_ASSIGNEES,
+		PERMISSION_EDIT_ASSIGNED_TASKS,
+		PERMISSION_COMMENT,
+	},
+	ROLE_MEMBER: {
+		PERMISSION_VIEW_PROJECT,
+		PERMISSION_EDIT_ASSIGNED_TASKS,
+		PERMISSION_COMMENT,
+	},
+	ROLE_VIEWER: {
+		PERMISSION_VIEW_PROJECT,
+	},
+}
+
+// Returns true if role is one of the project roles
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Returns true if role grants the permission
+func HasPermission(role string, permission Permission) bool {
+	return slices.Contains(rolePermissions[role], permission)
+}
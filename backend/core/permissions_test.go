@@ -0,0 +1,76 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type roleCheckerStub map[string]string
+
+func (c roleCheckerStub) Role(ctx context.Context,
+	projectID string, userID string) (string, error) {
+
+	role, ok := c[userID]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return role, nil
+}
+
+func TestIsValidRole(t *testing.T) {
+	assert.True(t, IsValidRole(ROLE_OWNER))
+	assert.True(t, IsValidRole(ROLE_MAINTAINER))
+	assert.True(t, IsValidRole(ROLE_MEMBER))
+	assert.True(t, IsValidRole(ROLE_VIEWER))
+	assert.False(t, IsValidRole("Admin"))
+}
+
+func TestHasPermission_Maintainer(t *testing.T) {
+	assert.True(t, HasPermission(ROLE_MAINTAINER, PERMISSION_MANAGE_TASKS))
+	assert.True(t, HasPermission(ROLE_MAINTAINER, PERMISSION_MANAGE_ASSIGNEES))
+	assert.True(t, HasPermission(ROLE_MAINTAINER, PERMISSION_MANAGE_JOIN_REQUESTS))
+	assert.False(t, HasPermission(ROLE_MAINTAINER, PERMISSION_MANAGE_MEMBERS))
+	assert.False(t, HasPermission(ROLE_MAINTAINER, PERMISSION_DELETE_PROJECT))
+	assert.False(t, HasPermission(ROLE_MAINTAINER, PERMISSION_MANAGE_WORKFLOW))
+	assert.False(t, HasPermission(ROLE_MAINTAINER, PERMISSION_MODERATE_COMMENTS))
+}
+
+func TestHasPermission_Viewer(t *testing.T) {
+	assert.True(t, HasPermission(ROLE_VIEWER, PERMISSION_VIEW_PROJECT))
+	assert.False(t, HasPermission(ROLE_VIEWER, PERMISSION_COMMENT))
+	assert.False(t, HasPermission(ROLE_VIEWER, PERMISSION_EDIT_ASSIGNED_TASKS))
+}
+
+func TestHasPermission_UnknownRole(t *testing.T) {
+	assert.False(t, HasPermission("", PERMISSION_VIEW_PROJECT))
+}
+
+func TestAuthorize(t *testing.T) {
+	checker := roleCheckerStub{"maintainer": ROLE_MAINTAINER}
+
+	err := Authorize(context.Background(), checker, "project", "maintainer",
+		PERMISSION_MANAGE_TASKS)
+
+	assert.NoError(t, err)
+}
+
+func TestAuthorize_MissingPermission(t *testing.T) {
+	checker := roleCheckerStub{"viewer": ROLE_VIEWER}
+
+	err := Authorize(context.Background(), checker, "project", "viewer",
+		PERMISSION_COMMENT)
+
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestAuthorize_NonMember(t *testing.T) {
+	checker := roleCheckerStub{}
+
+	err := Authorize(context.Background(), checker, "project", "user",
+		PERMISSION_VIEW_PROJECT)
+
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -12,17 +12,24 @@ import (
 )
 
 type ProjectSummaryRow struct {
-	ID              string    `gorm:"column:id"`
-	Name            string    `gorm:"column:name"`
-	Description     *string   `gorm:"column:description"`
-	Skills          *string   `gorm:"column:skills"`
-	OwnerID         string    `gorm:"column:owner_id"`
-	UnassignedTasks int64     `gorm:"column:unassigned_tasks"`
-	OngoingTasks    int64     `gorm:"column:ongoing_tasks"`
-	CompletedTasks  int64     `gorm:"column:completed_tasks"`
-	AbandonedTasks  int64     `gorm:"column:abandoned_tasks"`
-	CreatedAt       time.Time `gorm:"column:created_at"`
-	UpdatedAt       time.Time `gorm:"column:updated_at"`
+	ID              string  `gorm:"column:id"`
+	Name            string  `gorm:"column:name"`
+	Description     *string `gorm:"column:description"`
+	Skills          *string `gorm:"column:skills"`
+	OwnerID         string  `gorm:"column:owner_id"`
+	UnassignedTasks int64   `gorm:"column:unassigned_tasks"`
+	OngoingTasks    int64   `gorm:"column:ongoing_tasks"`
+	CompletedTasks  int64   `gorm:"column:completed_tasks"`
+	AbandonedTasks  int64   `gorm:"column:abandoned_tasks"`
+
+	UnassignedEstimate int64 `gorm:"column:unassigned_estimate"`
+	OngoingEstimate    int64 `gorm:"column:ongoing_estimate"`
+	CompletedEstimate  int64 `gorm:"column:completed_estimate"`
+	AbandonedEstimate  int64 `gorm:"column:abandoned_estimate"`
+
+	CreatedAt time.Time `gorm:"column:created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at"`
+	Version   int       `gorm:"column:version"`
 }
 
 type ProjectPreviewRow struct {
@@ -80,7 +87,9 @@ func (r *ProjectRepository) Get(ctx context.Context, id string) (ProjectSummaryR
 		Select(`p.id, p.name, p.description, p.skills, p.owner_id, 
 				ps.unassigned_tasks, ps.ongoing_tasks, 
 				ps.completed_tasks, ps.abandoned_tasks,
-				p.created_at, p.updated_at
+				ps.unassigned_estimate, ps.ongoing_estimate, 
+				ps.completed_estimate, ps.abandoned_estimate,
+				p.created_at, p.updated_at, p.version
 			`).
 		Joins("LEFT JOIN project_summary ps ON ps.id=p.id").
 		Where("p.id = ?", id).
@@ -95,32 +104,147 @@ func (r *ProjectRepository) Get(ctx context.Context, id string) (ProjectSummaryR
 	return row, nil
 }
 
+/*
+Updates the given fields of the project and bumps its version, when a
+version is given only if the project is still at it
+
+Returns ErrPreconditionFailed when the project was updated past the version.
+*/
+func (r *ProjectRepository) Update(ctx context.Context, id string,
+	name, description, skills *string,
+	version *int) error {
+
+	columns := map[string]any{
+		"version": gorm.Expr("version + 1"),
+	}
+
+	if name != nil {
+		columns["name"] = *name
+	}
+
+	if description != nil {
+		columns["description"] = *description
+	}
+
+	if skills != nil {
+		columns["skills"] = *skills
+	}
+
+	query := r.db.WithContext(ctx).
+		Model(&models.Project{}).
+		Where("id = ?", id)
+	if version != nil {
+		query = query.Where("version = ?", *version)
+	}
+
+	result := query.Updates(columns)
+	if result.Error != nil {
+		return fmt.Errorf("gorm db updates: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	_, err := gorm.G[models.Project](r.db).Where("id = ?", id).First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return core.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("gorm query project: %w", err)
+	}
+
+	return core.ErrPreconditionFailed
+}
+
+func (r *ProjectRepository) UpdateOwner(ctx context.Context,
+	id, ownerID string) error {
+
+	result := r.db.WithContext(ctx).
+		Model(&models.Project{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"owner_id": ownerID,
+			"version":  gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("gorm db updates: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return core.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *ProjectRepository) Delete(ctx context.Context, id string) error {
+
+	// tasks, members, join requests, assignees and comments are removed by
+	// the ON DELETE CASCADE constraints
+	rows, err := gorm.G[models.Project](r.db).Where("id = ?", id).Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("gorm delete: %w", err)
+	}
+	if rows == 0 {
+		return core.ErrNotFound
+	}
+
+	return nil
+}
+
 func (r *ProjectRepository) List(ctx context.Context,
-	userID string) ([]ProjectSummaryRow, error) {
+	userID string,
+	page core.PageQuery) ([]ProjectSummaryRow, string, error) {
 
-	var rows = []ProjectSummaryRow{}
-	err := r.db.WithContext(ctx).
+	cursor, err := core.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := r.db.WithContext(ctx).
 		Table("projects p").
 		Select(`p.id, p.name, p.description, p.skills, p.owner_id, 
 				ps.unassigned_tasks, ps.ongoing_tasks, ps.completed_tasks, ps.abandoned_tasks, 
+				ps.unassigned_estimate, ps.ongoing_estimate, ps.completed_estimate, ps.abandoned_estimate, 
 				p.created_at, p.updated_at`).
 		Joins("INNER JOIN members as m ON m.project_id=p.id").
 		Joins("LEFT JOIN project_summary as ps ON ps.id=p.id").
-		Where("m.user_id = ?", userID).
+		Where("m.user_id = ?", userID)
+
+	if cursor != nil {
+		createdAt, err := cursor.Time()
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(p.created_at, p.id) < (?, ?)", createdAt, cursor.ID)
+	}
+
+	var rows = []ProjectSummaryRow{}
+	err = query.
+		Order("p.created_at DESC, p.id DESC").
+		Limit(page.Limit + 1).
 		Scan(&rows).
 		Error
 	if err != nil {
-		return nil, fmt.Errorf("gorm db scan: %w", err)
+		return nil, "", fmt.Errorf("gorm db scan: %w", err)
 	}
 
-	return rows, nil
+	rows, nextCursor := core.Paginate(rows, page.Limit,
+		func(row ProjectSummaryRow) core.Cursor {
+			return core.NewTimeCursor(row.CreatedAt, row.ID)
+		})
+
+	return rows, nextCursor, nil
 }
 
 func (r *ProjectRepository) Public(ctx context.Context,
-	userID string) ([]ProjectPreviewRow, error) {
+	userID string,
+	page core.PageQuery) ([]ProjectPreviewRow, string, error) {
 
-	var rows = []ProjectPreviewRow{}
-	err := r.db.WithContext(ctx).
+	cursor, err := core.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := r.db.WithContext(ctx).
 		Table("projects").
 		Select(`id, name, description, skills, owner_id, 
 			created_at, updated_at`).
@@ -130,13 +254,31 @@ func (r *ProjectRepository) Public(ctx context.Context,
 				Table("members").
 				Select("1").
 				Where("project_id = projects.id AND user_id = ?", userID),
-		).
+		)
+
+	if cursor != nil {
+		createdAt, err := cursor.Time()
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(created_at, id) < (?, ?)", createdAt, cursor.ID)
+	}
+
+	var rows = []ProjectPreviewRow{}
+	err = query.
+		Order("created_at DESC, id DESC").
+		Limit(page.Limit + 1).
 		Scan(&rows).Error
 	if err != nil {
-		return nil, fmt.Errorf("gorm db scan: %w", err)
+		return nil, "", fmt.Errorf("gorm db scan: %w", err)
 	}
 
-	return rows, nil
+	rows, nextCursor := core.Paginate(rows, page.Limit,
+		func(row ProjectPreviewRow) core.Cursor {
+			return core.NewTimeCursor(row.CreatedAt, row.ID)
+		})
+
+	return rows, nextCursor, nil
 }
 
 func (r *ProjectRepository) RecentlyCreated(ctx context.Context,
@@ -147,6 +289,7 @@ func (r *ProjectRepository) RecentlyCreated(ctx context.Context,
 		Table("projects p").
 		Select(`p.id, p.name, p.description, p.skills, p.owner_id, 
 				ps.unassigned_tasks, ps.ongoing_tasks, ps.completed_tasks, ps.abandoned_tasks, 
+				ps.unassigned_estimate, ps.ongoing_estimate, ps.completed_estimate, ps.abandoned_estimate, 
 				p.created_at, p.updated_at`).
 		Joins("LEFT JOIN project_summary as ps ON ps.id=p.id").
 		Where("p.owner_id = ?", userID).
@@ -170,10 +313,11 @@ func (r *ProjectRepository) RecentlyJoined(ctx context.Context,
 		Table("projects p").
 		Select(`p.id, p.name, p.description, p.skills, p.owner_id,
 				ps.unassigned_tasks, ps.ongoing_tasks, ps.completed_tasks, ps.abandoned_tasks,
+				ps.unassigned_estimate, ps.ongoing_estimate, ps.completed_estimate, ps.abandoned_estimate,
 				p.created_at, p.updated_at`).
 		Joins("INNER JOIN members as m ON m.project_id=p.id").
 		Joins("LEFT JOIN project_summary as ps ON ps.id=p.id").
-		Where("m.user_id = ? AND m.role = ?", userID, core.ROLE_MEMBER).
+		Where("m.user_id = ? AND m.role != ?", userID, core.ROLE_OWNER).
 		Order("m.created_at DESC").
 		Limit(n).
 		Scan(&rows).
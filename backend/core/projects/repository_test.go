@@ -36,7 +36,7 @@ func (suite *projectRepositoryTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
-	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{})
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -166,6 +166,25 @@ func (suite *projectRepositoryTestSuite) TestProjectGet() {
 		suite.Require().EqualValues(1, project.OngoingTasks)
 		suite.Require().EqualValues(2, project.CompletedTasks)
 	})
+	t.Run("should get project summary with estimates summed per status", func(t *testing.T) {
+		projectID := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		for _, estimate := range []int{2, 3} {
+			task := fixtures.RandomTaskRow(projectID, core.TASK_STATUS_ONGOING)
+			task.Estimate = estimate
+			suite.fixtures.InsertTask(task)
+		}
+		completed := fixtures.RandomTaskRow(projectID, core.TASK_STATUS_COMPLETED)
+		completed.Estimate = 8
+		suite.fixtures.InsertTask(completed)
+
+		project, _ := suite.repo.Get(suite.ctx, projectID)
+
+		suite.Cleanup()
+
+		suite.Require().EqualValues(5, project.OngoingEstimate)
+		suite.Require().EqualValues(8, project.CompletedEstimate)
+		suite.Require().EqualValues(0, project.UnassignedEstimate)
+	})
 	t.Run("should not get any project with invalid id", func(t *testing.T) {
 		projectID := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 
@@ -181,7 +200,7 @@ func (suite *projectRepositoryTestSuite) TestProjectList() {
 	t := suite.T()
 
 	t.Run("should return empty list", func(t *testing.T) {
-		projects, err := suite.repo.List(suite.ctx, USER_ONE)
+		projects, _, err := suite.repo.List(suite.ctx, USER_ONE, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -193,20 +212,41 @@ func (suite *projectRepositoryTestSuite) TestProjectList() {
 		suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 		suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 
-		projects, err := suite.repo.List(suite.ctx, USER_ONE)
+		projects, _, err := suite.repo.List(suite.ctx, USER_ONE, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 
 		suite.Require().NoError(err)
 		suite.Require().Equal(2, len(projects))
 	})
+	t.Run("should page through projects with cursor", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		first, cursor, _ := suite.repo.List(suite.ctx, USER_ONE, core.PageQuery{Limit: 1})
+		second, last, err := suite.repo.List(suite.ctx, USER_ONE, core.PageQuery{Limit: 1, Cursor: cursor})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().NotEmpty(cursor)
+		suite.Require().Empty(last)
+		suite.Require().Equal([]string{p2, p1}, []string{first[0].ID, second[0].ID})
+	})
+	t.Run("should be invalid with malformed cursor", func(t *testing.T) {
+		_, _, err := suite.repo.List(suite.ctx, USER_ONE, core.PageQuery{Limit: 1, Cursor: "???"})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
 }
 
 func (suite *projectRepositoryTestSuite) TestProjectPublic() {
 	t := suite.T()
 
 	t.Run("should get empty list of public projects", func(t *testing.T) {
-		projects, err := suite.repo.Public(suite.ctx, USER_ONE)
+		projects, _, err := suite.repo.Public(suite.ctx, USER_ONE, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -218,7 +258,7 @@ func (suite *projectRepositoryTestSuite) TestProjectPublic() {
 		suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 		suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 
-		projects, err := suite.repo.Public(suite.ctx, USER_TWO)
+		projects, _, err := suite.repo.Public(suite.ctx, USER_TWO, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -230,13 +270,25 @@ func (suite *projectRepositoryTestSuite) TestProjectPublic() {
 		// should not be included in the result
 		suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_TWO))
 
-		projects, err := suite.repo.Public(suite.ctx, USER_TWO)
+		projects, _, err := suite.repo.Public(suite.ctx, USER_TWO, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 
 		suite.Require().NoError(err)
 		suite.Require().Equal(1, len(projects))
 	})
+	t.Run("should return next cursor when more public projects exist", func(t *testing.T) {
+		suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		projects, cursor, err := suite.repo.Public(suite.ctx, USER_TWO, core.PageQuery{Limit: 1})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(projects))
+		suite.Require().NotEmpty(cursor)
+	})
 }
 
 func (suite *projectRepositoryTestSuite) TestProjectRecentlyCreated() {
@@ -303,3 +355,107 @@ func (suite *projectRepositoryTestSuite) TestProjectRecentlyJoined() {
 		suite.Require().Equal(2, len(projects))
 	})
 }
+
+func (suite *projectRepositoryTestSuite) TestProjectDelete() {
+	t := suite.T()
+
+	t.Run("should delete project with its tasks and members", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		err := suite.repo.Delete(suite.ctx, p)
+
+		var tasks, members int64
+		suite.db.Model(&models.Task{}).Where("project_id = ?", p).Count(&tasks)
+		suite.db.Model(&models.Member{}).Where("project_id = ?", p).Count(&members)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().EqualValues(0, tasks)
+		suite.Require().EqualValues(0, members)
+	})
+	t.Run("should return not found for unknown project", func(t *testing.T) {
+		err := suite.repo.Delete(suite.ctx, "unknown")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
+
+func (suite *projectRepositoryTestSuite) TestProjectUpdate() {
+	t := suite.T()
+
+	t.Run("should update only given fields", func(t *testing.T) {
+		row := fixtures.RandomProjectRow(USER_ONE)
+		p := suite.fixtures.InsertProject(row)
+		name := "Project Renamed"
+
+		err := suite.repo.Update(suite.ctx, p, &name, nil, nil, nil)
+
+		project, _ := suite.repo.Get(suite.ctx, p)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(name, project.Name)
+		suite.Require().Equal(row.Description, project.Description)
+		suite.Require().Equal(row.Skills, project.Skills)
+	})
+	t.Run("should update description and skills", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		description, skills := "New description", "Go, Postgres"
+
+		err := suite.repo.Update(suite.ctx, p, nil, &description, &skills, nil)
+
+		project, _ := suite.repo.Get(suite.ctx, p)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(description, *project.Description)
+		suite.Require().Equal(skills, *project.Skills)
+	})
+	t.Run("should return not found for unknown project", func(t *testing.T) {
+		name := "Project Renamed"
+
+		err := suite.repo.Update(suite.ctx, "unknown", &name, nil, nil, nil)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+	t.Run("should bump version on update", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		name := "Project Renamed"
+		version := 1
+
+		err := suite.repo.Update(suite.ctx, p, &name, nil, nil, &version)
+
+		project, _ := suite.repo.Get(suite.ctx, p)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, project.Version)
+	})
+	t.Run("should return precondition failed for stale version", func(t *testing.T) {
+		row := fixtures.RandomProjectRow(USER_ONE)
+		p := suite.fixtures.InsertProject(row)
+		name := "Project Renamed"
+		version := 1
+		suite.repo.Update(suite.ctx, p, &name, nil, nil, &version)
+
+		other := "Project Renamed Again"
+		err := suite.repo.Update(suite.ctx, p, &other, nil, nil, &version)
+
+		project, _ := suite.repo.Get(suite.ctx, p)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrPreconditionFailed)
+		suite.Require().Equal(name, project.Name)
+	})
+}
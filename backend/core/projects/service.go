@@ -8,6 +8,7 @@ import (
 
 	"github.com/ptracker/core"
 	"github.com/ptracker/core/members"
+	"github.com/ptracker/outbox"
 	"gorm.io/gorm"
 )
 
@@ -27,21 +28,44 @@ type ProjectSummary struct {
 	OngoingTasks    int64 `json:"ongoing_tasks"`
 	CompletedTasks  int64 `json:"completed_tasks"`
 	AbandonedTasks  int64 `json:"abandoned_tasks"`
+
+	// sum of the task estimates per status
+	UnassignedEstimate int64 `json:"unassigned_estimate"`
+	OngoingEstimate    int64 `json:"ongoing_estimate"`
+	CompletedEstimate  int64 `json:"completed_estimate"`
+	AbandonedEstimate  int64 `json:"abandoned_estimate"`
+
+	Version int `json:"version"`
+}
+
+/*
+Snapshot of a project taken right before it was deleted
+
+Used to inform the members, the project and their memberships no longer
+exist when the notifications are sent.
+*/
+type DeletedProject struct {
+	ID        string
+	Name      string
+	MemberIDs []string
 }
 
 type ProjectService struct {
 	txManager   *core.TxManager
 	projectRepo *ProjectRepository
 	memberRepo  *members.MemberRepository
+	outboxRepo  *outbox.OutboxRepository
 }
 
 func NewProjectService(txManager *core.TxManager,
 	projectRepo *ProjectRepository,
-	memberRepo *members.MemberRepository) *ProjectService {
+	memberRepo *members.MemberRepository,
+	outboxRepo *outbox.OutboxRepository) *ProjectService {
 	return &ProjectService{
 		txManager:   txManager,
 		projectRepo: projectRepo,
 		memberRepo:  memberRepo,
+		outboxRepo:  outboxRepo,
 	}
 }
 
@@ -103,16 +127,210 @@ func (s *ProjectService) Get(ctx context.Context,
 		OngoingTasks:    project.OngoingTasks,
 		CompletedTasks:  project.CompletedTasks,
 		AbandonedTasks:  project.AbandonedTasks,
+
+		UnassignedEstimate: project.UnassignedEstimate,
+		OngoingEstimate:    project.OngoingEstimate,
+		CompletedEstimate:  project.CompletedEstimate,
+		AbandonedEstimate:  project.AbandonedEstimate,
+
+		Version: project.Version,
 	}
 	return &myProject, nil
 }
 
+// Updates the project and returns its new version, version is the one it is
+// expected to be at, nil skips the check
+func (s *ProjectService) Update(ctx context.Context,
+	projectID, userID string,
+	name, description, skills *string,
+	version *int) (int, error) {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_EDIT_PROJECT)
+	if err != nil {
+		return 0, fmt.Errorf("authorize edit project: %w", err)
+	}
+
+	if name == nil && description == nil && skills == nil {
+		return 0, core.ErrInvalidValue
+	}
+
+	if name != nil && strings.Trim(*name, " ") == "" {
+		return 0, core.ErrInvalidValue
+	}
+
+	var newVersion int
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		projectRepo := s.projectRepo.WithTx(tx)
+
+		err := projectRepo.Update(ctx, projectID, name, description, skills, version)
+		if err != nil {
+			return fmt.Errorf("project repository update: %w", err)
+		}
+
+		// read in the transaction, for the version to be the one just written
+		project, err := projectRepo.Get(ctx, projectID)
+		if err != nil {
+			return fmt.Errorf("project repository get: %w", err)
+		}
+		newVersion = project.Version
+
+		err = s.outboxRepo.WithTx(tx).Create(ctx, outbox.EV_PROJECT_UPDATED,
+			outbox.ProjectUpdated{
+				ProjectID:   projectID,
+				Name:        name,
+				Description: description,
+				Skills:      skills,
+				UpdaterID:   userID,
+			})
+		if err != nil {
+			return fmt.Errorf("outbox repository create: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("txManager WithTx: %w", err)
+	}
+
+	return newVersion, nil
+}
+
+/*
+Hands over the project from the owner userID to the member newOwnerID
+
+The project owner, the role of the new owner and the role of the old owner
+are updated in one transaction, the old owner stays as a member.
+*/
+func (s *ProjectService) TransferOwnership(ctx context.Context,
+	projectID, userID, newOwnerID string) error {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_TRANSFER_OWNERSHIP)
+	if err != nil {
+		return fmt.Errorf("authorize transfer ownership: %w", err)
+	}
+
+	if userID == newOwnerID {
+		return core.ErrInvalidValue
+	}
+
+	_, err = s.memberRepo.Role(ctx, projectID, newOwnerID)
+	if err != nil {
+		return fmt.Errorf("member repository role: %w", err)
+	}
+
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		projectRepo := s.projectRepo.WithTx(tx)
+		memberRepo := s.memberRepo.WithTx(tx)
+
+		err = projectRepo.UpdateOwner(ctx, projectID, newOwnerID)
+		if err != nil {
+			return fmt.Errorf("project repository update owner: %w", err)
+		}
+
+		err = memberRepo.UpdateRole(ctx, projectID, newOwnerID, core.ROLE_OWNER)
+		if err != nil {
+			return fmt.Errorf("member repository update role of new owner: %w", err)
+		}
+
+		err = memberRepo.UpdateRole(ctx, projectID, userID, core.ROLE_MEMBER)
+		if err != nil {
+			return fmt.Errorf("member repository update role of old owner: %w", err)
+		}
+
+		err = s.outboxRepo.WithTx(tx).Create(ctx, outbox.EV_OWNERSHIP_TRANSFERRED,
+			outbox.OwnershipTransferred{
+				ProjectID:       projectID,
+				PreviousOwnerID: userID,
+				NewOwnerID:      newOwnerID,
+			})
+		if err != nil {
+			return fmt.Errorf("outbox repository create: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("txManager WithTx: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ProjectService) Delete(ctx context.Context,
+	projectID, userID string) (*DeletedProject, error) {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_DELETE_PROJECT)
+	if err != nil {
+		return nil, fmt.Errorf("authorize delete project: %w", err)
+	}
+
+	deleted := DeletedProject{
+		ID:        projectID,
+		MemberIDs: []string{},
+	}
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		projectRepo := s.projectRepo.WithTx(tx)
+		memberRepo := s.memberRepo.WithTx(tx)
+
+		project, err := projectRepo.Get(ctx, projectID)
+		if err != nil {
+			return fmt.Errorf("project repository get: %w", err)
+		}
+		deleted.Name = project.Name
+
+		members, err := memberRepo.List(ctx, projectID)
+		if err != nil {
+			return fmt.Errorf("member repository list: %w", err)
+		}
+		for _, m := range members {
+			deleted.MemberIDs = append(deleted.MemberIDs, m.UserID)
+		}
+
+		err = projectRepo.Delete(ctx, projectID)
+		if err != nil {
+			return fmt.Errorf("project repository delete: %w", err)
+		}
+
+		err = s.outboxRepo.WithTx(tx).Create(ctx, outbox.EV_PROJECT_DELETED,
+			outbox.ProjectDeleted{
+				ProjectID: deleted.ID,
+				Name:      deleted.Name,
+				MemberIDs: deleted.MemberIDs,
+				DeleterID: userID,
+			})
+		if err != nil {
+			return fmt.Errorf("outbox repository create: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("txManager WithTx: %w", err)
+	}
+
+	return &deleted, nil
+}
+
 func (s *ProjectService) MyProjects(ctx context.Context,
-	userID string) ([]ProjectSummary, error) {
+	userID string,
+	page core.PageQuery) ([]ProjectSummary, string, error) {
+
+	if page.Limit <= 0 || page.Limit > core.MAX_LIST_LIMIT {
+		return nil, "", core.ErrInvalidValue
+	}
 
-	projects, err := s.projectRepo.List(ctx, userID)
+	projects, nextCursor, err := s.projectRepo.List(ctx, userID, page)
 	if err != nil {
-		return nil, fmt.Errorf("project repository list: %w", err)
+		return nil, "", fmt.Errorf("project repository list: %w", err)
 	}
 
 	myProjects := []ProjectSummary{}
@@ -131,18 +349,28 @@ func (s *ProjectService) MyProjects(ctx context.Context,
 			OngoingTasks:    p.OngoingTasks,
 			CompletedTasks:  p.CompletedTasks,
 			AbandonedTasks:  p.AbandonedTasks,
+
+			UnassignedEstimate: p.UnassignedEstimate,
+			OngoingEstimate:    p.OngoingEstimate,
+			CompletedEstimate:  p.CompletedEstimate,
+			AbandonedEstimate:  p.AbandonedEstimate,
 		})
 	}
 
-	return myProjects, nil
+	return myProjects, nextCursor, nil
 }
 
 func (s *ProjectService) ListPublic(ctx context.Context,
-	userID string) ([]ProjectPreview, error) {
+	userID string,
+	page core.PageQuery) ([]ProjectPreview, string, error) {
+
+	if page.Limit <= 0 || page.Limit > core.MAX_LIST_LIMIT {
+		return nil, "", core.ErrInvalidValue
+	}
 
-	rows, err := s.projectRepo.Public(ctx, userID)
+	rows, nextCursor, err := s.projectRepo.Public(ctx, userID, page)
 	if err != nil {
-		return nil, fmt.Errorf("project repository public: %w", err)
+		return nil, "", fmt.Errorf("project repository public: %w", err)
 	}
 
 	projects := []ProjectPreview{}
@@ -158,7 +386,7 @@ func (s *ProjectService) ListPublic(ctx context.Context,
 		})
 	}
 
-	return projects, nil
+	return projects, nextCursor, nil
 }
 
 func (s *ProjectService) RecentlyCreated(ctx context.Context,
@@ -186,6 +414,11 @@ func (s *ProjectService) RecentlyCreated(ctx context.Context,
 			OngoingTasks:    p.OngoingTasks,
 			CompletedTasks:  p.CompletedTasks,
 			AbandonedTasks:  p.AbandonedTasks,
+
+			UnassignedEstimate: p.UnassignedEstimate,
+			OngoingEstimate:    p.OngoingEstimate,
+			CompletedEstimate:  p.CompletedEstimate,
+			AbandonedEstimate:  p.AbandonedEstimate,
 		})
 	}
 
@@ -217,6 +450,11 @@ func (s *ProjectService) RecentlyJoined(ctx context.Context,
 			OngoingTasks:    p.OngoingTasks,
 			CompletedTasks:  p.CompletedTasks,
 			AbandonedTasks:  p.AbandonedTasks,
+
+			UnassignedEstimate: p.UnassignedEstimate,
+			OngoingEstimate:    p.OngoingEstimate,
+			CompletedEstimate:  p.CompletedEstimate,
+			AbandonedEstimate:  p.AbandonedEstimate,
 		})
 	}
 
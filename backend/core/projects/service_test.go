@@ -8,6 +8,7 @@ import (
 	"github.com/ptracker/core"
 	"github.com/ptracker/core/members"
 	"github.com/ptracker/models"
+	"github.com/ptracker/outbox"
 	"github.com/ptracker/testdata"
 	"github.com/ptracker/testhelpers"
 	"github.com/ptracker/testhelpers/fixtures"
@@ -39,7 +40,7 @@ func (suite *projectServiceTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
-	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{})
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -52,7 +53,8 @@ func (suite *projectServiceTestSuite) SetupSuite() {
 	txManager := core.NewTxManager(suite.db)
 	projectRepo := NewProjectRepository(suite.db)
 	memberRepo := members.NewMemberRepository(suite.db)
-	suite.service = NewProjectService(txManager, projectRepo, memberRepo)
+	outboxRepo := outbox.NewOutboxRepository(suite.db)
+	suite.service = NewProjectService(txManager, projectRepo, memberRepo, outboxRepo)
 
 	suite.fixtures = fixtures.New(suite.ctx, suite.db)
 
@@ -218,7 +220,7 @@ func (suite *projectServiceTestSuite) TestProjectMyProjects() {
 	t := suite.T()
 
 	t.Run("should get empty my projects list", func(t *testing.T) {
-		projects, err := suite.service.MyProjects(suite.ctx, USER_ONE)
+		projects, _, err := suite.service.MyProjects(suite.ctx, USER_ONE, core.PageQuery{Limit: 10})
 
 		suite.Require().NoError(err)
 		suite.Require().NotNil(projects)
@@ -227,7 +229,7 @@ func (suite *projectServiceTestSuite) TestProjectMyProjects() {
 	t.Run("should get 1 project in my projects list", func(t *testing.T) {
 		suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 
-		projects, err := suite.service.MyProjects(suite.ctx, USER_ONE)
+		projects, _, err := suite.service.MyProjects(suite.ctx, USER_ONE, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -248,7 +250,7 @@ func (suite *projectServiceTestSuite) TestProjectMyProjects() {
 		suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectID, core.TASK_STATUS_COMPLETED))
 		suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectID, core.TASK_STATUS_COMPLETED))
 
-		projects, _ := suite.service.MyProjects(suite.ctx, USER_ONE)
+		projects, _, _ := suite.service.MyProjects(suite.ctx, USER_ONE, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -364,3 +366,192 @@ func (suite *projectServiceTestSuite) TestProjectRecentlyJoined() {
 		suite.Require().EqualValues(2, projects[0].CompletedTasks)
 	})
 }
+
+func (suite *projectServiceTestSuite) TestProjectDelete() {
+	t := suite.T()
+
+	t.Run("should delete project as owner", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, err := suite.service.Delete(suite.ctx, p, USER_ONE)
+
+		_, getErr := suite.service.Get(suite.ctx, p)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().ErrorIs(getErr, core.ErrNotFound)
+	})
+	t.Run("should return name and members of deleted project", func(t *testing.T) {
+		row := fixtures.RandomProjectRow(USER_ONE)
+		p := suite.fixtures.InsertProject(row)
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		deleted, err := suite.service.Delete(suite.ctx, p, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(row.Name, deleted.Name)
+		suite.Require().ElementsMatch([]string{USER_ONE, USER_TWO}, deleted.MemberIDs)
+	})
+	t.Run("should be forbidden for member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		_, err := suite.service.Delete(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+	t.Run("should be forbidden for non-member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, err := suite.service.Delete(suite.ctx, p, USER_THREE)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+}
+
+func (suite *projectServiceTestSuite) TestProjectUpdate() {
+	t := suite.T()
+
+	t.Run("should update project name", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		name := "Project Renamed"
+
+		_, err := suite.service.Update(suite.ctx, p, USER_ONE, &name, nil, nil, nil)
+
+		project, _ := suite.service.Get(suite.ctx, p)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(name, project.Name)
+	})
+	t.Run("should fail with empty name", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		name := "  "
+
+		_, err := suite.service.Update(suite.ctx, p, USER_ONE, &name, nil, nil, nil)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should fail without any field", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, err := suite.service.Update(suite.ctx, p, USER_ONE, nil, nil, nil, nil)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should be forbidden for member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		skills := "Go"
+
+		_, err := suite.service.Update(suite.ctx, p, USER_TWO, nil, nil, &skills, nil)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+	t.Run("should return precondition failed when project changed since read", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		project, _ := suite.service.Get(suite.ctx, p)
+		name, other := "Project Renamed", "Project Renamed Again"
+
+		_, first := suite.service.Update(suite.ctx, p, USER_ONE, &name, nil, nil, &project.Version)
+		_, second := suite.service.Update(suite.ctx, p, USER_ONE, &other, nil, nil, &project.Version)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(first)
+		suite.Require().ErrorIs(second, core.ErrPreconditionFailed)
+	})
+	t.Run("should return the new version", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		project, _ := suite.service.Get(suite.ctx, p)
+		name := "Project Renamed"
+
+		version, err := suite.service.Update(suite.ctx, p, USER_ONE, &name, nil, nil, &project.Version)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(project.Version+1, version)
+	})
+}
+
+func (suite *projectServiceTestSuite) TestProjectTransferOwnership() {
+	t := suite.T()
+
+	t.Run("should update project owner", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		err := suite.service.TransferOwnership(suite.ctx, p, USER_ONE, USER_TWO)
+
+		project, _ := suite.service.Get(suite.ctx, p)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(USER_TWO, project.OwnerID)
+	})
+	t.Run("should swap roles of old and new owner", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		suite.service.TransferOwnership(suite.ctx, p, USER_ONE, USER_TWO)
+
+		var oldRole, newRole string
+		suite.db.Table("members").Select("role").
+			Where("project_id = ? AND user_id = ?", p, USER_ONE).Scan(&oldRole)
+		suite.db.Table("members").Select("role").
+			Where("project_id = ? AND user_id = ?", p, USER_TWO).Scan(&newRole)
+
+		suite.Cleanup()
+
+		suite.Require().Equal(core.ROLE_MEMBER, oldRole)
+		suite.Require().Equal(core.ROLE_OWNER, newRole)
+	})
+	t.Run("should not transfer to non-member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.service.TransferOwnership(suite.ctx, p, USER_ONE, USER_TWO)
+
+		project, _ := suite.service.Get(suite.ctx, p)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+		suite.Require().Equal(USER_ONE, project.OwnerID)
+	})
+	t.Run("should not transfer to self", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.service.TransferOwnership(suite.ctx, p, USER_ONE, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should be forbidden for member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_THREE, core.ROLE_MEMBER))
+
+		err := suite.service.TransferOwnership(suite.ctx, p, USER_TWO, USER_THREE)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+}
@@ -2,6 +2,7 @@ package requests
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -48,7 +49,7 @@ func (r *JoinRepository) Create(ctx context.Context,
 		},
 	}
 	err = gorm.G[models.JoinRequest](r.db).Create(ctx, &joinReq)
-	if err == gorm.ErrDuplicatedKey {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
 		return core.ErrDuplicate
 	} else if err != nil {
 		return fmt.Errorf("gorm create: %w", err)
@@ -7,8 +7,11 @@ import (
 	"slices"
 	"time"
 
+	"github.com/ptracker/activity"
 	"github.com/ptracker/core"
+	"github.com/ptracker/core/bans"
 	"github.com/ptracker/core/members"
+	"github.com/ptracker/outbox"
 	"gorm.io/gorm"
 )
 
@@ -21,27 +24,59 @@ type JoinRequest struct {
 }
 
 type JoinRequestService struct {
-	txManager  *core.TxManager
-	joinRepo   *JoinRepository
-	memberRepo *members.MemberRepository
+	txManager    *core.TxManager
+	joinRepo     *JoinRepository
+	memberRepo   *members.MemberRepository
+	banRepo      *bans.BanRepository
+	activityRepo *activity.ActivityRepository
+	outboxRepo   *outbox.OutboxRepository
 }
 
 func NewJoinRequestService(txManager *core.TxManager,
 	joinRepo *JoinRepository,
-	memberRepo *members.MemberRepository) *JoinRequestService {
+	memberRepo *members.MemberRepository,
+	banRepo *bans.BanRepository,
+	activityRepo *activity.ActivityRepository,
+	outboxRepo *outbox.OutboxRepository) *JoinRequestService {
 	return &JoinRequestService{
-		txManager:  txManager,
-		joinRepo:   joinRepo,
-		memberRepo: memberRepo,
+		txManager:    txManager,
+		joinRepo:     joinRepo,
+		memberRepo:   memberRepo,
+		banRepo:      banRepo,
+		activityRepo: activityRepo,
+		outboxRepo:   outboxRepo,
 	}
 }
 
 func (s *JoinRequestService) Create(ctx context.Context,
 	projectID, userID string) error {
 
-	err := s.joinRepo.Create(ctx, projectID, userID, core.JOIN_STATUS_PENDING)
+	err := s.banRepo.Is(ctx, projectID, userID)
+	if err == nil {
+		return core.ErrForbidden
+	} else if !errors.Is(err, core.ErrNotFound) {
+		return fmt.Errorf("ban repository is: %w", err)
+	}
+
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		err := s.joinRepo.WithTx(tx).Create(ctx, projectID, userID, core.JOIN_STATUS_PENDING)
+		if err != nil {
+			return fmt.Errorf("join repository create: %w", err)
+		}
+
+		err = s.outboxRepo.WithTx(tx).Create(ctx, outbox.EV_JOIN_REQUESTED,
+			outbox.JoinRequested{
+				ProjectID:   projectID,
+				RequestorID: userID,
+			})
+		if err != nil {
+			return fmt.Errorf("outbox repository create: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("join repository create: %w", err)
+		return fmt.Errorf("txManager WithTx: %w", err)
 	}
 
 	return nil
@@ -65,9 +100,10 @@ func (s *JoinRequestService) List(ctx context.Context,
 
 	var err error
 
-	err = core.NeedsToBeAnOwner(ctx, s.memberRepo, projectID, userID)
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_JOIN_REQUESTS)
 	if err != nil {
-		return nil, fmt.Errorf("needs to be an owner: %w", err)
+		return nil, fmt.Errorf("authorize manage join requests: %w", err)
 	}
 
 	rows, err := s.joinRepo.List(ctx, projectID)
@@ -100,9 +136,10 @@ func (s *JoinRequestService) Respond(ctx context.Context,
 
 	var err error
 
-	err = core.NeedsToBeAnOwner(ctx, s.memberRepo, projectID, responderID)
+	err = core.Authorize(ctx, s.memberRepo, projectID, responderID,
+		core.PERMISSION_MANAGE_JOIN_REQUESTS)
 	if err != nil {
-		return fmt.Errorf("needs to be an owner: %w", err)
+		return fmt.Errorf("authorize manage join requests: %w", err)
 	}
 
 	if !slices.Contains([]string{
@@ -152,6 +189,23 @@ func (s *JoinRequestService) Respond(ctx context.Context,
 			}
 		}
 
+		err = s.activityRepo.WithTx(tx).JoinResponded(ctx, projectID, requestorID,
+			joinStatus, responderID)
+		if err != nil {
+			return fmt.Errorf("activity repository join responded: %w", err)
+		}
+
+		err = s.outboxRepo.WithTx(tx).Create(ctx, outbox.EV_JOIN_RESPONDED,
+			outbox.JoinResponded{
+				ProjectID:   projectID,
+				RequestorID: requestorID,
+				ResponderID: responderID,
+				Status:      joinStatus,
+			})
+		if err != nil {
+			return fmt.Errorf("outbox repository create: %w", err)
+		}
+
 		return nil
 	})
 	if err != nil {
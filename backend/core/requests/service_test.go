@@ -5,9 +5,12 @@ import (
 	"log"
 	"testing"
 
+	"github.com/ptracker/activity"
 	"github.com/ptracker/core"
+	"github.com/ptracker/core/bans"
 	"github.com/ptracker/core/members"
 	"github.com/ptracker/models"
+	"github.com/ptracker/outbox"
 	"github.com/ptracker/testdata"
 	"github.com/ptracker/testhelpers"
 	"github.com/ptracker/testhelpers/fixtures"
@@ -39,7 +42,7 @@ func (suite *joinServiceTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
-	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{})
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -52,7 +55,10 @@ func (suite *joinServiceTestSuite) SetupSuite() {
 	txManager := core.NewTxManager(suite.db)
 	joinRepo := NewJoinRepository(suite.db)
 	memberRepo := members.NewMemberRepository(suite.db)
-	suite.service = NewJoinRequestService(txManager, joinRepo, memberRepo)
+	banRepo := bans.NewBanRepository(suite.db)
+	activityRepo := activity.NewActivityRepository(suite.db)
+	outboxRepo := outbox.NewOutboxRepository(suite.db)
+	suite.service = NewJoinRequestService(txManager, joinRepo, memberRepo, banRepo, activityRepo, outboxRepo)
 
 	suite.fixtures = fixtures.New(suite.ctx, suite.db)
 
@@ -84,6 +90,27 @@ func (suite *joinServiceTestSuite) TestCreate() {
 		suite.Require().NoError(err)
 		suite.Require().Equal(core.JOIN_STATUS_PENDING, status)
 	})
+	t.Run("should be forbidden for banned user", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.db.Create(&models.Ban{ProjectID: p, UserID: USER_TWO})
+
+		err := suite.service.Create(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+	t.Run("should create join request when banned from another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.db.Create(&models.Ban{ProjectID: p1, UserID: USER_TWO})
+
+		err := suite.service.Create(suite.ctx, p2, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+	})
 }
 
 func (suite *joinServiceTestSuite) TestStatus() {
@@ -2,7 +2,10 @@ package tasks
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -31,30 +34,157 @@ type AssigneeRow struct {
 	AssigneeAvatarURL   *string `json:"assignee_avatar_url"`
 }
 
+// Used with datatypes.JSONSlice to store list of LabelRow, like AssigneeRow
+type LabelRow struct {
+	ID        string    `json:"id"`
+	ProjectID string    `json:"project_id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+/*
+Labels of the task aggregated into a json array
+
+It is a correlated subquery instead of one more join, joining the labels
+next to the assignees would multiply the rows of each other.
+*/
+const labelsAggregateSQL = `COALESCE(
+	(SELECT json_agg(
+		json_build_object(
+			'id', l.id,
+			'project_id', l.project_id,
+			'name', l.name,
+			'color', l.color,
+			'created_at', l.created_at,
+			'updated_at', l.updated_at
+		) ORDER BY l.name)
+	FROM task_labels AS tl 
+	INNER JOIN labels AS l ON l.id=tl.label_id 
+	WHERE tl.task_id=t.id),
+	'[]'
+) AS labels`
+
+// Subtask counts of the task, abandoned ones are counted separately so they
+// can be left out of the progress
+const subtaskCountsSQL = `(SELECT COUNT(*) FROM tasks AS st WHERE st.parent_id=t.id) AS subtasks, 
+	(SELECT COUNT(*) FROM tasks AS st 
+		WHERE st.parent_id=t.id AND st.status='Completed') AS completed_subtasks, 
+	(SELECT COUNT(*) FROM tasks AS st 
+		WHERE st.parent_id=t.id AND st.status='Abandoned') AS abandoned_subtasks`
+
 type ProjectTaskItemRow struct {
-	ID          string            `gorm:"column:id"`
-	Title       string            `gorm:"column:title"`
-	Description *string           `gorm:"column:description"`
-	Status      models.TaskStatus `gorm:"column:status"`
-	CreatedAt   time.Time         `gorm:"column:created_at"`
-	UpdatedAt   time.Time         `gorm:"column:updated_at"`
+	ID          string              `gorm:"column:id"`
+	Title       string              `gorm:"column:title"`
+	Description *string             `gorm:"column:description"`
+	Status      models.TaskStatus   `gorm:"column:status"`
+	Priority    models.TaskPriority `gorm:"column:priority"`
+	Estimate    int                 `gorm:"column:estimate"`
+	StartDate   *time.Time          `gorm:"column:start_date"`
+	DueDate     *time.Time          `gorm:"column:due_date"`
+	CreatedAt   time.Time           `gorm:"column:created_at"`
+	UpdatedAt   time.Time           `gorm:"column:updated_at"`
+	Version     int                 `gorm:"column:version"`
+
+	WorkflowStatus *string `gorm:"column:workflow_status"`
+
+	ParentID          *string `gorm:"column:parent_id"`
+	Subtasks          int64   `gorm:"column:subtasks"`
+	CompletedSubtasks int64   `gorm:"column:completed_subtasks"`
+	AbandonedSubtasks int64   `gorm:"column:abandoned_subtasks"`
 
 	ProjectID string                           `gorm:"column:project_id"`
 	Assignees datatypes.JSONSlice[AssigneeRow] `gorm:"column:assignees"`
+	Labels    datatypes.JSONSlice[LabelRow]    `gorm:"column:labels"`
 }
 
 type DashboardTaskItemRow struct {
-	ID          string            `gorm:"column:id"`
-	Title       string            `gorm:"column:title"`
-	Description *string           `gorm:"column:description"`
-	Status      models.TaskStatus `gorm:"column:status"`
-	CreatedAt   time.Time         `gorm:"column:created_at"`
-	UpdatedAt   time.Time         `gorm:"column:updated_at"`
+	ID          string              `gorm:"column:id"`
+	Title       string              `gorm:"column:title"`
+	Description *string             `gorm:"column:description"`
+	Status      models.TaskStatus   `gorm:"column:status"`
+	Priority    models.TaskPriority `gorm:"column:priority"`
+	Estimate    int                 `gorm:"column:estimate"`
+	StartDate   *time.Time          `gorm:"column:start_date"`
+	DueDate     *time.Time          `gorm:"column:due_date"`
+	CreatedAt   time.Time           `gorm:"column:created_at"`
+	UpdatedAt   time.Time           `gorm:"column:updated_at"`
 
 	ProjectID   string `gorm:"column:project_id"`
 	ProjectName string `gorm:"column:project_name"`
 }
 
+// Task on the other end of a dependency
+type TaskDependencyRow struct {
+	ID     string            `gorm:"column:id"`
+	Title  string            `gorm:"column:title"`
+	Status models.TaskStatus `gorm:"column:status"`
+}
+
+/*
+Filtering, sorting and keyset pagination options for TaskRepository.List
+
+Cursor is the opaque value returned by a previous List call, the sort
+field and order must be the same as the ones used for that call.
+*/
+type TaskListQuery struct {
+	Cursor string
+	Limit  int
+
+	Statuses         []string
+	WorkflowStatuses []string // tasks in any of the statuses, or of Statuses
+	Priorities       []string
+	LabelIDs         []string // tasks with any of the labels
+	ParentID         *string  // subtasks of the task
+	AssigneeID       *string
+	MinEstimate      *int
+	MaxEstimate      *int
+	CreatedAfter     *time.Time
+	CreatedBefore    *time.Time
+	UpdatedAfter     *time.Time
+	UpdatedBefore    *time.Time
+
+	SortBy string // created_at, updated_at, title, priority or estimate
+	Order  string // asc or desc
+}
+
+/*
+Orders the priorities by their urgency, priorities are stored as their names
+so sorting on the column itself would sort them alphabetically
+*/
+const priorityRankSQL = `CASE t.priority 
+	WHEN 'Low' THEN 0 
+	WHEN 'Medium' THEN 1 
+	WHEN 'High' THEN 2 
+	WHEN 'Urgent' THEN 3 
+	END`
+
+// Returns the rank of the priority in the same order as priorityRankSQL
+func priorityRank(priority string) int {
+	return slices.Index([]string{
+		core.TASK_PRIORITY_LOW,
+		core.TASK_PRIORITY_MEDIUM,
+		core.TASK_PRIORITY_HIGH,
+		core.TASK_PRIORITY_URGENT,
+	}, priority)
+}
+
+func taskCursor(row ProjectTaskItemRow, sortBy string) core.Cursor {
+	switch sortBy {
+	case core.TASK_SORT_TITLE:
+		return core.Cursor{Value: row.Title, ID: row.ID}
+	case core.TASK_SORT_PRIORITY:
+		return core.NewIntCursor(priorityRank(row.Priority.String), row.ID)
+	case core.TASK_SORT_ESTIMATE:
+		return core.NewIntCursor(row.Estimate, row.ID)
+	case core.TASK_SORT_UPDATED_AT:
+		return core.NewTimeCursor(row.UpdatedAt, row.ID)
+	default:
+		return core.NewTimeCursor(row.CreatedAt, row.ID)
+	}
+}
+
 type TaskRepository struct {
 	db *gorm.DB
 }
@@ -65,19 +195,36 @@ func NewTaskRepository(db *gorm.DB) *TaskRepository {
 	}
 }
 
+func (r *TaskRepository) WithTx(tx *gorm.DB) *TaskRepository {
+	return NewTaskRepository(tx)
+}
+
 func (r *TaskRepository) Create(ctx context.Context,
 	projectID string,
-	title, description, status string) (string, error) {
+	title, description, status string,
+	workflowStatus *string,
+	priority string,
+	estimate int,
+	parentID *string,
+	startDate, dueDate *time.Time) (string, error) {
 
 	id := uuid.NewString()
 	task := models.Task{
 		ID:          id,
 		ProjectID:   projectID,
+		ParentID:    parentID,
 		Title:       title,
 		Description: &description,
 		Status: models.TaskStatus{
 			String: status,
 		},
+		WorkflowStatus: workflowStatus,
+		Priority: models.TaskPriority{
+			String: priority,
+		},
+		Estimate:  estimate,
+		StartDate: startDate,
+		DueDate:   dueDate,
 	}
 
 	err := gorm.G[models.Task](r.db).Create(ctx, &task)
@@ -88,10 +235,14 @@ func (r *TaskRepository) Create(ctx context.Context,
 	return task.ID, nil
 }
 
-func (r *TaskRepository) Get(ctx context.Context, id string) (ProjectTaskItemRow, error) {
+func (r *TaskRepository) Get(ctx context.Context,
+	projectID, id string) (ProjectTaskItemRow, error) {
 
 	query := `SELECT 
-			t.id, t.title, t.description, t.status, t.created_at, t.updated_at, 
+			t.id, t.project_id, t.parent_id, t.title, t.description, t.status, 
+			t.workflow_status, t.priority, t.estimate, t.start_date, t.due_date, 
+			t.created_at, t.updated_at, t.version, 
+			` + subtaskCountsSQL + `, 
 			COALESCE(
 				json_agg(
 				json_build_object(
@@ -105,26 +256,161 @@ func (r *TaskRepository) Get(ctx context.Context, id string) (ProjectTaskItemRow
 				)
 				) FILTER (WHERE a.user_id IS NOT NULL),
 				'[]'
-			) AS assignees 
+			) AS assignees, 
+			` + labelsAggregateSQL + ` 
 			FROM tasks AS t 
 			LEFT JOIN assignees AS a ON a.task_id=t.id 
 			LEFT JOIN users AS u ON u.id=a.user_id 
-			WHERE t.id = ? 
+			WHERE t.project_id = ? AND t.id = ? 
 			GROUP BY t.id, t.title, t.status, t.created_at, t.updated_at`
 
-	task, err := gorm.G[ProjectTaskItemRow](r.db).Raw(query, id).First(ctx)
-	if err != nil {
+	task, err := gorm.G[ProjectTaskItemRow](r.db).Raw(query, projectID, id).First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return task, core.ErrNotFound
+	} else if err != nil {
 		return task, fmt.Errorf("gorm query task: %w", err)
 	}
 
 	return task, nil
 }
 
+func (r *TaskRepository) Exists(ctx context.Context,
+	projectID, id string) error {
+
+	_, err := gorm.G[models.Task](r.db).
+		Where("project_id = ? AND id = ?", projectID, id).
+		First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return core.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("gorm query: %w", err)
+	}
+
+	return nil
+}
+
 func (r *TaskRepository) List(ctx context.Context,
-	projectId string) ([]ProjectTaskItemRow, error) {
+	projectId string,
+	query TaskListQuery) ([]ProjectTaskItemRow, string, error) {
 
-	query := `SELECT 
-		t.id, t.title, t.status, t.created_at, t.updated_at, 
+	var err error
+
+	sortBy := query.SortBy
+	if sortBy == "" {
+		sortBy = core.TASK_SORT_CREATED_AT
+	}
+	sortColumn, ok := map[string]string{
+		core.TASK_SORT_CREATED_AT: "t.created_at",
+		core.TASK_SORT_UPDATED_AT: "t.updated_at",
+		core.TASK_SORT_TITLE:      "t.title",
+		core.TASK_SORT_PRIORITY:   priorityRankSQL,
+		core.TASK_SORT_ESTIMATE:   "t.estimate",
+	}[sortBy]
+	if !ok {
+		return nil, "", core.ErrInvalidValue
+	}
+
+	order := query.Order
+	if order == "" {
+		order = core.SORT_ORDER_DESC
+	}
+	if order != core.SORT_ORDER_ASC && order != core.SORT_ORDER_DESC {
+		return nil, "", core.ErrInvalidValue
+	}
+
+	if query.Limit <= 0 {
+		return nil, "", core.ErrInvalidValue
+	}
+
+	conditions := []string{"t.project_id = ?"}
+	args := []any{projectId}
+
+	if len(query.Statuses) > 0 && len(query.WorkflowStatuses) > 0 {
+		conditions = append(conditions, "(t.status IN ? OR t.workflow_status IN ?)")
+		args = append(args, query.Statuses, query.WorkflowStatuses)
+	} else if len(query.Statuses) > 0 {
+		conditions = append(conditions, "t.status IN ?")
+		args = append(args, query.Statuses)
+	} else if len(query.WorkflowStatuses) > 0 {
+		conditions = append(conditions, "t.workflow_status IN ?")
+		args = append(args, query.WorkflowStatuses)
+	}
+	if len(query.Priorities) > 0 {
+		conditions = append(conditions, "t.priority IN ?")
+		args = append(args, query.Priorities)
+	}
+	if query.ParentID != nil {
+		conditions = append(conditions, "t.parent_id = ?")
+		args = append(args, *query.ParentID)
+	}
+	if len(query.LabelIDs) > 0 {
+		conditions = append(conditions,
+			"EXISTS (SELECT 1 FROM task_labels AS fl WHERE fl.task_id = t.id AND fl.label_id IN ?)")
+		args = append(args, query.LabelIDs)
+	}
+	if query.MinEstimate != nil {
+		conditions = append(conditions, "t.estimate >= ?")
+		args = append(args, *query.MinEstimate)
+	}
+	if query.MaxEstimate != nil {
+		conditions = append(conditions, "t.estimate <= ?")
+		args = append(args, *query.MaxEstimate)
+	}
+	if query.AssigneeID != nil {
+		conditions = append(conditions,
+			"EXISTS (SELECT 1 FROM assignees AS fa WHERE fa.task_id = t.id AND fa.user_id = ?)")
+		args = append(args, *query.AssigneeID)
+	}
+	if query.CreatedAfter != nil {
+		conditions = append(conditions, "t.created_at >= ?")
+		args = append(args, *query.CreatedAfter)
+	}
+	if query.CreatedBefore != nil {
+		conditions = append(conditions, "t.created_at < ?")
+		args = append(args, *query.CreatedBefore)
+	}
+	if query.UpdatedAfter != nil {
+		conditions = append(conditions, "t.updated_at >= ?")
+		args = append(args, *query.UpdatedAfter)
+	}
+	if query.UpdatedBefore != nil {
+		conditions = append(conditions, "t.updated_at < ?")
+		args = append(args, *query.UpdatedBefore)
+	}
+
+	// keyset pagination: continue strictly after the (sort value, id) of
+	// the last row of the previous page
+	comparator := "<"
+	if order == core.SORT_ORDER_ASC {
+		comparator = ">"
+	}
+	cursor, err := core.DecodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if cursor != nil {
+		var value any
+		switch sortBy {
+		case core.TASK_SORT_TITLE:
+			value = cursor.Value
+		case core.TASK_SORT_PRIORITY, core.TASK_SORT_ESTIMATE:
+			value, err = cursor.Int()
+		default:
+			value, err = cursor.Time()
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		conditions = append(conditions,
+			fmt.Sprintf("(%s, t.id) %s (?, ?)", sortColumn, comparator))
+		args = append(args, value, cursor.ID)
+	}
+
+	sql := fmt.Sprintf(`SELECT 
+		t.id, t.parent_id, t.title, t.status, t.workflow_status, t.priority, 
+		t.estimate, t.start_date, t.due_date, t.created_at, t.updated_at, 
+		t.version, %s, 
 		COALESCE(
 			json_agg(
 			json_build_object(
@@ -138,47 +424,336 @@ func (r *TaskRepository) List(ctx context.Context,
 			)
 			) FILTER (WHERE a.user_id IS NOT NULL),
 			'[]'
-		) AS assignees 
+		) AS assignees, 
+		%s 
 		FROM tasks AS t 
 		LEFT JOIN assignees AS a ON a.task_id=t.id 
 		LEFT JOIN users AS u ON u.id=a.user_id 
-		WHERE t.project_id=? 
-		GROUP BY t.id`
+		WHERE %s 
+		GROUP BY t.id 
+		ORDER BY %s %s, t.id %s 
+		LIMIT ?`,
+		subtaskCountsSQL,
+		labelsAggregateSQL,
+		strings.Join(conditions, " AND "),
+		sortColumn, order, order)
+	args = append(args, query.Limit+1)
 
 	var rows = []ProjectTaskItemRow{}
-	err := r.db.Raw(query, projectId).Scan(&rows).Error
+	err = r.db.WithContext(ctx).Raw(sql, args...).Scan(&rows).Error
 	if err != nil {
-		return nil, fmt.Errorf("gorm db raw scan: %w", err)
+		return nil, "", fmt.Errorf("gorm db raw scan: %w", err)
 	}
 
-	return rows, nil
+	rows, nextCursor := core.Paginate(rows, query.Limit,
+		func(row ProjectTaskItemRow) core.Cursor {
+			return taskCursor(row, sortBy)
+		})
+
+	return rows, nextCursor, nil
 }
 
-func (r *TaskRepository) Update(ctx context.Context, id string,
-	title, description, status *string) error {
+func (r *TaskRepository) Update(ctx context.Context,
+	projectID, id string,
+	title, description, priority *string,
+	estimate *int,
+	parentID *string,
+	startDate, dueDate *time.Time) error {
 
-	task, err := gorm.G[models.Task](r.db).Where("id = ?", id).First(ctx)
-	if err != nil {
-		return fmt.Errorf("gorm query task: %w", err)
-	}
+	// only the given columns are written, so concurrent updates of the
+	// other fields are kept, the status goes through UpdateStatus
+	columns := map[string]any{}
 
 	if title != nil {
-		task.Title = *title
+		columns["title"] = *title
 	}
 
 	if description != nil {
-		*task.Description = *description
+		columns["description"] = *description
 	}
 
-	if status != nil {
-		task.Status = models.TaskStatus{
-			String: *status,
+	if priority != nil {
+		columns["priority"] = *priority
+	}
+
+	if estimate != nil {
+		columns["estimate"] = *estimate
+	}
+
+	// an empty parent moves the task back to the top level
+	if parentID != nil {
+		if *parentID == "" {
+			columns["parent_id"] = nil
+		} else {
+			columns["parent_id"] = *parentID
 		}
 	}
 
-	err = r.db.Save(&task).Error
+	// a zero date clears it
+	if startDate != nil {
+		if startDate.IsZero() {
+			columns["start_date"] = nil
+		} else {
+			columns["start_date"] = *startDate
+		}
+	}
+
+	if dueDate != nil {
+		if dueDate.IsZero() {
+			columns["due_date"] = nil
+		} else {
+			columns["due_date"] = *dueDate
+		}
+	}
+
+	return r.updateColumns(ctx, projectID, id, columns)
+}
+
+// Sets the status of the task, a nil workflow status leaves the task
+// only in the built-in status
+func (r *TaskRepository) UpdateStatus(ctx context.Context,
+	projectID, id, status string,
+	workflowStatus *string) error {
+
+	return r.updateColumns(ctx, projectID, id, map[string]any{
+		"status":          status,
+		"workflow_status": workflowStatus,
+	})
+}
+
+/*
+Bumps the version of the task, when a version is given only if the task is
+still at it
+
+Returns ErrPreconditionFailed when the task was updated past the version.
+*/
+func (r *TaskRepository) BumpVersion(ctx context.Context,
+	projectID, id string,
+	version *int) error {
+
+	query := r.db.WithContext(ctx).
+		Model(&models.Task{}).
+		Where("project_id = ? AND id = ?", projectID, id)
+	if version != nil {
+		query = query.Where("version = ?", *version)
+	}
+
+	result := query.Update("version", gorm.Expr("version + 1"))
+	if result.Error != nil {
+		return fmt.Errorf("gorm db update: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	err := r.Exists(ctx, projectID, id)
+	if err != nil {
+		return fmt.Errorf("exists: %w", err)
+	}
+
+	return core.ErrPreconditionFailed
+}
+
+func (r *TaskRepository) updateColumns(ctx context.Context,
+	projectID, id string,
+	columns map[string]any) error {
+
+	result := r.db.WithContext(ctx).
+		Model(&models.Task{}).
+		Where("project_id = ? AND id = ?", projectID, id).
+		Updates(columns)
+	if result.Error != nil {
+		return fmt.Errorf("gorm db updates: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return core.ErrNotFound
+	}
+
+	return nil
+}
+
+/*
+Returns the ids of the task and all of its ancestors, starting with the task
+
+UNION drops the rows already visited, so the walk ends even if the stored
+hierarchy somehow has a cycle.
+*/
+func (r *TaskRepository) Ancestors(ctx context.Context,
+	projectID, id string) ([]string, error) {
+
+	query := `WITH RECURSIVE ancestors AS (
+			SELECT t.id, t.parent_id FROM tasks AS t 
+			WHERE t.project_id = ? AND t.id = ? 
+			UNION 
+			SELECT t.id, t.parent_id FROM tasks AS t 
+			INNER JOIN ancestors AS a ON t.id=a.parent_id
+		) 
+		SELECT id FROM ancestors`
+
+	var ids = []string{}
+	err := r.db.WithContext(ctx).Raw(query, projectID, id).Scan(&ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("gorm db raw scan: %w", err)
+	}
+
+	return ids, nil
+}
+
+func (r *TaskRepository) CountSubtasks(ctx context.Context,
+	projectID, id, status string) (int64, error) {
+
+	count, err := gorm.G[models.Task](r.db).
+		Where("project_id = ? AND parent_id = ? AND status = ?", projectID, id, status).
+		Count(ctx, "*")
+	if err != nil {
+		return 0, fmt.Errorf("gorm count: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *TaskRepository) AddBlocker(ctx context.Context,
+	taskID, blockerID string) error {
+
+	dependency := models.TaskDependency{
+		TaskID:    taskID,
+		BlockerID: blockerID,
+	}
+	err := gorm.G[models.TaskDependency](r.db).Create(ctx, &dependency)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return core.ErrDuplicate
+	} else if err != nil {
+		return fmt.Errorf("gorm create: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TaskRepository) IsBlocker(ctx context.Context,
+	taskID, blockerID string) error {
+
+	_, err := gorm.G[models.TaskDependency](r.db).
+		Where("task_id = ? AND blocker_id = ?", taskID, blockerID).
+		First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return core.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("gorm query: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TaskRepository) RemoveBlocker(ctx context.Context,
+	taskID, blockerID string) error {
+
+	rows, err := gorm.G[models.TaskDependency](r.db).
+		Where("task_id = ? AND blocker_id = ?", taskID, blockerID).
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("gorm delete: %w", err)
+	}
+	if rows == 0 {
+		return core.ErrNotFound
+	}
+
+	return nil
+}
+
+/*
+Returns the ids of the task and all the tasks blocking it, directly or
+through other blockers
+
+Same as Ancestors, UNION keeps the walk finite.
+*/
+func (r *TaskRepository) TransitiveBlockers(ctx context.Context,
+	id string) ([]string, error) {
+
+	query := `WITH RECURSIVE blockers AS (
+			SELECT CAST(? AS TEXT) AS id 
+			UNION 
+			SELECT d.blocker_id FROM task_dependencies AS d 
+			INNER JOIN blockers AS b ON d.task_id=b.id
+		) 
+		SELECT id FROM blockers`
+
+	var ids = []string{}
+	err := r.db.WithContext(ctx).Raw(query, id).Scan(&ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("gorm db raw scan: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Tasks blocking the task, in the order they were added
+func (r *TaskRepository) BlockedBy(ctx context.Context,
+	projectID, id string) ([]TaskDependencyRow, error) {
+
+	var rows = []TaskDependencyRow{}
+	err := r.db.WithContext(ctx).
+		Table("task_dependencies AS d").
+		Select("t.id, t.title, t.status").
+		Joins("INNER JOIN tasks AS t ON t.id=d.blocker_id").
+		Where("t.project_id = ? AND d.task_id = ?", projectID, id).
+		Order("d.created_at ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("gorm db scan: %w", err)
+	}
+
+	return rows, nil
+}
+
+// Tasks blocked by the task, in the order they were added
+func (r *TaskRepository) Blocks(ctx context.Context,
+	projectID, id string) ([]TaskDependencyRow, error) {
+
+	var rows = []TaskDependencyRow{}
+	err := r.db.WithContext(ctx).
+		Table("task_dependencies AS d").
+		Select("t.id, t.title, t.status").
+		Joins("INNER JOIN tasks AS t ON t.id=d.task_id").
+		Where("t.project_id = ? AND d.blocker_id = ?", projectID, id).
+		Order("d.created_at ASC").
+		Scan(&rows).Error
 	if err != nil {
-		return fmt.Errorf("gorm db save: %w", err)
+		return nil, fmt.Errorf("gorm db scan: %w", err)
+	}
+
+	return rows, nil
+}
+
+// Counts the blockers of the task which are neither Completed nor Abandoned
+func (r *TaskRepository) CountOpenBlockers(ctx context.Context,
+	projectID, id string) (int64, error) {
+
+	var count int64
+	err := r.db.WithContext(ctx).
+		Table("task_dependencies AS d").
+		Joins("INNER JOIN tasks AS t ON t.id=d.blocker_id").
+		Where("t.project_id = ? AND d.task_id = ? AND t.status NOT IN ?",
+			projectID, id, []string{core.TASK_STATUS_COMPLETED, core.TASK_STATUS_ABANDONED}).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("gorm db count: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *TaskRepository) Delete(ctx context.Context,
+	projectID, id string) error {
+
+	// assignees, comments and dependencies are removed by the ON DELETE CASCADE constraints
+	rows, err := gorm.G[models.Task](r.db).
+		Where("project_id = ? AND id = ?", projectID, id).
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("gorm delete: %w", err)
+	}
+	if rows == 0 {
+		return core.ErrNotFound
 	}
 
 	return nil
@@ -191,7 +766,8 @@ func (r *TaskRepository) RecentlyAssigned(ctx context.Context,
 	var rows = []DashboardTaskItemRow{}
 	err := r.db.WithContext(ctx).
 		Table("tasks t").
-		Select(`t.id, t.title, t.status, t.created_at, t.updated_at,
+		Select(`t.id, t.title, t.status, t.priority, t.estimate, t.start_date, t.due_date,
+				t.created_at, t.updated_at,
 				p.id as project_id, p.name as project_name`).
 		Joins("INNER JOIN projects AS p ON t.project_id=p.id").
 		Joins("INNER JOIN assignees AS a ON a.task_id=t.id").
@@ -214,8 +790,9 @@ func (r *TaskRepository) RecentlyUnassigned(ctx context.Context,
 	var rows = []DashboardTaskItemRow{}
 	err := r.db.WithContext(ctx).
 		Table("tasks t").
-		Select(`t.id, t.project_id, t.title, t.status, t.created_at, t.updated_at,
-					p.name as project_name`).
+		Select(`t.id, t.project_id, t.title, t.status, t.priority, t.estimate,
+					t.start_date, t.due_date,
+					t.created_at, t.updated_at, p.name as project_name`).
 		Joins("INNER JOIN projects AS p ON t.project_id=p.id").
 		Where("p.owner_id = ? AND t.status = ?", userId, core.TASK_STATUS_UNASSIGNED).
 		Order("t.created_at DESC").
@@ -228,3 +805,43 @@ func (r *TaskRepository) RecentlyUnassigned(ctx context.Context,
 
 	return rows, nil
 }
+
+/*
+Lists the open (Unassigned/Ongoing) tasks with a due date before `before`
+from all the projects the user is a member of, earliest due date first
+
+If `after` is not nil, only the tasks due on or after it are listed.
+*/
+func (r *TaskRepository) Due(ctx context.Context,
+	userId string,
+	after *time.Time, before time.Time,
+	n int) ([]DashboardTaskItemRow, error) {
+
+	query := r.db.WithContext(ctx).
+		Table("tasks t").
+		Select(`t.id, t.project_id, t.title, t.status, t.priority, t.estimate,
+					t.start_date, t.due_date,
+					t.created_at, t.updated_at, p.name as project_name`).
+		Joins("INNER JOIN projects AS p ON t.project_id=p.id").
+		Joins("INNER JOIN members AS m ON m.project_id=p.id").
+		Where("m.user_id = ? AND t.status IN ?", userId, []string{
+			core.TASK_STATUS_UNASSIGNED,
+			core.TASK_STATUS_ONGOING,
+		}).
+		Where("t.due_date IS NOT NULL AND t.due_date < ?", before)
+	if after != nil {
+		query = query.Where("t.due_date >= ?", *after)
+	}
+
+	var rows = []DashboardTaskItemRow{}
+	err := query.
+		Order("t.due_date ASC, t.id ASC").
+		Limit(n).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("gorm db scan: %w", err)
+	}
+
+	return rows, nil
+}
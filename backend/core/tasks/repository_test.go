@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"testing"
+	"time"
 
 	"github.com/ptracker/core"
 	"github.com/ptracker/models"
@@ -36,7 +37,7 @@ func (suite *taskRepositoryTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
-	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{})
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -74,7 +75,7 @@ func (suite *taskRepositoryTestSuite) TestTaskCreate() {
 		sample_status := core.TASK_STATUS_UNASSIGNED
 
 		_, err := suite.repo.Create(suite.ctx, p,
-			sample_title, sample_description, sample_status)
+			sample_title, sample_description, sample_status, nil, core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil)
 
 		suite.Cleanup()
 
@@ -87,7 +88,7 @@ func (suite *taskRepositoryTestSuite) TestTaskCreate() {
 		sample_status := core.TASK_STATUS_UNASSIGNED
 
 		id, _ := suite.repo.Create(suite.ctx, p,
-			sample_title, sample_description, sample_status)
+			sample_title, sample_description, sample_status, nil, core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil)
 		task, _ := gorm.G[models.Task](suite.db).Where("id = ?", id).First(suite.ctx)
 
 		suite.Cleanup()
@@ -103,7 +104,7 @@ func (suite *taskRepositoryTestSuite) TestTaskCreate() {
 		sample_status := core.TASK_STATUS_UNASSIGNED
 
 		_, err := suite.repo.Create(suite.ctx, p,
-			sample_title, "", sample_status)
+			sample_title, "", sample_status, nil, core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil)
 
 		suite.Cleanup()
 
@@ -125,7 +126,7 @@ func (suite *taskRepositoryTestSuite) TestTaskGet() {
 			Description: &sample_description,
 			Status:      models.TaskStatus{String: sample_status},
 		})
-		task, _ := suite.repo.Get(suite.ctx, taskID)
+		task, _ := suite.repo.Get(suite.ctx, p, taskID)
 
 		suite.Cleanup()
 
@@ -140,7 +141,7 @@ func (suite *taskRepositoryTestSuite) TestTaskGet() {
 		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
 		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskID, USER_TWO))
 
-		_, err := suite.repo.Get(suite.ctx, taskID)
+		_, err := suite.repo.Get(suite.ctx, p, taskID)
 
 		suite.Cleanup()
 
@@ -154,7 +155,7 @@ func (suite *taskRepositoryTestSuite) TestTaskGet() {
 		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskID, USER_TWO))
 		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskID, USER_THREE))
 
-		task, _ := suite.repo.Get(suite.ctx, taskID)
+		task, _ := suite.repo.Get(suite.ctx, p, taskID)
 
 		suite.Cleanup()
 
@@ -168,7 +169,7 @@ func (suite *taskRepositoryTestSuite) TestTaskGet() {
 		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskID, USER_TWO))
 		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskID, USER_THREE))
 
-		task, _ := suite.repo.Get(suite.ctx, taskID)
+		task, _ := suite.repo.Get(suite.ctx, p, taskID)
 
 		suite.Cleanup()
 
@@ -179,13 +180,38 @@ func (suite *taskRepositoryTestSuite) TestTaskGet() {
 	})
 }
 
+func (suite *taskRepositoryTestSuite) TestTaskGetLabels() {
+	t := suite.T()
+
+	t.Run("should get labels next to assignees without duplicates", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskID, USER_ONE))
+		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskID, USER_TWO))
+		label := fixtures.RandomLabelRow(p)
+		suite.fixtures.InsertLabel(label)
+		suite.fixtures.InsertTaskLabel(fixtures.GetTaskLabelRow(taskID, label.ID))
+
+		task, err := suite.repo.Get(suite.ctx, p, taskID)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, len(task.Assignees))
+		suite.Require().Equal(1, len(task.Labels))
+		suite.Require().Equal(label.Name, task.Labels[0].Name)
+		suite.Require().Equal(label.Color, task.Labels[0].Color)
+	})
+}
+
 func (suite *taskRepositoryTestSuite) TestTaskList() {
 	t := suite.T()
 
 	t.Run("should get empty list", func(t *testing.T) {
 		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 
-		tasks, err := suite.repo.List(suite.ctx, p)
+		tasks, _, err := suite.repo.List(suite.ctx, p, TaskListQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -197,7 +223,7 @@ func (suite *taskRepositoryTestSuite) TestTaskList() {
 		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
 
-		_, err := suite.repo.List(suite.ctx, p)
+		_, _, err := suite.repo.List(suite.ctx, p, TaskListQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -208,7 +234,7 @@ func (suite *taskRepositoryTestSuite) TestTaskList() {
 		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
 		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
 
-		tasks, _ := suite.repo.List(suite.ctx, p)
+		tasks, _, _ := suite.repo.List(suite.ctx, p, TaskListQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -219,7 +245,7 @@ func (suite *taskRepositoryTestSuite) TestTaskList() {
 		t1 := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
 		t2 := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
 
-		tasks, _ := suite.repo.List(suite.ctx, p)
+		tasks, _, _ := suite.repo.List(suite.ctx, p, TaskListQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -236,7 +262,7 @@ func (suite *taskRepositoryTestSuite) TestTaskList() {
 		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, task, USER_TWO))
 		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, task, USER_THREE))
 
-		tasks, _ := suite.repo.List(suite.ctx, p)
+		tasks, _, _ := suite.repo.List(suite.ctx, p, TaskListQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -247,6 +273,248 @@ func (suite *taskRepositoryTestSuite) TestTaskList() {
 	})
 }
 
+func (suite *taskRepositoryTestSuite) TestTaskListQuery() {
+	t := suite.T()
+
+	t.Run("should limit tasks and return next cursor", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+
+		tasks, cursor, err := suite.repo.List(suite.ctx, p, TaskListQuery{Limit: 2})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, len(tasks))
+		suite.Require().NotEmpty(cursor)
+	})
+	t.Run("should not return next cursor on last page", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+
+		_, cursor, err := suite.repo.List(suite.ctx, p, TaskListQuery{Limit: 2})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Empty(cursor)
+	})
+	t.Run("should walk all pages without duplicates", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		t1 := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		t2 := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		t3 := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+
+		first, cursor, _ := suite.repo.List(suite.ctx, p, TaskListQuery{Limit: 2})
+		second, last, err := suite.repo.List(suite.ctx, p, TaskListQuery{Limit: 2, Cursor: cursor})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Empty(last)
+		suite.Require().ElementsMatch(
+			[]string{t1, t2, t3},
+			[]string{first[0].ID, first[1].ID, second[0].ID},
+		)
+	})
+	t.Run("should sort by title ascending", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		b := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		b.Title = "B task"
+		a := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		a.Title = "A task"
+		suite.fixtures.InsertTask(b)
+		suite.fixtures.InsertTask(a)
+
+		tasks, _, err := suite.repo.List(suite.ctx, p, TaskListQuery{
+			Limit:  10,
+			SortBy: core.TASK_SORT_TITLE,
+			Order:  core.SORT_ORDER_ASC,
+		})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal([]string{a.ID, b.ID}, []string{tasks[0].ID, tasks[1].ID})
+	})
+	t.Run("should sort by priority descending", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		high := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		high.Priority = models.TaskPriority{String: core.TASK_PRIORITY_HIGH}
+		low := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		low.Priority = models.TaskPriority{String: core.TASK_PRIORITY_LOW}
+		urgent := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		urgent.Priority = models.TaskPriority{String: core.TASK_PRIORITY_URGENT}
+		suite.fixtures.InsertTask(high)
+		suite.fixtures.InsertTask(low)
+		suite.fixtures.InsertTask(urgent)
+
+		first, cursor, _ := suite.repo.List(suite.ctx, p, TaskListQuery{
+			Limit:  2,
+			SortBy: core.TASK_SORT_PRIORITY,
+		})
+		second, _, err := suite.repo.List(suite.ctx, p, TaskListQuery{
+			Limit:  2,
+			Cursor: cursor,
+			SortBy: core.TASK_SORT_PRIORITY,
+		})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(
+			[]string{urgent.ID, high.ID, low.ID},
+			[]string{first[0].ID, first[1].ID, second[0].ID},
+		)
+	})
+	t.Run("should sort by estimate ascending", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		large := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		large.Estimate = 8
+		small := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		small.Estimate = 1
+		suite.fixtures.InsertTask(large)
+		suite.fixtures.InsertTask(small)
+
+		tasks, _, err := suite.repo.List(suite.ctx, p, TaskListQuery{
+			Limit:  10,
+			SortBy: core.TASK_SORT_ESTIMATE,
+			Order:  core.SORT_ORDER_ASC,
+		})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal([]string{small.ID, large.ID}, []string{tasks[0].ID, tasks[1].ID})
+	})
+	t.Run("should filter by priority and estimate", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		urgent := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		urgent.Priority = models.TaskPriority{String: core.TASK_PRIORITY_URGENT}
+		urgent.Estimate = 3
+		suite.fixtures.InsertTask(urgent)
+		large := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		large.Priority = models.TaskPriority{String: core.TASK_PRIORITY_URGENT}
+		large.Estimate = 13
+		suite.fixtures.InsertTask(large)
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		maxEstimate := 5
+
+		tasks, _, err := suite.repo.List(suite.ctx, p, TaskListQuery{
+			Limit:       10,
+			Priorities:  []string{core.TASK_PRIORITY_URGENT},
+			MaxEstimate: &maxEstimate,
+		})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(tasks))
+		suite.Require().Equal(urgent.ID, tasks[0].ID)
+	})
+	t.Run("should filter by label", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		labeled := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+		suite.fixtures.InsertTaskLabel(fixtures.GetTaskLabelRow(labeled, l))
+
+		tasks, _, err := suite.repo.List(suite.ctx, p, TaskListQuery{
+			Limit:    10,
+			LabelIDs: []string{l},
+		})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(tasks))
+		suite.Require().Equal(labeled, tasks[0].ID)
+		suite.Require().Equal(1, len(tasks[0].Labels))
+	})
+	t.Run("should filter by status", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		ongoing := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		tasks, _, err := suite.repo.List(suite.ctx, p, TaskListQuery{
+			Limit:    10,
+			Statuses: []string{core.TASK_STATUS_ONGOING},
+		})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(tasks))
+		suite.Require().Equal(ongoing, tasks[0].ID)
+	})
+	t.Run("should filter by assignee and keep all assignees", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_THREE, core.ROLE_MEMBER))
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		task := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, task, USER_TWO))
+		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, task, USER_THREE))
+
+		tasks, _, err := suite.repo.List(suite.ctx, p, TaskListQuery{
+			Limit:      10,
+			AssigneeID: &USER_TWO,
+		})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(tasks))
+		suite.Require().Equal(2, len(tasks[0].Assignees))
+	})
+	t.Run("should filter by created range", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		old := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		old.CreatedAt = time.Now().Add(-48 * time.Hour)
+		suite.fixtures.InsertTask(old)
+		recent := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		after := time.Now().Add(-24 * time.Hour)
+
+		tasks, _, err := suite.repo.List(suite.ctx, p, TaskListQuery{
+			Limit:        10,
+			CreatedAfter: &after,
+		})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(tasks))
+		suite.Require().Equal(recent, tasks[0].ID)
+	})
+	t.Run("should be invalid with malformed cursor", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, _, err := suite.repo.List(suite.ctx, p, TaskListQuery{
+			Limit:  10,
+			Cursor: "not-a-cursor",
+		})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should be invalid with unknown sort field", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, _, err := suite.repo.List(suite.ctx, p, TaskListQuery{
+			Limit:  10,
+			SortBy: "status; DROP TABLE tasks",
+		})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+}
+
 func (suite *taskRepositoryTestSuite) TestTaskUpdate() {
 	t := suite.T()
 
@@ -255,7 +523,7 @@ func (suite *taskRepositoryTestSuite) TestTaskUpdate() {
 		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectID, core.TASK_STATUS_UNASSIGNED))
 		newTitle := "New Title"
 
-		err := suite.repo.Update(suite.ctx, taskID, &newTitle, nil, nil)
+		err := suite.repo.Update(suite.ctx, projectID, taskID, &newTitle, nil, nil, nil, nil, nil, nil)
 
 		task, _ := gorm.G[models.Task](suite.db).Where("id = ?", taskID).First(suite.ctx)
 
@@ -269,7 +537,7 @@ func (suite *taskRepositoryTestSuite) TestTaskUpdate() {
 		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectID, core.TASK_STATUS_UNASSIGNED))
 		newDescription := "New Description"
 
-		err := suite.repo.Update(suite.ctx, taskID, nil, &newDescription, nil)
+		err := suite.repo.Update(suite.ctx, projectID, taskID, nil, &newDescription, nil, nil, nil, nil, nil)
 
 		task, _ := gorm.G[models.Task](suite.db).Where("id = ?", taskID).First(suite.ctx)
 
@@ -283,7 +551,7 @@ func (suite *taskRepositoryTestSuite) TestTaskUpdate() {
 		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectID, core.TASK_STATUS_UNASSIGNED))
 		newStatus := core.TASK_STATUS_COMPLETED
 
-		err := suite.repo.Update(suite.ctx, taskID, nil, nil, &newStatus)
+		err := suite.repo.UpdateStatus(suite.ctx, projectID, taskID, newStatus, nil)
 
 		task, _ := gorm.G[models.Task](suite.db).Where("id = ?", taskID).First(suite.ctx)
 
@@ -292,6 +560,65 @@ func (suite *taskRepositoryTestSuite) TestTaskUpdate() {
 		suite.Require().NoError(err)
 		suite.Require().Equal(newStatus, task.Status.String)
 	})
+	t.Run("should update task due date", func(t *testing.T) {
+		projectID := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectID, core.TASK_STATUS_UNASSIGNED))
+		dueDate := time.Now().AddDate(0, 0, 3).UTC().Truncate(time.Second)
+
+		err := suite.repo.Update(suite.ctx, projectID, taskID, nil, nil, nil, nil, nil, nil, &dueDate)
+
+		task, _ := gorm.G[models.Task](suite.db).Where("id = ?", taskID).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().NotNil(task.DueDate)
+		suite.Require().True(dueDate.Equal(*task.DueDate))
+		suite.Require().Nil(task.StartDate)
+	})
+}
+
+func (suite *taskRepositoryTestSuite) TestTaskBumpVersion() {
+	t := suite.T()
+
+	t.Run("should bump version at the given version", func(t *testing.T) {
+		projectID := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectID, core.TASK_STATUS_UNASSIGNED))
+		version := 1
+
+		err := suite.repo.BumpVersion(suite.ctx, projectID, taskID, &version)
+
+		task, _ := gorm.G[models.Task](suite.db).Where("id = ?", taskID).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, task.Version)
+	})
+	t.Run("should return precondition failed for stale version", func(t *testing.T) {
+		projectID := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectID, core.TASK_STATUS_UNASSIGNED))
+		version := 1
+		suite.repo.BumpVersion(suite.ctx, projectID, taskID, nil)
+
+		err := suite.repo.BumpVersion(suite.ctx, projectID, taskID, &version)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrPreconditionFailed)
+	})
+	t.Run("should return not found for task of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_UNASSIGNED))
+		version := 1
+
+		err := suite.repo.BumpVersion(suite.ctx, p2, taskID, &version)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
 }
 
 func (suite *taskRepositoryTestSuite) TestTaskRecentlyAssigned() {
@@ -348,3 +675,350 @@ func (suite *taskRepositoryTestSuite) TestTaskRecentlyUnassigned() {
 		suite.Require().Equal(2, len(tasks))
 	})
 }
+
+func (suite *taskRepositoryTestSuite) TestTaskDue() {
+	t := suite.T()
+
+	t.Run("should get overdue tasks of member projects", func(t *testing.T) {
+		projectID := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(projectID, USER_TWO, core.ROLE_MEMBER))
+		yesterday := time.Now().AddDate(0, 0, -1)
+		overdue := fixtures.RandomTaskRow(projectID, core.TASK_STATUS_ONGOING)
+		overdue.DueDate = &yesterday
+		suite.fixtures.InsertTask(overdue)
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectID, core.TASK_STATUS_ONGOING))
+
+		tasks, err := suite.repo.Due(suite.ctx, USER_TWO, nil, time.Now(), 10)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(tasks))
+		suite.Require().Equal(overdue.ID, tasks[0].ID)
+	})
+	t.Run("should not get completed tasks", func(t *testing.T) {
+		projectID := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		yesterday := time.Now().AddDate(0, 0, -1)
+		completed := fixtures.RandomTaskRow(projectID, core.TASK_STATUS_COMPLETED)
+		completed.DueDate = &yesterday
+		suite.fixtures.InsertTask(completed)
+
+		tasks, err := suite.repo.Due(suite.ctx, USER_ONE, nil, time.Now(), 10)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(0, len(tasks))
+	})
+	t.Run("should not get tasks of other projects", func(t *testing.T) {
+		projectID := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		yesterday := time.Now().AddDate(0, 0, -1)
+		overdue := fixtures.RandomTaskRow(projectID, core.TASK_STATUS_ONGOING)
+		overdue.DueDate = &yesterday
+		suite.fixtures.InsertTask(overdue)
+
+		tasks, err := suite.repo.Due(suite.ctx, USER_TWO, nil, time.Now(), 10)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(0, len(tasks))
+	})
+	t.Run("should get tasks due between the dates", func(t *testing.T) {
+		projectID := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		now := time.Now()
+		yesterday, tomorrow := now.AddDate(0, 0, -1), now.AddDate(0, 0, 1)
+		overdue := fixtures.RandomTaskRow(projectID, core.TASK_STATUS_ONGOING)
+		overdue.DueDate = &yesterday
+		suite.fixtures.InsertTask(overdue)
+		upcoming := fixtures.RandomTaskRow(projectID, core.TASK_STATUS_UNASSIGNED)
+		upcoming.DueDate = &tomorrow
+		suite.fixtures.InsertTask(upcoming)
+
+		tasks, err := suite.repo.Due(suite.ctx, USER_ONE, &now, now.AddDate(0, 0, 7), 10)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(tasks))
+		suite.Require().Equal(upcoming.ID, tasks[0].ID)
+	})
+}
+
+func (suite *taskRepositoryTestSuite) TestTaskSubtasks() {
+	t := suite.T()
+
+	t.Run("should get subtask counts of parent task", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		parentID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		for _, status := range []string{
+			core.TASK_STATUS_ONGOING,
+			core.TASK_STATUS_COMPLETED,
+			core.TASK_STATUS_ABANDONED,
+		} {
+			subtask := fixtures.RandomTaskRow(p, status)
+			subtask.ParentID = &parentID
+			suite.fixtures.InsertTask(subtask)
+		}
+
+		task, err := suite.repo.Get(suite.ctx, p, parentID)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Nil(task.ParentID)
+		suite.Require().Equal(int64(3), task.Subtasks)
+		suite.Require().Equal(int64(1), task.CompletedSubtasks)
+		suite.Require().Equal(int64(1), task.AbandonedSubtasks)
+	})
+	t.Run("should list only subtasks of parent task", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		parentID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		subtask := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		subtask.ParentID = &parentID
+		suite.fixtures.InsertTask(subtask)
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+
+		tasks, _, err := suite.repo.List(suite.ctx, p, TaskListQuery{
+			Limit:    10,
+			ParentID: &parentID,
+		})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(tasks))
+		suite.Require().Equal(subtask.ID, tasks[0].ID)
+		suite.Require().Equal(parentID, *tasks[0].ParentID)
+	})
+	t.Run("should get ancestors of subtask", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		rootID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		child := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		child.ParentID = &rootID
+		childID := suite.fixtures.InsertTask(child)
+		grandchild := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		grandchild.ParentID = &childID
+		grandchildID := suite.fixtures.InsertTask(grandchild)
+
+		ids, err := suite.repo.Ancestors(suite.ctx, p, grandchildID)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().ElementsMatch([]string{rootID, childID, grandchildID}, ids)
+	})
+	t.Run("should count ongoing subtasks", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		parentID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		for _, status := range []string{core.TASK_STATUS_ONGOING, core.TASK_STATUS_COMPLETED} {
+			subtask := fixtures.RandomTaskRow(p, status)
+			subtask.ParentID = &parentID
+			suite.fixtures.InsertTask(subtask)
+		}
+
+		count, err := suite.repo.CountSubtasks(suite.ctx, p, parentID, core.TASK_STATUS_ONGOING)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(int64(1), count)
+	})
+	t.Run("should detach subtask when parent task is deleted", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		parentID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		subtask := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		subtask.ParentID = &parentID
+		subtaskID := suite.fixtures.InsertTask(subtask)
+
+		err := suite.repo.Delete(suite.ctx, p, parentID)
+		task, getErr := suite.repo.Get(suite.ctx, p, subtaskID)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().NoError(getErr)
+		suite.Require().Nil(task.ParentID)
+	})
+}
+
+func (suite *taskRepositoryTestSuite) TestTaskDependencies() {
+	t := suite.T()
+
+	t.Run("should add blocker to task", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		blockerID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		err := suite.repo.AddBlocker(suite.ctx, taskID, blockerID)
+		isBlockerErr := suite.repo.IsBlocker(suite.ctx, taskID, blockerID)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().NoError(isBlockerErr)
+	})
+	t.Run("should get duplicate error when adding blocker twice", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		blockerID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(taskID, blockerID))
+
+		err := suite.repo.AddBlocker(suite.ctx, taskID, blockerID)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrDuplicate)
+	})
+	t.Run("should get blocked by and blocks of task", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		blockerID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(taskID, blockerID))
+
+		blockedBy, err := suite.repo.BlockedBy(suite.ctx, p, taskID)
+		blocks, _ := suite.repo.Blocks(suite.ctx, p, blockerID)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(blockedBy))
+		suite.Require().Equal(blockerID, blockedBy[0].ID)
+		suite.Require().Equal(core.TASK_STATUS_ONGOING, blockedBy[0].Status.String)
+		suite.Require().Equal(1, len(blocks))
+		suite.Require().Equal(taskID, blocks[0].ID)
+	})
+	t.Run("should get transitive blockers of task", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		first := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		second := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		third := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(first, second))
+		suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(second, third))
+
+		ids, err := suite.repo.TransitiveBlockers(suite.ctx, first)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().ElementsMatch([]string{first, second, third}, ids)
+	})
+	t.Run("should count only open blockers", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		for _, status := range []string{
+			core.TASK_STATUS_ONGOING,
+			core.TASK_STATUS_COMPLETED,
+			core.TASK_STATUS_ABANDONED,
+		} {
+			blockerID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, status))
+			suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(taskID, blockerID))
+		}
+
+		count, err := suite.repo.CountOpenBlockers(suite.ctx, p, taskID)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(int64(1), count)
+	})
+	t.Run("should get not found error when removing unknown blocker", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+
+		err := suite.repo.RemoveBlocker(suite.ctx, taskID, "unknown-task-id")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+	t.Run("should remove dependencies of deleted blocker", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		blockerID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(taskID, blockerID))
+
+		err := suite.repo.Delete(suite.ctx, p, blockerID)
+		blockedBy, _ := suite.repo.BlockedBy(suite.ctx, p, taskID)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(0, len(blockedBy))
+	})
+}
+
+func (suite *taskRepositoryTestSuite) TestTaskDelete() {
+	t := suite.T()
+
+	t.Run("should delete task", func(t *testing.T) {
+		projectID := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectID, core.TASK_STATUS_UNASSIGNED))
+
+		err := suite.repo.Delete(suite.ctx, projectID, taskID)
+
+		_, getErr := suite.repo.Get(suite.ctx, projectID, taskID)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().ErrorIs(getErr, core.ErrNotFound)
+	})
+	t.Run("should not delete task of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_UNASSIGNED))
+
+		err := suite.repo.Delete(suite.ctx, p2, taskID)
+
+		_, getErr := suite.repo.Get(suite.ctx, p1, taskID)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+		suite.Require().NoError(getErr)
+	})
+}
+
+func (suite *taskRepositoryTestSuite) TestCrossProjectAccess() {
+	t := suite.T()
+
+	t.Run("should not get task of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_TWO))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_UNASSIGNED))
+
+		_, err := suite.repo.Get(suite.ctx, p2, taskID)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+	t.Run("should not update task of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_TWO))
+		row := fixtures.RandomTaskRow(p1, core.TASK_STATUS_UNASSIGNED)
+		taskID := suite.fixtures.InsertTask(row)
+		title := "Hijacked"
+
+		err := suite.repo.Update(suite.ctx, p2, taskID, &title, nil, nil, nil, nil, nil, nil)
+
+		task, _ := gorm.G[models.Task](suite.db).Where("id = ?", taskID).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+		suite.Require().Equal(row.Title, task.Title)
+	})
+	t.Run("should not find task of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_TWO))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_UNASSIGNED))
+
+		err := suite.repo.Exists(suite.ctx, p2, taskID)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
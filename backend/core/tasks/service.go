@@ -2,103 +2,482 @@ package tasks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/ptracker/activity"
 	"github.com/ptracker/core"
 	"github.com/ptracker/core/assignees"
+	"github.com/ptracker/core/events"
+	"github.com/ptracker/core/labels"
 	"github.com/ptracker/core/members"
+	"github.com/ptracker/core/workflows"
+	"github.com/ptracker/models"
+	"github.com/ptracker/outbox"
+	"gorm.io/gorm"
 )
 
 type ProjectTaskItem struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description *string   `json:"description"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description *string    `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	Estimate    int        `json:"estimate"`
+	StartDate   *time.Time `json:"start_date"`
+	DueDate     *time.Time `json:"due_date"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Version     int        `json:"version"`
+
+	// custom status of the project workflow, Status is its category
+	WorkflowStatus *string `json:"workflow_status"`
+
+	// Progress is the percentage of completed subtasks, the abandoned
+	// subtasks are left out of it
+	ParentID          *string `json:"parent_id"`
+	Subtasks          int64   `json:"subtasks"`
+	CompletedSubtasks int64   `json:"completed_subtasks"`
+	Progress          int     `json:"progress"`
 
 	ProjectID string               `json:"project_id"`
 	Assignees []assignees.Assignee `json:"assignees"`
+	Labels    []labels.Label       `json:"labels"`
+
+	// only filled in for the task detail
+	BlockedBy []DependencyTask `json:"blocked_by,omitempty"`
+	Blocks    []DependencyTask `json:"blocks,omitempty"`
+}
+
+/*
+Changes of a task applied together by Update
+
+Nil fields are left as they are, an empty ParentID moves the task to the
+top level.
+*/
+type TaskUpdate struct {
+	Title       *string
+	Description *string
+	Status      *string
+	Priority    *string
+	Estimate    *int
+	ParentID    *string
+	StartDate   *time.Time
+	DueDate     *time.Time
+
+	// removes the date, can not be given along with the date
+	ClearStartDate bool
+	ClearDueDate   bool
+
+	AssigneesToAdd    []string
+	AssigneesToRemove []string
+	LabelsToAdd       []string
+	LabelsToRemove    []string
+	BlockersToAdd     []string
+	BlockersToRemove  []string
+
+	// completes the task even if some of its subtasks are still Ongoing
+	Force bool
+
+	// version the task is expected to be at, nil skips the check
+	Version *int
+}
+
+// Task after an update, with the fields and relations that changed
+type UpdatedTask struct {
+	Task    *ProjectTaskItem `json:"task"`
+	Changed []string         `json:"changed"`
+}
+
+type DependencyTask struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
 }
 
 type DashboardTaskItem struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description *string   `json:"description"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description *string    `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	Estimate    int        `json:"estimate"`
+	StartDate   *time.Time `json:"start_date"`
+	DueDate     *time.Time `json:"due_date"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 
 	ProjectID   string `json:"project_id"`
 	ProjectName string `json:"project_name"`
 }
 
+/*
+Snapshot of a task taken right before it was deleted
+
+Used to inform the assignees, the task and its assignees no longer exist
+when the notifications are sent.
+*/
+type DeletedTask struct {
+	ID          string
+	ProjectID   string
+	Title       string
+	AssigneeIDs []string
+}
+
+/*
+Open tasks of a user with a due date, split by whether the due date is
+already past or falls within the next 7 days
+*/
+type DueTasks struct {
+	Overdue     []DashboardTaskItem `json:"overdue"`
+	DueThisWeek []DashboardTaskItem `json:"due_this_week"`
+}
+
+// Returns the percentage of the completed subtasks, leaving out the abandoned ones
+func progress(row ProjectTaskItemRow) int {
+	total := row.Subtasks - row.AbandonedSubtasks
+	if total <= 0 {
+		return 0
+	}
+
+	return int(row.CompletedSubtasks * 100 / total)
+}
+
+// Change of a task field, recorded in the task history
+type fieldChange struct {
+	field    string
+	oldValue *string
+	newValue *string
+}
+
+// Appends the change of the field unless the value stays the same
+func appendChange(changes []fieldChange,
+	field string, oldValue, newValue *string) []fieldChange {
+
+	if (oldValue == nil && newValue == nil) ||
+		(oldValue != nil && newValue != nil && *oldValue == *newValue) {
+		return changes
+	}
+
+	return append(changes, fieldChange{
+		field:    field,
+		oldValue: oldValue,
+		newValue: newValue,
+	})
+}
+
+// Reports whether any id shows up more than once across the lists
+func repeats(lists ...[]string) bool {
+	seen := map[string]bool{}
+	for _, list := range lists {
+		for _, id := range list {
+			if seen[id] {
+				return true
+			}
+			seen[id] = true
+		}
+	}
+
+	return false
+}
+
+// Returns the RFC3339 form of the time, nil for no time
+// Date written by an update, a zero date clears it
+func updatedDate(date *time.Time, clear bool) *time.Time {
+	if clear {
+		return &time.Time{}
+	}
+
+	return date
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
+
 type TaskService struct {
+	txManager    *core.TxManager
 	taskRepo     *TaskRepository
 	memberRepo   *members.MemberRepository
 	assigneeRepo *assignees.AssigneeRepository
+	labelRepo    *labels.LabelRepository
+	workflowRepo *workflows.WorkflowRepository
+	eventRepo    *events.EventRepository
+	activityRepo *activity.ActivityRepository
+	outboxRepo   *outbox.OutboxRepository
 }
 
-func NewTaskService(taskRepo *TaskRepository,
+func NewTaskService(txManager *core.TxManager,
+	taskRepo *TaskRepository,
 	memberRepo *members.MemberRepository,
-	assigneeRepo *assignees.AssigneeRepository) *TaskService {
+	assigneeRepo *assignees.AssigneeRepository,
+	labelRepo *labels.LabelRepository,
+	workflowRepo *workflows.WorkflowRepository,
+	eventRepo *events.EventRepository,
+	activityRepo *activity.ActivityRepository,
+	outboxRepo *outbox.OutboxRepository) *TaskService {
 	return &TaskService{
+		txManager:    txManager,
 		taskRepo:     taskRepo,
 		memberRepo:   memberRepo,
 		assigneeRepo: assigneeRepo,
+		labelRepo:    labelRepo,
+		workflowRepo: workflowRepo,
+		eventRepo:    eventRepo,
+		activityRepo: activityRepo,
+		outboxRepo:   outboxRepo,
+	}
+}
+
+/*
+Maps the requested status onto the built-in status and the custom status
+of the task
+
+Projects with a workflow only take its custom statuses, the other projects
+the built-in ones.
+*/
+func (s *TaskService) resolveStatus(ctx context.Context,
+	projectID, status string) (string, *string, error) {
+
+	statuses, err := s.workflowRepo.ListStatuses(ctx, projectID)
+	if err != nil {
+		return "", nil, fmt.Errorf("workflow repository list statuses: %w", err)
 	}
+
+	if len(statuses) == 0 {
+		if !slices.Contains([]string{
+			core.TASK_STATUS_UNASSIGNED,
+			core.TASK_STATUS_ONGOING,
+			core.TASK_STATUS_COMPLETED,
+			core.TASK_STATUS_ABANDONED,
+		}, status) {
+			return "", nil, core.ErrInvalidValue
+		}
+
+		return status, nil, nil
+	}
+
+	for _, ws := range statuses {
+		if ws.Name == status {
+			return ws.Category.String, &ws.Name, nil
+		}
+	}
+
+	return "", nil, core.ErrInvalidValue
 }
 
 func (s *TaskService) Create(ctx context.Context,
 	projectID, userID string,
-	title, description, status string) (string, error) {
+	title, description, status, priority string,
+	estimate int,
+	parentID *string,
+	startDate, dueDate *time.Time,
+	assigneeIDs []string) (string, error) {
 
 	var err error
 
-	err = core.NeedsToBeAnOwner(ctx, s.memberRepo, projectID, userID)
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_TASKS)
 	if err != nil {
-		return "", fmt.Errorf("needs to be an owner: %w", err)
+		return "", fmt.Errorf("authorize manage tasks: %w", err)
+	}
+
+	if len(assigneeIDs) > 0 {
+		err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+			core.PERMISSION_MANAGE_ASSIGNEES)
+		if err != nil {
+			return "", fmt.Errorf("authorize manage assignees: %w", err)
+		}
+	}
+
+	if repeats(assigneeIDs) {
+		return "", core.ErrInvalidValue
 	}
 
 	if strings.Trim(title, " ") == "" {
 		return "", core.ErrInvalidValue
 	}
 
+	category, workflowStatus, err := s.resolveStatus(ctx, projectID, status)
+	if err != nil {
+		return "", fmt.Errorf("resolve status: %w", err)
+	}
+
+	if priority == "" {
+		priority = core.TASK_PRIORITY_MEDIUM
+	}
 	if !slices.Contains([]string{
-		core.TASK_STATUS_UNASSIGNED,
-		core.TASK_STATUS_ONGOING,
-		core.TASK_STATUS_COMPLETED,
-		core.TASK_STATUS_ABANDONED,
-	}, status) {
+		core.TASK_PRIORITY_LOW,
+		core.TASK_PRIORITY_MEDIUM,
+		core.TASK_PRIORITY_HIGH,
+		core.TASK_PRIORITY_URGENT,
+	}, priority) {
+		return "", core.ErrInvalidValue
+	}
+
+	if estimate < 0 {
+		return "", core.ErrInvalidValue
+	}
+
+	if startDate != nil && dueDate != nil && startDate.After(*dueDate) {
 		return "", core.ErrInvalidValue
 	}
 
-	taskID, err := s.taskRepo.Create(ctx,
-		projectID,
-		title, description, status)
+	if parentID != nil && *parentID == "" {
+		parentID = nil
+	}
+	if parentID != nil {
+		err = s.taskRepo.Exists(ctx, projectID, *parentID)
+		if errors.Is(err, core.ErrNotFound) {
+			return "", core.ErrInvalidValue
+		} else if err != nil {
+			return "", fmt.Errorf("task repository exists: %w", err)
+		}
+	}
+
+	var taskID string
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		var err error
+
+		taskID, err = s.taskRepo.WithTx(tx).Create(ctx,
+			projectID,
+			title, description, category, workflowStatus, priority,
+			estimate,
+			parentID,
+			startDate, dueDate)
+		if err != nil {
+			return fmt.Errorf("task repository create: %w", err)
+		}
+
+		err = s.eventRepo.WithTx(tx).Create(ctx, projectID, taskID, userID,
+			core.TASK_EVENT_CREATED, nil, nil, &title)
+		if err != nil {
+			return fmt.Errorf("event repository create: %w", err)
+		}
+
+		err = s.activityRepo.WithTx(tx).TaskCreated(ctx, projectID, taskID, userID)
+		if err != nil {
+			return fmt.Errorf("activity repository task created: %w", err)
+		}
+
+		outboxRepo := s.outboxRepo.WithTx(tx)
+
+		// the task is created along with its assignees or not at all
+		field := "assignee"
+		for _, assigneeID := range assigneeIDs {
+			err = s.assigneeRepo.WithTx(tx).Create(ctx, projectID, taskID, assigneeID)
+			if err != nil {
+				return fmt.Errorf("assignee repository create: %w", err)
+			}
+
+			err = s.eventRepo.WithTx(tx).Create(ctx, projectID, taskID, userID,
+				core.TASK_EVENT_ASSIGNEE_ADDED, &field, nil, &assigneeID)
+			if err != nil {
+				return fmt.Errorf("event repository create: %w", err)
+			}
+
+			err = outboxRepo.Create(ctx, outbox.EV_ASSIGNEE_UPDATED, outbox.AssigneeUpdated{
+				ProjectID:  projectID,
+				TaskID:     taskID,
+				AssigneeID: assigneeID,
+				Added:      true,
+			})
+			if err != nil {
+				return fmt.Errorf("outbox repository create assignee updated: %w", err)
+			}
+		}
+
+		err = outboxRepo.Create(ctx, outbox.EV_TASK_ADDED, outbox.TaskAdded{
+			ProjectID: projectID,
+			TaskID:    taskID,
+			CreatorID: userID,
+		})
+		if err != nil {
+			return fmt.Errorf("outbox repository create task added: %w", err)
+		}
+
+		if strings.Contains(description, "@") {
+			err = outboxRepo.Create(ctx, outbox.EV_MENTIONED, outbox.Mentioned{
+				ProjectID:   projectID,
+				TaskID:      taskID,
+				SourceID:    taskID,
+				MentionerID: userID,
+				Content:     description,
+			})
+			if err != nil {
+				return fmt.Errorf("outbox repository create mentioned: %w", err)
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("task repository create: %w", err)
+		return "", fmt.Errorf("txManager WithTx: %w", err)
 	}
 
 	return taskID, nil
 }
 
 func (s *TaskService) List(ctx context.Context,
-	projectID, userID string) ([]ProjectTaskItem, error) {
+	projectID, userID string,
+	query TaskListQuery) ([]ProjectTaskItem, string, error) {
 
 	var err error
 
-	err = core.NeedsToBeAMember(ctx, s.memberRepo, projectID, userID)
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_VIEW_PROJECT)
 	if err != nil {
-		return nil, fmt.Errorf("needs to be a member: %w", err)
+		return nil, "", fmt.Errorf("authorize view project: %w", err)
 	}
 
-	rows, err := s.taskRepo.List(ctx, projectID)
+	// a status is either a built-in category or one of the project workflow
+	categories := []string{}
+	for _, status := range query.Statuses {
+		if slices.Contains([]string{
+			core.TASK_STATUS_UNASSIGNED,
+			core.TASK_STATUS_ONGOING,
+			core.TASK_STATUS_COMPLETED,
+			core.TASK_STATUS_ABANDONED,
+		}, status) {
+			categories = append(categories, status)
+			continue
+		}
+
+		_, workflowStatus, err := s.resolveStatus(ctx, projectID, status)
+		if err != nil {
+			return nil, "", fmt.Errorf("resolve status: %w", err)
+		}
+		query.WorkflowStatuses = append(query.WorkflowStatuses, *workflowStatus)
+	}
+	query.Statuses = categories
+
+	for _, priority := range query.Priorities {
+		if !slices.Contains([]string{
+			core.TASK_PRIORITY_LOW,
+			core.TASK_PRIORITY_MEDIUM,
+			core.TASK_PRIORITY_HIGH,
+			core.TASK_PRIORITY_URGENT,
+		}, priority) {
+			return nil, "", core.ErrInvalidValue
+		}
+	}
+
+	if query.Limit <= 0 || query.Limit > core.MAX_LIST_LIMIT {
+		return nil, "", core.ErrInvalidValue
+	}
+
+	rows, nextCursor, err := s.taskRepo.List(ctx, projectID, query)
 	if err != nil {
-		return nil, fmt.Errorf("task repository list: %w", err)
+		return nil, "", fmt.Errorf("task repository list: %w", err)
 	}
 
 	tasks := []ProjectTaskItem{}
@@ -109,9 +488,21 @@ func (s *TaskService) List(ctx context.Context,
 			Title:       r.Title,
 			Description: r.Description,
 			Status:      r.Status.String,
+			Priority:    r.Priority.String,
+			Estimate:    r.Estimate,
+			StartDate:   r.StartDate,
+			DueDate:     r.DueDate,
 			CreatedAt:   r.CreatedAt,
 			UpdatedAt:   r.UpdatedAt,
 			Assignees:   []assignees.Assignee{},
+			Labels:      []labels.Label{},
+
+			Version:           r.Version,
+			WorkflowStatus:    r.WorkflowStatus,
+			ParentID:          r.ParentID,
+			Subtasks:          r.Subtasks,
+			CompletedSubtasks: r.CompletedSubtasks,
+			Progress:          progress(r),
 		}
 		for _, assignee := range r.Assignees {
 			task.Assignees = append(task.Assignees, assignees.Assignee{
@@ -128,11 +519,14 @@ func (s *TaskService) List(ctx context.Context,
 				},
 			})
 		}
+		for _, label := range r.Labels {
+			task.Labels = append(task.Labels, labels.Label(label))
+		}
 
 		tasks = append(tasks, task)
 	}
 
-	return tasks, nil
+	return tasks, nextCursor, nil
 }
 
 func (s *TaskService) Get(ctx context.Context,
@@ -140,12 +534,13 @@ func (s *TaskService) Get(ctx context.Context,
 
 	var err error
 
-	err = core.NeedsToBeAMember(ctx, s.memberRepo, projectID, userID)
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_VIEW_PROJECT)
 	if err != nil {
-		return nil, fmt.Errorf("needs to be a member: %w", err)
+		return nil, fmt.Errorf("authorize view project: %w", err)
 	}
 
-	row, err := s.taskRepo.Get(ctx, taskID)
+	row, err := s.taskRepo.Get(ctx, projectID, taskID)
 	if err != nil {
 		return nil, fmt.Errorf("task repository get: %w", err)
 	}
@@ -155,9 +550,21 @@ func (s *TaskService) Get(ctx context.Context,
 		Title:       row.Title,
 		Description: row.Description,
 		Status:      row.Status.String,
+		Priority:    row.Priority.String,
+		Estimate:    row.Estimate,
+		StartDate:   row.StartDate,
+		DueDate:     row.DueDate,
 		CreatedAt:   row.CreatedAt,
 		UpdatedAt:   row.UpdatedAt,
 		Assignees:   []assignees.Assignee{},
+		Labels:      []labels.Label{},
+
+		Version:           row.Version,
+		WorkflowStatus:    row.WorkflowStatus,
+		ParentID:          row.ParentID,
+		Subtasks:          row.Subtasks,
+		CompletedSubtasks: row.CompletedSubtasks,
+		Progress:          progress(row),
 	}
 	for _, assignee := range row.Assignees {
 		task.Assignees = append(task.Assignees, assignees.Assignee{
@@ -174,61 +581,546 @@ func (s *TaskService) Get(ctx context.Context,
 			},
 		})
 	}
+	for _, label := range row.Labels {
+		task.Labels = append(task.Labels, labels.Label(label))
+	}
+
+	blockedBy, err := s.taskRepo.BlockedBy(ctx, projectID, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("task repository blocked by: %w", err)
+	}
+	blocks, err := s.taskRepo.Blocks(ctx, projectID, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("task repository blocks: %w", err)
+	}
+
+	task.BlockedBy = []DependencyTask{}
+	for _, r := range blockedBy {
+		task.BlockedBy = append(task.BlockedBy, DependencyTask{
+			ID:     r.ID,
+			Title:  r.Title,
+			Status: r.Status.String,
+		})
+	}
+	task.Blocks = []DependencyTask{}
+	for _, r := range blocks {
+		task.Blocks = append(task.Blocks, DependencyTask{
+			ID:     r.ID,
+			Title:  r.Title,
+			Status: r.Status.String,
+		})
+	}
 
 	return &task, nil
 }
 
 func (s *TaskService) Update(ctx context.Context,
 	projectID, taskID, userID string,
-	title, description, status *string) error {
+	update TaskUpdate) (*UpdatedTask, error) {
 
 	var err error
 
-	err = core.NeedsToBeAnOwner(ctx, s.memberRepo, projectID, userID)
+	hasFields := update.Title != nil || update.Description != nil ||
+		update.Status != nil || update.Priority != nil ||
+		update.Estimate != nil || update.ParentID != nil ||
+		update.StartDate != nil || update.DueDate != nil ||
+		update.ClearStartDate || update.ClearDueDate
+	hasAssignees := len(update.AssigneesToAdd) > 0 || len(update.AssigneesToRemove) > 0
+	hasLabels := len(update.LabelsToAdd) > 0 || len(update.LabelsToRemove) > 0
+	hasBlockers := len(update.BlockersToAdd) > 0 || len(update.BlockersToRemove) > 0
+
+	if !hasFields && !hasAssignees && !hasLabels && !hasBlockers {
+		return nil, core.ErrInvalidValue
+	}
+
+	// maintainers update any task, members only the tasks assigned to them
+	if hasFields {
+		err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+			core.PERMISSION_MANAGE_TASKS)
+		if err != nil {
+			err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+				core.PERMISSION_EDIT_ASSIGNED_TASKS)
+			if err != nil {
+				return nil, fmt.Errorf("authorize edit assigned tasks: %w", err)
+			}
+
+			err = core.NeedsToBeAnAssignee(ctx, s.assigneeRepo, projectID, taskID, userID)
+			if err != nil {
+				return nil, fmt.Errorf("needs to be an assignee: %w", err)
+			}
+		}
+	}
+
+	if hasAssignees {
+		err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+			core.PERMISSION_MANAGE_ASSIGNEES)
+		if err != nil {
+			return nil, fmt.Errorf("authorize manage assignees: %w", err)
+		}
+	}
+
+	if hasLabels || hasBlockers {
+		err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+			core.PERMISSION_MANAGE_TASKS)
+		if err != nil {
+			return nil, fmt.Errorf("authorize manage tasks: %w", err)
+		}
+	}
+
+	row, err := s.taskRepo.Get(ctx, projectID, taskID)
 	if err != nil {
-		err = core.NeedsToBeAnAssignee(ctx, s.assigneeRepo, projectID, taskID, userID)
+		return nil, fmt.Errorf("task repository get: %w", err)
+	}
+
+	if update.Version != nil && *update.Version != row.Version {
+		return nil, core.ErrPreconditionFailed
+	}
+
+	var category string
+	var workflowStatus *string
+	if update.Status != nil {
+		category, workflowStatus, err = s.resolveStatus(ctx, projectID, *update.Status)
 		if err != nil {
-			return fmt.Errorf("needs to be an owner or assignee: %w", err)
+			return nil, fmt.Errorf("resolve status: %w", err)
+		}
+
+		// tasks move between the custom statuses along the workflow transitions,
+		// tasks created before the workflow can move to any status
+		if row.WorkflowStatus != nil && workflowStatus != nil &&
+			*row.WorkflowStatus != *workflowStatus {
+			transitions, err := s.workflowRepo.ListTransitions(ctx, projectID)
+			if err != nil {
+				return nil, fmt.Errorf("workflow repository list transitions: %w", err)
+			}
+
+			if len(transitions) > 0 && !slices.ContainsFunc(transitions,
+				func(t models.WorkflowTransition) bool {
+					return t.FromStatus == *row.WorkflowStatus && t.ToStatus == *workflowStatus
+				}) {
+				return nil, core.ErrInvalidValue
+			}
+		}
+
+		// completing a parent needs force while its subtasks are in progress
+		if category == core.TASK_STATUS_COMPLETED && !update.Force {
+			ongoing, err := s.taskRepo.CountSubtasks(ctx, projectID, taskID,
+				core.TASK_STATUS_ONGOING)
+			if err != nil {
+				return nil, fmt.Errorf("task repository count subtasks: %w", err)
+			}
+			if ongoing > 0 {
+				return nil, core.ErrConflict
+			}
 		}
 	}
 
-	if title == nil && description == nil && status == nil {
-		return core.ErrInvalidValue
+	if update.Priority != nil && !slices.Contains([]string{
+		core.TASK_PRIORITY_LOW,
+		core.TASK_PRIORITY_MEDIUM,
+		core.TASK_PRIORITY_HIGH,
+		core.TASK_PRIORITY_URGENT,
+	}, *update.Priority) {
+		return nil, core.ErrInvalidValue
 	}
 
-	if title != nil {
-		err = s.taskRepo.Update(ctx, taskID, title, nil, nil)
+	if update.Estimate != nil && *update.Estimate < 0 {
+		return nil, core.ErrInvalidValue
+	}
+
+	if update.ParentID != nil && *update.ParentID != "" {
+		err = s.taskRepo.Exists(ctx, projectID, *update.ParentID)
+		if errors.Is(err, core.ErrNotFound) {
+			return nil, core.ErrInvalidValue
+		} else if err != nil {
+			return nil, fmt.Errorf("task repository exists: %w", err)
+		}
+
+		// the task can not be moved under itself or any of its subtasks
+		ancestors, err := s.taskRepo.Ancestors(ctx, projectID, *update.ParentID)
 		if err != nil {
-			return fmt.Errorf("task repository update title: %w", err)
+			return nil, fmt.Errorf("task repository ancestors: %w", err)
+		}
+		if slices.Contains(ancestors, taskID) {
+			return nil, core.ErrInvalidValue
 		}
 	}
 
-	if description != nil {
-		err = s.taskRepo.Update(ctx, taskID, nil, description, nil)
+	if (update.ClearStartDate && update.StartDate != nil) ||
+		(update.ClearDueDate && update.DueDate != nil) {
+		return nil, core.ErrInvalidValue
+	}
+	startDate := updatedDate(update.StartDate, update.ClearStartDate)
+	dueDate := updatedDate(update.DueDate, update.ClearDueDate)
+
+	// the start date can not move past the due date, including the one
+	// already stored when only one of them changes
+	if update.StartDate != nil || update.DueDate != nil {
+		start, due := row.StartDate, row.DueDate
+		if startDate != nil {
+			start = update.StartDate
+		}
+		if dueDate != nil {
+			due = update.DueDate
+		}
+		if start != nil && due != nil && start.After(*due) {
+			return nil, core.ErrInvalidValue
+		}
+	}
+
+	// a user, label or blocker is either added or removed, and only once
+	if repeats(update.AssigneesToAdd, update.AssigneesToRemove) ||
+		repeats(update.LabelsToAdd, update.LabelsToRemove) ||
+		repeats(update.BlockersToAdd, update.BlockersToRemove) {
+		return nil, core.ErrInvalidValue
+	}
+
+	for _, assigneeID := range update.AssigneesToAdd {
+		if err = s.assigneeRepo.Is(ctx, projectID, taskID, assigneeID); err == nil {
+			return nil, core.ErrDuplicate
+		}
+	}
+	for _, assigneeID := range update.AssigneesToRemove {
+		if err = s.assigneeRepo.Is(ctx, projectID, taskID, assigneeID); err != nil {
+			return nil, fmt.Errorf("assignee repository is: %w", err)
+		}
+	}
+
+	for _, labelID := range update.LabelsToAdd {
+		// labels of other projects can not be put on the task
+		_, err = s.labelRepo.Get(ctx, projectID, labelID)
 		if err != nil {
-			return fmt.Errorf("task repository update description: %w", err)
+			return nil, fmt.Errorf("label repository get: %w", err)
+		}
+
+		if err = s.labelRepo.IsOnTask(ctx, taskID, labelID); err == nil {
+			return nil, core.ErrDuplicate
+		}
+	}
+	for _, labelID := range update.LabelsToRemove {
+		if err = s.labelRepo.IsOnTask(ctx, taskID, labelID); err != nil {
+			return nil, fmt.Errorf("label repository is on task: %w", err)
 		}
 	}
 
-	if status != nil {
-		if !slices.Contains([]string{
-			core.TASK_STATUS_UNASSIGNED,
-			core.TASK_STATUS_ONGOING,
-			core.TASK_STATUS_COMPLETED,
-			core.TASK_STATUS_ABANDONED,
-		}, *status) {
-			return core.ErrInvalidValue
+	for _, blockerID := range update.BlockersToAdd {
+		err = s.validateBlocker(ctx, projectID, taskID, blockerID)
+		if err != nil {
+			return nil, fmt.Errorf("validate blocker: %w", err)
 		}
+	}
+	for _, blockerID := range update.BlockersToRemove {
+		if err = s.taskRepo.IsBlocker(ctx, taskID, blockerID); err != nil {
+			return nil, fmt.Errorf("task repository is blocker: %w", err)
+		}
+	}
 
-		err = s.taskRepo.Update(ctx, taskID, nil, nil, status)
+	// every changed field is recorded in the task history
+	changes := []fieldChange{}
+	if update.Title != nil {
+		changes = appendChange(changes, "title", &row.Title, update.Title)
+	}
+	if update.Description != nil {
+		changes = appendChange(changes, "description", row.Description, update.Description)
+	}
+	if update.Status != nil {
+		oldStatus := row.Status.String
+		if row.WorkflowStatus != nil {
+			oldStatus = *row.WorkflowStatus
+		}
+		changes = appendChange(changes, "status", &oldStatus, update.Status)
+	}
+	if update.Priority != nil {
+		changes = appendChange(changes, "priority", &row.Priority.String, update.Priority)
+	}
+	if update.Estimate != nil {
+		oldEstimate, newEstimate := strconv.Itoa(row.Estimate), strconv.Itoa(*update.Estimate)
+		changes = appendChange(changes, "estimate", &oldEstimate, &newEstimate)
+	}
+	if update.ParentID != nil {
+		newParent := update.ParentID
+		if *update.ParentID == "" {
+			newParent = nil
+		}
+		changes = appendChange(changes, "parent_id", row.ParentID, newParent)
+	}
+	if startDate != nil {
+		changes = appendChange(changes, "start_date",
+			formatTime(row.StartDate), formatTime(update.StartDate))
+	}
+	if dueDate != nil {
+		changes = appendChange(changes, "due_date",
+			formatTime(row.DueDate), formatTime(update.DueDate))
+	}
+
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		taskRepo := s.taskRepo.WithTx(tx)
+		assigneeRepo := s.assigneeRepo.WithTx(tx)
+		labelRepo := s.labelRepo.WithTx(tx)
+		eventRepo := s.eventRepo.WithTx(tx)
+
+		// the version is checked again while writing, the task may have been
+		// updated since it was read
+		err := taskRepo.BumpVersion(ctx, projectID, taskID, update.Version)
 		if err != nil {
-			return fmt.Errorf("task repository update status: %w", err)
+			return fmt.Errorf("task repository bump version: %w", err)
+		}
+
+		if update.Title != nil || update.Description != nil || update.Priority != nil ||
+			update.Estimate != nil || update.ParentID != nil ||
+			startDate != nil || dueDate != nil {
+			err := taskRepo.Update(ctx, projectID, taskID,
+				update.Title, update.Description, update.Priority,
+				update.Estimate,
+				update.ParentID,
+				startDate, dueDate)
+			if err != nil {
+				return fmt.Errorf("task repository update: %w", err)
+			}
+		}
+
+		if update.Status != nil {
+			err := taskRepo.UpdateStatus(ctx, projectID, taskID, category, workflowStatus)
+			if err != nil {
+				return fmt.Errorf("task repository update status: %w", err)
+			}
+		}
+
+		for _, change := range changes {
+			err := eventRepo.Create(ctx, projectID, taskID, userID,
+				core.TASK_EVENT_UPDATED, &change.field, change.oldValue, change.newValue)
+			if err != nil {
+				return fmt.Errorf("event repository create: %w", err)
+			}
+		}
+
+		field := "assignee"
+		for _, assigneeID := range update.AssigneesToAdd {
+			err := assigneeRepo.Create(ctx, projectID, taskID, assigneeID)
+			if err != nil {
+				return fmt.Errorf("assignee repository create: %w", err)
+			}
+
+			err = eventRepo.Create(ctx, projectID, taskID, userID,
+				core.TASK_EVENT_ASSIGNEE_ADDED, &field, nil, &assigneeID)
+			if err != nil {
+				return fmt.Errorf("event repository create: %w", err)
+			}
+		}
+		for _, assigneeID := range update.AssigneesToRemove {
+			err := assigneeRepo.Delete(ctx, projectID, taskID, assigneeID)
+			if err != nil {
+				return fmt.Errorf("assignee repository delete: %w", err)
+			}
+
+			err = eventRepo.Create(ctx, projectID, taskID, userID,
+				core.TASK_EVENT_ASSIGNEE_REMOVED, &field, &assigneeID, nil)
+			if err != nil {
+				return fmt.Errorf("event repository create: %w", err)
+			}
+		}
+
+		for _, labelID := range update.LabelsToAdd {
+			err := labelRepo.AddToTask(ctx, taskID, labelID)
+			if err != nil {
+				return fmt.Errorf("label repository add to task: %w", err)
+			}
+		}
+		for _, labelID := range update.LabelsToRemove {
+			err := labelRepo.RemoveFromTask(ctx, taskID, labelID)
+			if err != nil {
+				return fmt.Errorf("label repository remove from task: %w", err)
+			}
+		}
+
+		for _, blockerID := range update.BlockersToAdd {
+			err := taskRepo.AddBlocker(ctx, taskID, blockerID)
+			if err != nil {
+				return fmt.Errorf("task repository add blocker: %w", err)
+			}
+		}
+		for _, blockerID := range update.BlockersToRemove {
+			err := taskRepo.RemoveBlocker(ctx, taskID, blockerID)
+			if err != nil {
+				return fmt.Errorf("task repository remove blocker: %w", err)
+			}
+		}
+
+		// the task can not start while any of its blockers is still open,
+		// counted once the blockers of the update are written
+		if update.Status != nil && category == core.TASK_STATUS_ONGOING {
+			open, err := taskRepo.CountOpenBlockers(ctx, projectID, taskID)
+			if err != nil {
+				return fmt.Errorf("task repository count open blockers: %w", err)
+			}
+			if open > 0 {
+				return core.ErrConflict
+			}
+		}
+
+		return s.writeUpdateEvents(ctx, tx, projectID, taskID, userID, update, changes)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("txManager WithTx: %w", err)
+	}
+
+	changed := []string{}
+	for _, change := range changes {
+		changed = append(changed, change.field)
+	}
+	if hasAssignees {
+		changed = append(changed, "assignees")
+	}
+	if hasLabels {
+		changed = append(changed, "labels")
+	}
+	if hasBlockers {
+		changed = append(changed, "blockers")
+	}
+
+	task, err := s.Get(ctx, projectID, taskID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+
+	return &UpdatedTask{
+		Task:    task,
+		Changed: changed,
+	}, nil
+}
+
+// Writes the outbox events and the activity of the update in the
+// transaction tx
+func (s *TaskService) writeUpdateEvents(ctx context.Context,
+	tx *gorm.DB,
+	projectID, taskID, userID string,
+	update TaskUpdate,
+	changes []fieldChange) error {
+
+	outboxRepo := s.outboxRepo.WithTx(tx)
+
+	for _, change := range changes {
+		if change.field != "status" {
+			continue
+		}
+
+		err := s.activityRepo.WithTx(tx).TaskStatusChanged(ctx, projectID, taskID,
+			*update.Status, userID)
+		if err != nil {
+			return fmt.Errorf("activity repository task status changed: %w", err)
+		}
+	}
+
+	if len(changes) > 0 {
+		err := outboxRepo.Create(ctx, outbox.EV_TASK_UPDATED, outbox.TaskUpdated{
+			ProjectID:   projectID,
+			TaskID:      taskID,
+			Title:       update.Title,
+			Description: update.Description,
+			Status:      update.Status,
+			Priority:    update.Priority,
+			Estimate:    update.Estimate,
+			StartDate:   updatedDate(update.StartDate, update.ClearStartDate),
+			DueDate:     updatedDate(update.DueDate, update.ClearDueDate),
+			UpdaterID:   userID,
+		})
+		if err != nil {
+			return fmt.Errorf("outbox repository create task updated: %w", err)
+		}
+	}
+
+	for _, change := range changes {
+		if change.field != "description" || change.newValue == nil ||
+			!strings.Contains(*change.newValue, "@") {
+			continue
+		}
+
+		err := outboxRepo.Create(ctx, outbox.EV_MENTIONED, outbox.Mentioned{
+			ProjectID:   projectID,
+			TaskID:      taskID,
+			SourceID:    taskID,
+			MentionerID: userID,
+			Content:     *change.newValue,
+		})
+		if err != nil {
+			return fmt.Errorf("outbox repository create mentioned: %w", err)
+		}
+	}
+
+	for _, list := range []struct {
+		assigneeIDs []string
+		added       bool
+	}{
+		{update.AssigneesToAdd, true},
+		{update.AssigneesToRemove, false},
+	} {
+		for _, assigneeID := range list.assigneeIDs {
+			err := outboxRepo.Create(ctx, outbox.EV_ASSIGNEE_UPDATED, outbox.AssigneeUpdated{
+				ProjectID:  projectID,
+				TaskID:     taskID,
+				AssigneeID: assigneeID,
+				Added:      list.added,
+			})
+			if err != nil {
+				return fmt.Errorf("outbox repository create assignee updated: %w", err)
+			}
 		}
 	}
 
 	return nil
 }
 
+func (s *TaskService) Delete(ctx context.Context,
+	projectID, taskID, userID string) (*DeletedTask, error) {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_TASKS)
+	if err != nil {
+		return nil, fmt.Errorf("authorize manage tasks: %w", err)
+	}
+
+	row, err := s.taskRepo.Get(ctx, projectID, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("task repository get: %w", err)
+	}
+
+	deleted := DeletedTask{
+		ID:          row.ID,
+		ProjectID:   row.ProjectID,
+		Title:       row.Title,
+		AssigneeIDs: []string{},
+	}
+	for _, assignee := range row.Assignees {
+		deleted.AssigneeIDs = append(deleted.AssigneeIDs, assignee.AssigneeID)
+	}
+
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		err := s.taskRepo.WithTx(tx).Delete(ctx, projectID, taskID)
+		if err != nil {
+			return fmt.Errorf("task repository delete: %w", err)
+		}
+
+		err = s.outboxRepo.WithTx(tx).Create(ctx, outbox.EV_TASK_DELETED, outbox.TaskDeleted{
+			ProjectID:   deleted.ProjectID,
+			TaskID:      deleted.ID,
+			Title:       deleted.Title,
+			AssigneeIDs: deleted.AssigneeIDs,
+			DeleterID:   userID,
+		})
+		if err != nil {
+			return fmt.Errorf("outbox repository create: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("txManager WithTx: %w", err)
+	}
+
+	return &deleted, nil
+}
+
 func (s *TaskService) RecentlyAssigned(ctx context.Context,
 	userId string) ([]DashboardTaskItem, error) {
 
@@ -245,6 +1137,10 @@ func (s *TaskService) RecentlyAssigned(ctx context.Context,
 			Title:       r.Title,
 			Description: r.Description,
 			Status:      r.Status.String,
+			Priority:    r.Priority.String,
+			Estimate:    r.Estimate,
+			StartDate:   r.StartDate,
+			DueDate:     r.DueDate,
 			CreatedAt:   r.CreatedAt,
 			UpdatedAt:   r.UpdatedAt,
 			ProjectID:   r.ProjectID,
@@ -271,6 +1167,10 @@ func (s *TaskService) RecentlyUnassigned(ctx context.Context,
 			Title:       r.Title,
 			Description: r.Description,
 			Status:      r.Status.String,
+			Priority:    r.Priority.String,
+			Estimate:    r.Estimate,
+			StartDate:   r.StartDate,
+			DueDate:     r.DueDate,
 			CreatedAt:   r.CreatedAt,
 			UpdatedAt:   r.UpdatedAt,
 			ProjectID:   r.ProjectID,
@@ -280,3 +1180,117 @@ func (s *TaskService) RecentlyUnassigned(ctx context.Context,
 
 	return tasks, nil
 }
+
+func (s *TaskService) Due(ctx context.Context,
+	userId string) (*DueTasks, error) {
+
+	now := time.Now()
+	weekLater := now.AddDate(0, 0, 7)
+
+	// pick at most 50 tasks of each kind, the ones due earliest first
+	overdueRows, err := s.taskRepo.Due(ctx, userId, nil, now, 50)
+	if err != nil {
+		return nil, fmt.Errorf("task repository due overdue: %w", err)
+	}
+
+	weekRows, err := s.taskRepo.Due(ctx, userId, &now, weekLater, 50)
+	if err != nil {
+		return nil, fmt.Errorf("task repository due this week: %w", err)
+	}
+
+	due := DueTasks{
+		Overdue:     []DashboardTaskItem{},
+		DueThisWeek: []DashboardTaskItem{},
+	}
+	for _, r := range overdueRows {
+		due.Overdue = append(due.Overdue, DashboardTaskItem{
+			ID:          r.ID,
+			Title:       r.Title,
+			Description: r.Description,
+			Status:      r.Status.String,
+			Priority:    r.Priority.String,
+			Estimate:    r.Estimate,
+			StartDate:   r.StartDate,
+			DueDate:     r.DueDate,
+			CreatedAt:   r.CreatedAt,
+			UpdatedAt:   r.UpdatedAt,
+			ProjectID:   r.ProjectID,
+			ProjectName: r.ProjectName,
+		})
+	}
+	for _, r := range weekRows {
+		due.DueThisWeek = append(due.DueThisWeek, DashboardTaskItem{
+			ID:          r.ID,
+			Title:       r.Title,
+			Description: r.Description,
+			Status:      r.Status.String,
+			Priority:    r.Priority.String,
+			Estimate:    r.Estimate,
+			StartDate:   r.StartDate,
+			DueDate:     r.DueDate,
+			CreatedAt:   r.CreatedAt,
+			UpdatedAt:   r.UpdatedAt,
+			ProjectID:   r.ProjectID,
+			ProjectName: r.ProjectName,
+		})
+	}
+
+	return &due, nil
+}
+
+func (s *TaskService) Subtasks(ctx context.Context,
+	projectID, taskID, userID string,
+	query TaskListQuery) ([]ProjectTaskItem, string, error) {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_VIEW_PROJECT)
+	if err != nil {
+		return nil, "", fmt.Errorf("authorize view project: %w", err)
+	}
+
+	err = s.taskRepo.Exists(ctx, projectID, taskID)
+	if err != nil {
+		return nil, "", fmt.Errorf("task repository exists: %w", err)
+	}
+
+	query.ParentID = &taskID
+	return s.List(ctx, projectID, userID, query)
+}
+
+/*
+Checks that blockerID can be added as a blocker of the task
+
+The blocker is another task of the same project, not yet blocking the
+task and not waiting on it, directly or through other blockers.
+*/
+func (s *TaskService) validateBlocker(ctx context.Context,
+	projectID, taskID, blockerID string) error {
+
+	var err error
+
+	if taskID == blockerID {
+		return core.ErrInvalidValue
+	}
+
+	// tasks of other projects can not block the task
+	err = s.taskRepo.Exists(ctx, projectID, blockerID)
+	if err != nil {
+		return fmt.Errorf("task repository exists blocker: %w", err)
+	}
+
+	if err = s.taskRepo.IsBlocker(ctx, taskID, blockerID); err == nil {
+		return core.ErrDuplicate
+	}
+
+	blockers, err := s.taskRepo.TransitiveBlockers(ctx, blockerID)
+	if err != nil {
+		return fmt.Errorf("task repository transitive blockers: %w", err)
+	}
+	if slices.Contains(blockers, taskID) {
+		return core.ErrInvalidValue
+	}
+
+	return nil
+}
@@ -4,11 +4,17 @@ import (
 	"context"
 	"log"
 	"testing"
+	"time"
 
+	"github.com/ptracker/activity"
 	"github.com/ptracker/core"
 	"github.com/ptracker/core/assignees"
+	"github.com/ptracker/core/events"
+	"github.com/ptracker/core/labels"
 	"github.com/ptracker/core/members"
+	"github.com/ptracker/core/workflows"
 	"github.com/ptracker/models"
+	"github.com/ptracker/outbox"
 	"github.com/ptracker/testdata"
 	"github.com/ptracker/testhelpers"
 	"github.com/ptracker/testhelpers/fixtures"
@@ -40,7 +46,7 @@ func (suite *taskServiceTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
-	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{})
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -53,7 +59,13 @@ func (suite *taskServiceTestSuite) SetupSuite() {
 	taskRepo := NewTaskRepository(suite.db)
 	memberRepo := members.NewMemberRepository(suite.db)
 	assigneeRepo := assignees.NewAssigneeRepository(suite.db)
-	suite.service = NewTaskService(taskRepo, memberRepo, assigneeRepo)
+	labelRepo := labels.NewLabelRepository(suite.db)
+	workflowRepo := workflows.NewWorkflowRepository(suite.db)
+	eventRepo := events.NewEventRepository(suite.db)
+	txManager := core.NewTxManager(suite.db)
+	activityRepo := activity.NewActivityRepository(suite.db)
+	outboxRepo := outbox.NewOutboxRepository(suite.db)
+	suite.service = NewTaskService(txManager, taskRepo, memberRepo, assigneeRepo, labelRepo, workflowRepo, eventRepo, activityRepo, outboxRepo)
 
 	suite.fixtures = fixtures.New(suite.ctx, suite.db)
 
@@ -81,12 +93,71 @@ func (suite *taskServiceTestSuite) TestTaskCreate() {
 
 		_, err := suite.service.Create(suite.ctx,
 			p, USER_ONE,
-			sample_title, sample_description, core.TASK_STATUS_UNASSIGNED)
+			sample_title, sample_description, core.TASK_STATUS_UNASSIGNED, core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil, nil)
 
 		suite.Cleanup()
 
 		suite.Require().NoError(err)
 	})
+	t.Run("should record the task created activity with the task", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		taskId, err := suite.service.Create(suite.ctx,
+			p, USER_ONE,
+			"sample task", "", core.TASK_STATUS_UNASSIGNED, core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil, nil)
+		a, _ := gorm.G[models.Activity](suite.db).Where("project_id = ?", p).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(activity.AT_TASK_CREATED, a.Type)
+		suite.Require().Contains(string(a.Body), taskId)
+	})
+	t.Run("should create task with its assignees", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		taskId, err := suite.service.Create(suite.ctx,
+			p, USER_ONE,
+			"sample task", "", core.TASK_STATUS_UNASSIGNED, core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil,
+			[]string{USER_TWO})
+		assignees, _ := gorm.G[models.Assignee](suite.db).Where("task_id = ?", taskId).Find(suite.ctx)
+		events, _ := gorm.G[models.OutboxEvent](suite.db).Where("type = ?", outbox.EV_ASSIGNEE_UPDATED).Find(suite.ctx)
+
+		suite.Cleanup()
+		suite.db.Exec("DELETE FROM outbox_events")
+
+		suite.Require().NoError(err)
+		suite.Require().Len(assignees, 1)
+		suite.Require().Equal(USER_TWO, assignees[0].UserID)
+		suite.Require().Len(events, 1)
+	})
+	t.Run("should not create task when an assignee can not be added", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, err := suite.service.Create(suite.ctx,
+			p, USER_ONE,
+			"sample task", "", core.TASK_STATUS_UNASSIGNED, core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil,
+			[]string{"00000000-0000-0000-0000-000000000000"})
+		count, _ := gorm.G[models.Task](suite.db).Where("project_id = ?", p).Count(suite.ctx, "id")
+
+		suite.Cleanup()
+
+		suite.Require().Error(err)
+		suite.Require().Zero(count)
+	})
+	t.Run("should be invalid with a repeated assignee", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, err := suite.service.Create(suite.ctx,
+			p, USER_ONE,
+			"sample task", "", core.TASK_STATUS_UNASSIGNED, core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil,
+			[]string{USER_ONE, USER_ONE})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
 	t.Run("should create task with unassigned status", func(t *testing.T) {
 		p := suite.fixtures.InsertProject(models.Project{
 			Name:    "Project Fixture A",
@@ -97,7 +168,7 @@ func (suite *taskServiceTestSuite) TestTaskCreate() {
 
 		taskId, _ := suite.service.Create(suite.ctx,
 			p, USER_ONE,
-			sample_title, sample_description, core.TASK_STATUS_UNASSIGNED)
+			sample_title, sample_description, core.TASK_STATUS_UNASSIGNED, core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil, nil)
 
 		var status string
 		suite.db.WithContext(suite.ctx).
@@ -121,7 +192,7 @@ func (suite *taskServiceTestSuite) TestTaskCreate() {
 
 		_, err := suite.service.Create(suite.ctx,
 			p, USER_TWO,
-			sample_title, sample_description, core.TASK_STATUS_UNASSIGNED)
+			sample_title, sample_description, core.TASK_STATUS_UNASSIGNED, core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil, nil)
 
 		suite.Cleanup()
 
@@ -137,7 +208,7 @@ func (suite *taskServiceTestSuite) TestTaskCreate() {
 
 		_, err := suite.service.Create(suite.ctx,
 			p, USER_ONE,
-			sample_title, sample_description, core.TASK_STATUS_UNASSIGNED)
+			sample_title, sample_description, core.TASK_STATUS_UNASSIGNED, core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil, nil)
 
 		suite.Cleanup()
 
@@ -153,12 +224,36 @@ func (suite *taskServiceTestSuite) TestTaskCreate() {
 
 		_, err := suite.service.Create(suite.ctx,
 			p, USER_ONE,
-			sample_title, sample_description, "UNKNOWN")
+			sample_title, sample_description, "UNKNOWN", core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil, nil)
 
 		suite.Cleanup()
 
 		suite.Require().ErrorIs(err, core.ErrInvalidValue)
 	})
+	t.Run("should create task as maintainer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MAINTAINER))
+
+		_, err := suite.service.Create(suite.ctx,
+			p, USER_TWO,
+			"sample task", "sample description", core.TASK_STATUS_UNASSIGNED, core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil, nil)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+	})
+	t.Run("should be forbidden to create task as viewer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_VIEWER))
+
+		_, err := suite.service.Create(suite.ctx,
+			p, USER_TWO,
+			"sample task", "sample description", core.TASK_STATUS_UNASSIGNED, core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil, nil)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
 }
 
 func (suite *taskServiceTestSuite) TestTaskList() {
@@ -170,13 +265,14 @@ func (suite *taskServiceTestSuite) TestTaskList() {
 			OwnerID: USER_ONE,
 		})
 
-		tasks, err := suite.service.List(suite.ctx, p, USER_ONE)
+		tasks, cursor, err := suite.service.List(suite.ctx, p, USER_ONE, TaskListQuery{Limit: 10})
 
 		suite.Cleanup()
 
 		suite.Require().NoError(err)
 		suite.Require().NotNil(tasks)
 		suite.Require().Equal(0, len(tasks))
+		suite.Require().Empty(cursor)
 	})
 	t.Run("should give 2 tasks", func(t *testing.T) {
 		p := suite.fixtures.InsertProject(models.Project{
@@ -186,7 +282,7 @@ func (suite *taskServiceTestSuite) TestTaskList() {
 		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
 		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
 
-		tasks, err := suite.service.List(suite.ctx, p, USER_ONE)
+		tasks, _, err := suite.service.List(suite.ctx, p, USER_ONE, TaskListQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -202,7 +298,7 @@ func (suite *taskServiceTestSuite) TestTaskList() {
 		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
 		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskID, USER_TWO))
 
-		tasks, err := suite.service.List(suite.ctx, p, USER_ONE)
+		tasks, _, err := suite.service.List(suite.ctx, p, USER_ONE, TaskListQuery{Limit: 10})
 
 		suite.Cleanup()
 
@@ -211,6 +307,49 @@ func (suite *taskServiceTestSuite) TestTaskList() {
 			[]string{USER_TWO},
 			[]string{tasks[0].Assignees[0].UserID})
 	})
+	t.Run("should have next page", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(models.Project{
+			Name:    "Project Fixture A",
+			OwnerID: USER_ONE,
+		})
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		tasks, cursor, err := suite.service.List(suite.ctx, p, USER_ONE, TaskListQuery{Limit: 1})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(tasks))
+		suite.Require().NotEmpty(cursor)
+	})
+	t.Run("should be invalid with unknown status filter", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(models.Project{
+			Name:    "Project Fixture A",
+			OwnerID: USER_ONE,
+		})
+
+		_, _, err := suite.service.List(suite.ctx, p, USER_ONE, TaskListQuery{
+			Limit:    10,
+			Statuses: []string{"Unknown"},
+		})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should be forbidden for non-member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(models.Project{
+			Name:    "Project Fixture A",
+			OwnerID: USER_ONE,
+		})
+
+		_, _, err := suite.service.List(suite.ctx, p, USER_TWO, TaskListQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
 }
 
 func (suite *taskServiceTestSuite) TestTaskGet() {
@@ -333,9 +472,7 @@ func (suite *taskServiceTestSuite) TestTaskUpdate() {
 		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectId, core.TASK_STATUS_ONGOING))
 		updatedTaskTitle := "Project title updated"
 
-		err := suite.service.Update(suite.ctx,
-			projectId, taskId, USER_ONE,
-			&updatedTaskTitle, nil, nil)
+		_, err := suite.service.Update(suite.ctx, projectId, taskId, USER_ONE, TaskUpdate{Title: &updatedTaskTitle})
 
 		suite.Cleanup()
 
@@ -350,9 +487,7 @@ func (suite *taskServiceTestSuite) TestTaskUpdate() {
 
 		updatedTaskTitle := "Project title updated"
 
-		suite.service.Update(suite.ctx,
-			projectId, taskId, USER_ONE,
-			&updatedTaskTitle, nil, nil)
+		suite.service.Update(suite.ctx, projectId, taskId, USER_ONE, TaskUpdate{Title: &updatedTaskTitle})
 
 		var title string
 		suite.db.WithContext(suite.ctx).
@@ -374,9 +509,7 @@ func (suite *taskServiceTestSuite) TestTaskUpdate() {
 		updatedTaskDesc := "Project description updated"
 		updatedStatus := core.TASK_STATUS_ABANDONED
 
-		suite.service.Update(suite.ctx,
-			projectId, taskId, USER_ONE,
-			nil, &updatedTaskDesc, &updatedStatus)
+		suite.service.Update(suite.ctx, projectId, taskId, USER_ONE, TaskUpdate{Description: &updatedTaskDesc, Status: &updatedStatus})
 
 		task, _ := gorm.G[models.Task](suite.db).Where("id = ?", taskId).First(suite.ctx)
 
@@ -394,9 +527,7 @@ func (suite *taskServiceTestSuite) TestTaskUpdate() {
 		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectId, core.TASK_STATUS_ONGOING))
 		updatedStatus := "Unknown"
 
-		err := suite.service.Update(suite.ctx,
-			projectId, taskId, USER_ONE,
-			nil, nil, &updatedStatus)
+		_, err := suite.service.Update(suite.ctx, projectId, taskId, USER_ONE, TaskUpdate{Status: &updatedStatus})
 
 		suite.Cleanup()
 
@@ -409,9 +540,7 @@ func (suite *taskServiceTestSuite) TestTaskUpdate() {
 		})
 		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectId, core.TASK_STATUS_ONGOING))
 
-		err := suite.service.Update(suite.ctx,
-			projectId, taskId, USER_ONE,
-			nil, nil, nil)
+		_, err := suite.service.Update(suite.ctx, projectId, taskId, USER_ONE, TaskUpdate{})
 
 		suite.Cleanup()
 
@@ -426,9 +555,7 @@ func (suite *taskServiceTestSuite) TestTaskUpdate() {
 		suite.fixtures.InsertMember(fixtures.GetMemberRow(projectId, USER_TWO, core.ROLE_MEMBER))
 		updatedTaskTitle := "Project title updated"
 
-		err := suite.service.Update(suite.ctx,
-			projectId, taskId, USER_TWO,
-			&updatedTaskTitle, nil, nil)
+		_, err := suite.service.Update(suite.ctx, projectId, taskId, USER_TWO, TaskUpdate{Title: &updatedTaskTitle})
 
 		suite.Cleanup()
 
@@ -444,9 +571,181 @@ func (suite *taskServiceTestSuite) TestTaskUpdate() {
 		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(projectId, taskId, USER_TWO))
 		updatedTaskTitle := "Project title updated"
 
-		err := suite.service.Update(suite.ctx,
-			projectId, taskId, USER_TWO,
-			&updatedTaskTitle, nil, nil)
+		_, err := suite.service.Update(suite.ctx, projectId, taskId, USER_TWO, TaskUpdate{Title: &updatedTaskTitle})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+	})
+	t.Run("should update unassigned task as maintainer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MAINTAINER))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		title := "Task title updated"
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_TWO, TaskUpdate{Title: &title})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+	})
+	t.Run("should be forbidden to update assigned task as viewer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_VIEWER))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskId, USER_TWO))
+		title := "Task title updated"
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_TWO, TaskUpdate{Title: &title})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+}
+
+func (suite *taskServiceTestSuite) TestAtomicTaskUpdate() {
+	t := suite.T()
+
+	t.Run("should apply fields, assignees, labels and blockers together", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		blockerId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		l := suite.fixtures.InsertLabel(fixtures.RandomLabelRow(p))
+		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskId, USER_THREE))
+		title := "Task title updated"
+
+		updated, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{
+			Title:             &title,
+			AssigneesToAdd:    []string{USER_TWO},
+			AssigneesToRemove: []string{USER_THREE},
+			LabelsToAdd:       []string{l},
+			BlockersToAdd:     []string{blockerId},
+		})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(title, updated.Task.Title)
+		suite.Require().Equal(1, len(updated.Task.Assignees))
+		suite.Require().Equal(USER_TWO, updated.Task.Assignees[0].UserID)
+		suite.Require().Equal(1, len(updated.Task.Labels))
+		suite.Require().Equal(1, len(updated.Task.BlockedBy))
+		suite.Require().Equal([]string{"title", "assignees", "labels", "blockers"}, updated.Changed)
+	})
+	t.Run("should leave the task unchanged when an assignee fails", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskRow := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		taskId := suite.fixtures.InsertTask(taskRow)
+		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskId, USER_TWO))
+		title := "Task title updated"
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{
+			Title:          &title,
+			AssigneesToAdd: []string{USER_TWO},
+		})
+		task, _ := gorm.G[models.Task](suite.db).Where("id = ?", taskId).First(suite.ctx)
+		events, _ := gorm.G[models.TaskEvent](suite.db).Where("task_id = ?", taskId).Find(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrDuplicate)
+		suite.Require().Equal(taskRow.Title, task.Title)
+		suite.Require().Equal(0, len(events))
+	})
+	t.Run("should return invalid value when a user is added and removed", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{
+			AssigneesToAdd:    []string{USER_TWO},
+			AssigneesToRemove: []string{USER_TWO},
+		})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should be forbidden for assignee to change assignees", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskId, USER_TWO))
+		title := "Task title updated"
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_TWO, TaskUpdate{
+			Title:          &title,
+			AssigneesToAdd: []string{USER_THREE},
+		})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+	t.Run("should record assignee events with the field changes", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		estimate := 8
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{
+			Estimate:       &estimate,
+			AssigneesToAdd: []string{USER_TWO},
+		})
+		updated, _ := gorm.G[models.TaskEvent](suite.db).
+			Where("task_id = ? AND type = ?", taskId, core.TASK_EVENT_UPDATED).
+			Find(suite.ctx)
+		added, _ := gorm.G[models.TaskEvent](suite.db).
+			Where("task_id = ? AND type = ?", taskId, core.TASK_EVENT_ASSIGNEE_ADDED).
+			Find(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(updated))
+		suite.Require().Equal(1, len(added))
+	})
+}
+
+func (suite *taskServiceTestSuite) TestTaskVersion() {
+	t := suite.T()
+
+	t.Run("should bump version on update", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		title := "Task title updated"
+		version := 1
+
+		updated, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE,
+			TaskUpdate{Title: &title, Version: &version})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, updated.Task.Version)
+	})
+	t.Run("should return precondition failed for stale version", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		title, other := "Task title updated", "Task title updated again"
+		version := 1
+		suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{Title: &title, Version: &version})
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE,
+			TaskUpdate{Title: &other, Version: &version})
+		task, _ := gorm.G[models.Task](suite.db).Where("id = ?", taskId).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrPreconditionFailed)
+		suite.Require().Equal(title, task.Title)
+	})
+	t.Run("should update without version", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		title, other := "Task title updated", "Task title updated again"
+		suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{Title: &title})
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{Title: &other})
 
 		suite.Cleanup()
 
@@ -522,3 +821,782 @@ func (suite *taskServiceTestSuite) TestRecentlyUnassigned() {
 		suite.Require().Equal(name, tasks[0].ProjectName)
 	})
 }
+
+func (suite *taskServiceTestSuite) TestTaskDates() {
+	t := suite.T()
+
+	t.Run("should create task with start and due date", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		start := time.Now().UTC().Truncate(time.Second)
+		due := start.AddDate(0, 0, 7)
+
+		taskId, err := suite.service.Create(suite.ctx, p, USER_ONE,
+			"sample task", "sample description", core.TASK_STATUS_UNASSIGNED, core.TASK_PRIORITY_MEDIUM, 0, nil,
+			&start, &due, nil)
+		task, _ := suite.service.Get(suite.ctx, p, taskId, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().True(start.Equal(*task.StartDate))
+		suite.Require().True(due.Equal(*task.DueDate))
+	})
+	t.Run("should get invalid value error when start date is after due date", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		due := time.Now()
+		start := due.AddDate(0, 0, 1)
+
+		_, err := suite.service.Create(suite.ctx, p, USER_ONE,
+			"sample task", "sample description", core.TASK_STATUS_UNASSIGNED, core.TASK_PRIORITY_MEDIUM, 0, nil,
+			&start, &due, nil)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should update due date", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		due := time.Now().AddDate(0, 0, 2).UTC().Truncate(time.Second)
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{DueDate: &due})
+		task, _ := suite.service.Get(suite.ctx, p, taskId, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().True(due.Equal(*task.DueDate))
+	})
+	t.Run("should get invalid value error when due date moves before stored start date", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		start := time.Now()
+		row := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		row.StartDate = &start
+		taskId := suite.fixtures.InsertTask(row)
+		due := start.AddDate(0, 0, -1)
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{DueDate: &due})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should clear due date and record it in the history", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		due := time.Now()
+		row := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		row.DueDate = &due
+		taskId := suite.fixtures.InsertTask(row)
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{ClearDueDate: true})
+		task, _ := gorm.G[models.Task](suite.db).Where("id = ?", taskId).First(suite.ctx)
+		events, _ := gorm.G[models.TaskEvent](suite.db).Where("task_id = ?", taskId).Find(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Nil(task.DueDate)
+		suite.Require().Len(events, 1)
+		suite.Require().Equal("due_date", *events[0].Field)
+		suite.Require().NotNil(events[0].OldValue)
+		suite.Require().Nil(events[0].NewValue)
+	})
+	t.Run("should get invalid value error when a date is set and cleared", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		start := time.Now()
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{
+			StartDate:      &start,
+			ClearStartDate: true,
+		})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+}
+
+func (suite *taskServiceTestSuite) TestDue() {
+	t := suite.T()
+
+	t.Run("should get empty overdue and due this week lists", func(t *testing.T) {
+		due, err := suite.service.Due(suite.ctx, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().NotNil(due.Overdue)
+		suite.Require().NotNil(due.DueThisWeek)
+		suite.Require().Equal(0, len(due.Overdue))
+		suite.Require().Equal(0, len(due.DueThisWeek))
+	})
+	t.Run("should split overdue and due this week tasks", func(t *testing.T) {
+		name := "Project Fixture A"
+		p := suite.fixtures.InsertProject(models.Project{
+			Name:    name,
+			OwnerID: USER_ONE,
+		})
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		yesterday := time.Now().AddDate(0, 0, -1)
+		inThreeDays := time.Now().AddDate(0, 0, 3)
+		nextMonth := time.Now().AddDate(0, 1, 0)
+		for _, date := range []*time.Time{&yesterday, &inThreeDays, &nextMonth} {
+			row := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+			row.DueDate = date
+			suite.fixtures.InsertTask(row)
+		}
+
+		due, err := suite.service.Due(suite.ctx, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(due.Overdue))
+		suite.Require().Equal(1, len(due.DueThisWeek))
+		suite.Require().Equal(name, due.Overdue[0].ProjectName)
+	})
+}
+
+func (suite *taskServiceTestSuite) TestTaskPriority() {
+	t := suite.T()
+
+	t.Run("should create task with Medium priority by default", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		taskId, err := suite.service.Create(suite.ctx, p, USER_ONE,
+			"sample task", "sample description", core.TASK_STATUS_UNASSIGNED,
+			"", 3, nil, nil, nil, nil)
+		task, _ := suite.service.Get(suite.ctx, p, taskId, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(core.TASK_PRIORITY_MEDIUM, task.Priority)
+		suite.Require().Equal(3, task.Estimate)
+	})
+	t.Run("should get invalid value error for unknown priority", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, err := suite.service.Create(suite.ctx, p, USER_ONE,
+			"sample task", "sample description", core.TASK_STATUS_UNASSIGNED,
+			"Critical", 0, nil, nil, nil, nil)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should get invalid value error for negative estimate", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		estimate := -1
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{Estimate: &estimate})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should update priority and estimate", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		priority := core.TASK_PRIORITY_URGENT
+		estimate := 5
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{Priority: &priority, Estimate: &estimate})
+		task, _ := suite.service.Get(suite.ctx, p, taskId, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(core.TASK_PRIORITY_URGENT, task.Priority)
+		suite.Require().Equal(5, task.Estimate)
+	})
+	t.Run("should get invalid value error when filtering unknown priority", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, _, err := suite.service.List(suite.ctx, p, USER_ONE, TaskListQuery{
+			Limit:      10,
+			Priorities: []string{"Critical"},
+		})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+}
+
+func (suite *taskServiceTestSuite) TestSubtasks() {
+	t := suite.T()
+
+	t.Run("should create subtask", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		parentID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		taskId, err := suite.service.Create(suite.ctx, p, USER_ONE,
+			"sample task", "sample description", core.TASK_STATUS_UNASSIGNED,
+			core.TASK_PRIORITY_MEDIUM, 0, &parentID, nil, nil, nil)
+		task, _ := suite.service.Get(suite.ctx, p, taskId, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(parentID, *task.ParentID)
+	})
+	t.Run("should get invalid value error for parent of another project", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		parentID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p2, core.TASK_STATUS_ONGOING))
+
+		_, err := suite.service.Create(suite.ctx, p, USER_ONE,
+			"sample task", "sample description", core.TASK_STATUS_UNASSIGNED,
+			core.TASK_PRIORITY_MEDIUM, 0, &parentID, nil, nil, nil)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should get progress without abandoned subtasks", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		parentID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		for _, status := range []string{
+			core.TASK_STATUS_ONGOING,
+			core.TASK_STATUS_COMPLETED,
+			core.TASK_STATUS_ABANDONED,
+		} {
+			subtask := fixtures.RandomTaskRow(p, status)
+			subtask.ParentID = &parentID
+			suite.fixtures.InsertTask(subtask)
+		}
+
+		task, err := suite.service.Get(suite.ctx, p, parentID, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(int64(3), task.Subtasks)
+		suite.Require().Equal(int64(1), task.CompletedSubtasks)
+		suite.Require().Equal(50, task.Progress)
+	})
+	t.Run("should get invalid value error when task becomes its own ancestor", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		parentID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		subtask := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		subtask.ParentID = &parentID
+		subtaskID := suite.fixtures.InsertTask(subtask)
+
+		_, err := suite.service.Update(suite.ctx, p, parentID, USER_ONE, TaskUpdate{ParentID: &subtaskID})
+		_, selfErr := suite.service.Update(suite.ctx, p, parentID, USER_ONE, TaskUpdate{ParentID: &parentID})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+		suite.Require().ErrorIs(selfErr, core.ErrInvalidValue)
+	})
+	t.Run("should move subtask to top level", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		parentID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		subtask := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		subtask.ParentID = &parentID
+		subtaskID := suite.fixtures.InsertTask(subtask)
+		topLevel := ""
+
+		_, err := suite.service.Update(suite.ctx, p, subtaskID, USER_ONE, TaskUpdate{ParentID: &topLevel})
+		task, _ := suite.service.Get(suite.ctx, p, subtaskID, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Nil(task.ParentID)
+	})
+	t.Run("should get conflict error when completing parent with ongoing subtasks", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		parentID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		subtask := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		subtask.ParentID = &parentID
+		suite.fixtures.InsertTask(subtask)
+		status := core.TASK_STATUS_COMPLETED
+
+		_, err := suite.service.Update(suite.ctx, p, parentID, USER_ONE, TaskUpdate{Status: &status})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrConflict)
+	})
+	t.Run("should complete parent with ongoing subtasks when forced", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		parentID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		subtask := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		subtask.ParentID = &parentID
+		suite.fixtures.InsertTask(subtask)
+		status := core.TASK_STATUS_COMPLETED
+
+		_, err := suite.service.Update(suite.ctx, p, parentID, USER_ONE, TaskUpdate{Status: &status, Force: true})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+	})
+	t.Run("should list subtasks of task", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		parentID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		subtask := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		subtask.ParentID = &parentID
+		suite.fixtures.InsertTask(subtask)
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		tasks, _, err := suite.service.Subtasks(suite.ctx, p, parentID, USER_ONE, TaskListQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(tasks))
+		suite.Require().Equal(subtask.ID, tasks[0].ID)
+	})
+	t.Run("should not find subtasks of task of another project", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		parentID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p2, core.TASK_STATUS_ONGOING))
+
+		_, _, err := suite.service.Subtasks(suite.ctx, p, parentID, USER_ONE, TaskListQuery{Limit: 10})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
+
+func (suite *taskServiceTestSuite) TestTaskDependencies() {
+	t := suite.T()
+
+	t.Run("should get blocked by and blocks with task detail", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		blockerID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		_, err := suite.service.Update(suite.ctx, p, taskID, USER_ONE, TaskUpdate{BlockersToAdd: []string{blockerID}})
+		task, _ := suite.service.Get(suite.ctx, p, taskID, USER_ONE)
+		blocker, _ := suite.service.Get(suite.ctx, p, blockerID, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(task.BlockedBy))
+		suite.Require().Equal(blockerID, task.BlockedBy[0].ID)
+		suite.Require().Equal(0, len(task.Blocks))
+		suite.Require().Equal(1, len(blocker.Blocks))
+		suite.Require().Equal(taskID, blocker.Blocks[0].ID)
+	})
+	t.Run("should get duplicate error when adding blocker twice", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		blockerID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(taskID, blockerID))
+
+		_, err := suite.service.Update(suite.ctx, p, taskID, USER_ONE, TaskUpdate{BlockersToAdd: []string{blockerID}})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrDuplicate)
+	})
+	t.Run("should get invalid value error for dependency cycle", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		first := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		second := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		third := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(first, second))
+		suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(second, third))
+
+		_, err := suite.service.Update(suite.ctx, p, third, USER_ONE, TaskUpdate{BlockersToAdd: []string{first}})
+		_, selfErr := suite.service.Update(suite.ctx, p, first, USER_ONE, TaskUpdate{BlockersToAdd: []string{first}})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+		suite.Require().ErrorIs(selfErr, core.ErrInvalidValue)
+	})
+	t.Run("should not find blocker of another project", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		blockerID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p2, core.TASK_STATUS_ONGOING))
+
+		_, err := suite.service.Update(suite.ctx, p, taskID, USER_ONE, TaskUpdate{BlockersToAdd: []string{blockerID}})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+	t.Run("should be forbidden to add blocker as viewer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_VIEWER))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		blockerID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		_, err := suite.service.Update(suite.ctx, p, taskID, USER_TWO, TaskUpdate{BlockersToAdd: []string{blockerID}})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+	t.Run("should get conflict error when starting task with open blocker", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		blockerID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(taskID, blockerID))
+		status := core.TASK_STATUS_ONGOING
+
+		_, err := suite.service.Update(suite.ctx, p, taskID, USER_ONE, TaskUpdate{Status: &status})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrConflict)
+	})
+	t.Run("should get conflict error when starting task with a blocker added in the same update", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskRow := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		taskID := suite.fixtures.InsertTask(taskRow)
+		blockerID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		status := core.TASK_STATUS_ONGOING
+
+		_, err := suite.service.Update(suite.ctx, p, taskID, USER_ONE, TaskUpdate{
+			Status:        &status,
+			BlockersToAdd: []string{blockerID},
+		})
+		task, _ := gorm.G[models.Task](suite.db).Where("id = ?", taskID).First(suite.ctx)
+		blockerErr := suite.service.taskRepo.IsBlocker(suite.ctx, taskID, blockerID)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrConflict)
+		suite.Require().Equal(core.TASK_STATUS_UNASSIGNED, task.Status.String)
+		suite.Require().ErrorIs(blockerErr, core.ErrNotFound)
+	})
+	t.Run("should start task when its open blocker is removed in the same update", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		blockerID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(taskID, blockerID))
+		status := core.TASK_STATUS_ONGOING
+
+		_, err := suite.service.Update(suite.ctx, p, taskID, USER_ONE, TaskUpdate{
+			Status:           &status,
+			BlockersToRemove: []string{blockerID},
+		})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+	})
+	t.Run("should start task once blockers are closed", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		completedID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_COMPLETED))
+		abandonedID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ABANDONED))
+		suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(taskID, completedID))
+		suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(taskID, abandonedID))
+		status := core.TASK_STATUS_ONGOING
+
+		_, err := suite.service.Update(suite.ctx, p, taskID, USER_ONE, TaskUpdate{Status: &status})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+	})
+	t.Run("should remove blocker", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		blockerID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertTaskDependency(fixtures.GetTaskDependencyRow(taskID, blockerID))
+
+		_, err := suite.service.Update(suite.ctx, p, taskID, USER_ONE, TaskUpdate{BlockersToRemove: []string{blockerID}})
+		task, _ := suite.service.Get(suite.ctx, p, taskID, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(0, len(task.BlockedBy))
+	})
+}
+
+func (suite *taskServiceTestSuite) TestTaskWorkflow() {
+	t := suite.T()
+
+	insertWorkflow := func(p string) {
+		suite.fixtures.InsertWorkflowStatus(fixtures.GetWorkflowStatusRow(p, "Todo", core.TASK_STATUS_UNASSIGNED, 0))
+		suite.fixtures.InsertWorkflowStatus(fixtures.GetWorkflowStatusRow(p, "Doing", core.TASK_STATUS_ONGOING, 1))
+		suite.fixtures.InsertWorkflowStatus(fixtures.GetWorkflowStatusRow(p, "Done", core.TASK_STATUS_COMPLETED, 2))
+		suite.fixtures.InsertWorkflowTransition(fixtures.GetWorkflowTransitionRow(p, "Todo", "Doing"))
+		suite.fixtures.InsertWorkflowTransition(fixtures.GetWorkflowTransitionRow(p, "Doing", "Done"))
+	}
+
+	t.Run("should create task in custom status under its category", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		insertWorkflow(p)
+
+		taskId, err := suite.service.Create(suite.ctx, p, USER_ONE,
+			"sample task", "sample description", "Doing",
+			core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil, nil)
+		task, _ := suite.service.Get(suite.ctx, p, taskId, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(core.TASK_STATUS_ONGOING, task.Status)
+		suite.Require().Equal("Doing", *task.WorkflowStatus)
+	})
+	t.Run("should be invalid to use built-in status with workflow", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		insertWorkflow(p)
+
+		_, err := suite.service.Create(suite.ctx, p, USER_ONE,
+			"sample task", "sample description", core.TASK_STATUS_ONGOING,
+			core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil, nil)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should move task along workflow transition", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		insertWorkflow(p)
+		todo := "Todo"
+		row := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		row.WorkflowStatus = &todo
+		taskId := suite.fixtures.InsertTask(row)
+		status := "Doing"
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{Status: &status})
+		task, _ := suite.service.Get(suite.ctx, p, taskId, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(core.TASK_STATUS_ONGOING, task.Status)
+		suite.Require().Equal("Doing", *task.WorkflowStatus)
+	})
+	t.Run("should be invalid to skip workflow transitions", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		insertWorkflow(p)
+		todo := "Todo"
+		row := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		row.WorkflowStatus = &todo
+		taskId := suite.fixtures.InsertTask(row)
+		status := "Done"
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{Status: &status})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should move task created before workflow to any status", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		insertWorkflow(p)
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		status := "Done"
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{Status: &status})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+	})
+	t.Run("should list tasks in custom status", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		insertWorkflow(p)
+		todo, doing := "Todo", "Doing"
+		row := fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED)
+		row.WorkflowStatus = &todo
+		suite.fixtures.InsertTask(row)
+		row = fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		row.WorkflowStatus = &doing
+		taskId := suite.fixtures.InsertTask(row)
+
+		tasks, _, err := suite.service.List(suite.ctx, p, USER_ONE, TaskListQuery{
+			Limit:    10,
+			Statuses: []string{"Doing"},
+		})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Len(tasks, 1)
+		suite.Require().Equal(taskId, tasks[0].ID)
+	})
+	t.Run("should be invalid to list tasks in unknown status", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		insertWorkflow(p)
+
+		_, _, err := suite.service.List(suite.ctx, p, USER_ONE, TaskListQuery{
+			Limit:    10,
+			Statuses: []string{"Review"},
+		})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+}
+
+func (suite *taskServiceTestSuite) TestTaskHistory() {
+	t := suite.T()
+
+	t.Run("should record created event", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		taskId, err := suite.service.Create(suite.ctx, p, USER_ONE,
+			"sample task", "sample description", core.TASK_STATUS_UNASSIGNED,
+			core.TASK_PRIORITY_MEDIUM, 0, nil, nil, nil, nil)
+		events, _ := gorm.G[models.TaskEvent](suite.db).Where("task_id = ?", taskId).Find(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(events))
+		suite.Require().Equal(core.TASK_EVENT_CREATED, events[0].Type)
+		suite.Require().Equal(USER_ONE, events[0].ActorID)
+	})
+	t.Run("should record old and new value of changed fields only", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		row := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		taskId := suite.fixtures.InsertTask(row)
+		status := core.TASK_STATUS_ABANDONED
+		priority := core.TASK_PRIORITY_MEDIUM
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{Status: &status, Priority: &priority})
+		events, _ := gorm.G[models.TaskEvent](suite.db).Where("task_id = ?", taskId).Find(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(events))
+		suite.Require().Equal(core.TASK_EVENT_UPDATED, events[0].Type)
+		suite.Require().Equal("status", *events[0].Field)
+		suite.Require().Equal(core.TASK_STATUS_ONGOING, *events[0].OldValue)
+		suite.Require().Equal(core.TASK_STATUS_ABANDONED, *events[0].NewValue)
+	})
+	t.Run("should not record events of rejected update", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskId := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+		title := "new title"
+		estimate := -1
+
+		_, err := suite.service.Update(suite.ctx, p, taskId, USER_ONE, TaskUpdate{Title: &title, Estimate: &estimate})
+		events, _ := gorm.G[models.TaskEvent](suite.db).Where("task_id = ?", taskId).Find(suite.ctx)
+		task, _ := suite.service.Get(suite.ctx, p, taskId, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+		suite.Require().Equal(0, len(events))
+		suite.Require().NotEqual(title, task.Title)
+	})
+}
+
+func (suite *taskServiceTestSuite) TestTaskDelete() {
+	t := suite.T()
+
+	t.Run("should delete task as owner", func(t *testing.T) {
+		projectID := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectID, core.TASK_STATUS_UNASSIGNED))
+
+		_, err := suite.service.Delete(suite.ctx, projectID, taskID, USER_ONE)
+
+		var count int64
+		suite.db.Table("tasks").Where("id = ?", taskID).Count(&count)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().EqualValues(0, count)
+	})
+	t.Run("should return assignees of deleted task", func(t *testing.T) {
+		projectID := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(projectID, USER_TWO, core.ROLE_MEMBER))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectID, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(projectID, taskID, USER_TWO))
+
+		deleted, err := suite.service.Delete(suite.ctx, projectID, taskID, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(taskID, deleted.ID)
+		suite.Require().Equal([]string{USER_TWO}, deleted.AssigneeIDs)
+	})
+	t.Run("should be forbidden for member", func(t *testing.T) {
+		projectID := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(projectID, USER_TWO, core.ROLE_MEMBER))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(projectID, core.TASK_STATUS_UNASSIGNED))
+
+		_, err := suite.service.Delete(suite.ctx, projectID, taskID, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+	t.Run("should not find task of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_UNASSIGNED))
+
+		_, err := suite.service.Delete(suite.ctx, p2, taskID, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
+
+func (suite *taskServiceTestSuite) TestCrossProjectAccess() {
+	t := suite.T()
+
+	t.Run("should not get task of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_TWO))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_UNASSIGNED))
+
+		_, err := suite.service.Get(suite.ctx, p2, taskID, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+	t.Run("should not update task of another project", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_TWO))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_UNASSIGNED))
+		title := "Hijacked"
+
+		_, err := suite.service.Update(suite.ctx, p2, taskID, USER_TWO, TaskUpdate{Title: &title})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+	t.Run("should not update task of another project as its assignee", func(t *testing.T) {
+		p1 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p2, USER_TWO, core.ROLE_MEMBER))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p1, core.TASK_STATUS_ONGOING))
+		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p1, taskID, USER_TWO))
+		title := "Hijacked"
+
+		_, err := suite.service.Update(suite.ctx, p2, taskID, USER_TWO, TaskUpdate{Title: &title})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+	t.Run("should not get task as non-member of its project", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+
+		_, err := suite.service.Get(suite.ctx, p, taskID, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+}
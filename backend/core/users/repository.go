@@ -2,8 +2,8 @@ package users
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/google/uuid"
 	"github.com/ptracker/core"
@@ -39,11 +39,9 @@ func (r *UserRepository) Create(ctx context.Context,
 	}
 
 	err := gorm.G[models.User](r.db).Create(ctx, &user)
-
-	if err != nil {
-		if strings.Contains(err.Error(), `duplicate key value violates unique constraint`) {
-			return "", core.ErrDuplicate
-		}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return "", core.ErrDuplicate
+	} else if err != nil {
 		return "", fmt.Errorf("gorm create: %w", err)
 	}
 
@@ -14,23 +14,16 @@ type RoleChecker interface {
 		projectID string, userID string) (string, error)
 }
 
-func NeedsToBeAMember(ctx context.Context,
-	c RoleChecker,
-	projectID, userID string) error {
+/*
+Checks that user userID can perform the action permission in project projectID
 
-	_, err := c.Role(ctx, projectID, userID)
-	if err == ErrNotFound {
-		return ErrForbidden
-	} else if err != nil {
-		return fmt.Errorf("role checker Role: %w", err)
-	}
-
-	return nil
-}
-
-func NeedsToBeAnOwner(ctx context.Context,
+Returns ErrForbidden if the user is not a member of the project or their
+role does not grant the permission.
+*/
+func Authorize(ctx context.Context,
 	c RoleChecker,
-	projectID, userID string) error {
+	projectID, userID string,
+	permission Permission) error {
 
 	role, err := c.Role(ctx, projectID, userID)
 	if err == ErrNotFound {
@@ -39,7 +32,7 @@ func NeedsToBeAnOwner(ctx context.Context,
 		return fmt.Errorf("role checker Role: %w", err)
 	}
 
-	if role != ROLE_OWNER {
+	if !HasPermission(role, permission) {
 		return ErrForbidden
 	}
 
@@ -69,3 +62,46 @@ func NeedsToBeAnAssignee(ctx context.Context,
 
 	return nil
 }
+
+type TaskChecker interface {
+
+	/*
+		Check if task taskID belongs to the project projectID
+	*/
+	Exists(ctx context.Context,
+		projectID, taskID string) error
+}
+
+func NeedsToBeAProjectTask(ctx context.Context,
+	c TaskChecker,
+	projectID, taskID string) error {
+
+	err := c.Exists(ctx, projectID, taskID)
+	if err == ErrNotFound {
+		return ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("task checker Exists: %w", err)
+	}
+
+	return nil
+}
+
+/*
+Checks that user userID is a member of the project projectID, whatever
+their role
+
+Returns ErrForbidden if the user is not a member of the project.
+*/
+func NeedsToBeAMember(ctx context.Context,
+	c RoleChecker,
+	projectID, userID string) error {
+
+	_, err := c.Role(ctx, projectID, userID)
+	if err == ErrNotFound {
+		return ErrForbidden
+	} else if err != nil {
+		return fmt.Errorf("role checker Role: %w", err)
+	}
+
+	return nil
+}
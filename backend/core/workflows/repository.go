@@ -0,0 +1,153 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WorkflowRepository struct {
+	db *gorm.DB
+}
+
+func NewWorkflowRepository(db *gorm.DB) *WorkflowRepository {
+	return &WorkflowRepository{
+		db: db,
+	}
+}
+
+func (r *WorkflowRepository) WithTx(tx *gorm.DB) *WorkflowRepository {
+	return NewWorkflowRepository(tx)
+}
+
+func (r *WorkflowRepository) ListStatuses(ctx context.Context,
+	projectID string) ([]models.WorkflowStatus, error) {
+
+	statuses, err := gorm.G[models.WorkflowStatus](r.db).
+		Where("project_id = ?", projectID).
+		Order("position ASC").
+		Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gorm find: %w", err)
+	}
+
+	return statuses, nil
+}
+
+func (r *WorkflowRepository) GetStatus(ctx context.Context,
+	projectID, name string) (models.WorkflowStatus, error) {
+
+	status, err := gorm.G[models.WorkflowStatus](r.db).
+		Where("project_id = ? AND name = ?", projectID, name).
+		First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return status, core.ErrNotFound
+	} else if err != nil {
+		return status, fmt.Errorf("gorm query: %w", err)
+	}
+
+	return status, nil
+}
+
+func (r *WorkflowRepository) ListTransitions(ctx context.Context,
+	projectID string) ([]models.WorkflowTransition, error) {
+
+	transitions, err := gorm.G[models.WorkflowTransition](r.db).
+		Where("project_id = ?", projectID).
+		Order("from_status ASC, to_status ASC").
+		Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gorm find: %w", err)
+	}
+
+	return transitions, nil
+}
+
+/*
+Returns the custom statuses the tasks of the project are in
+
+The tasks of the project are locked until the end of the transaction, for
+none of them to move to another status meanwhile. Use it within a
+transaction.
+*/
+func (r *WorkflowRepository) StatusesInUse(ctx context.Context,
+	projectID string) ([]string, error) {
+
+	// locked rows can not be distinct, the statuses are deduplicated here
+	var statuses []*string
+	err := r.db.WithContext(ctx).
+		Table("tasks").
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("project_id = ?", projectID).
+		Pluck("workflow_status", &statuses).Error
+	if err != nil {
+		return nil, fmt.Errorf("gorm db pluck: %w", err)
+	}
+
+	names := []string{}
+	for _, status := range statuses {
+		if status != nil && !slices.Contains(names, *status) {
+			names = append(names, *status)
+		}
+	}
+
+	return names, nil
+}
+
+/*
+Replaces the statuses and transitions of the project workflow
+
+The tasks in a custom status are moved to the category the status has in
+the new workflow. Use it within a transaction.
+*/
+func (r *WorkflowRepository) Replace(ctx context.Context,
+	projectID string,
+	statuses []models.WorkflowStatus,
+	transitions []models.WorkflowTransition) error {
+
+	var err error
+
+	_, err = gorm.G[models.WorkflowTransition](r.db).
+		Where("project_id = ?", projectID).
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("gorm delete transitions: %w", err)
+	}
+
+	_, err = gorm.G[models.WorkflowStatus](r.db).
+		Where("project_id = ?", projectID).
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("gorm delete statuses: %w", err)
+	}
+
+	if len(statuses) > 0 {
+		err = gorm.G[models.WorkflowStatus](r.db).CreateInBatches(ctx, &statuses, 100)
+		if err != nil {
+			return fmt.Errorf("gorm create statuses: %w", err)
+		}
+	}
+
+	if len(transitions) > 0 {
+		err = gorm.G[models.WorkflowTransition](r.db).CreateInBatches(ctx, &transitions, 100)
+		if err != nil {
+			return fmt.Errorf("gorm create transitions: %w", err)
+		}
+	}
+
+	err = r.db.WithContext(ctx).
+		Exec(`UPDATE tasks AS t SET status = ws.category 
+			FROM workflow_statuses AS ws 
+			WHERE t.project_id = ? AND ws.project_id = t.project_id AND ws.name = t.workflow_status`,
+			projectID).Error
+	if err != nil {
+		return fmt.Errorf("gorm db exec update task statuses: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package workflows
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/ptracker/testhelpers/fixtures"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var USER_ONE, USER_TWO string
+
+type workflowRepositoryTestSuite struct {
+	suite.Suite
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	fixtures    *fixtures.Fixtures
+	repo        *WorkflowRepository
+	ctx         context.Context
+}
+
+func (suite *workflowRepositoryTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx = context.Background()
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.repo = NewWorkflowRepository(suite.db)
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.fixtures = fixtures.New(suite.ctx, suite.db)
+
+	USER_ONE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_TWO = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+}
+
+func (suite *workflowRepositoryTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM projects").Error
+	suite.Require().NoError(err)
+}
+
+func TestWorkflowRepository(t *testing.T) {
+	suite.Run(t, new(workflowRepositoryTestSuite))
+}
+
+func (suite *workflowRepositoryTestSuite) TestListStatuses() {
+	t := suite.T()
+
+	t.Run("should get empty list of statuses", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		statuses, err := suite.repo.ListStatuses(suite.ctx, p)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(0, len(statuses))
+	})
+	t.Run("should get statuses in order of their position", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertWorkflowStatus(fixtures.GetWorkflowStatusRow(p, "Review", core.TASK_STATUS_ONGOING, 1))
+		suite.fixtures.InsertWorkflowStatus(fixtures.GetWorkflowStatusRow(p, "Todo", core.TASK_STATUS_UNASSIGNED, 0))
+
+		statuses, err := suite.repo.ListStatuses(suite.ctx, p)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, len(statuses))
+		suite.Require().Equal("Todo", statuses[0].Name)
+		suite.Require().Equal("Review", statuses[1].Name)
+	})
+}
+
+func (suite *workflowRepositoryTestSuite) TestGetStatus() {
+	t := suite.T()
+
+	t.Run("should get status with its category", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertWorkflowStatus(fixtures.GetWorkflowStatusRow(p, "Review", core.TASK_STATUS_ONGOING, 0))
+
+		status, err := suite.repo.GetStatus(suite.ctx, p, "Review")
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(core.TASK_STATUS_ONGOING, status.Category.String)
+	})
+	t.Run("should not find status of another project", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		p2 := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertWorkflowStatus(fixtures.GetWorkflowStatusRow(p2, "Review", core.TASK_STATUS_ONGOING, 0))
+
+		_, err := suite.repo.GetStatus(suite.ctx, p, "Review")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
+
+func (suite *workflowRepositoryTestSuite) TestStatusesInUse() {
+	t := suite.T()
+
+	t.Run("should get custom statuses of tasks", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertWorkflowStatus(fixtures.GetWorkflowStatusRow(p, "Review", core.TASK_STATUS_ONGOING, 0))
+		review := "Review"
+		task := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		task.WorkflowStatus = &review
+		suite.fixtures.InsertTask(task)
+		suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING))
+
+		names, err := suite.repo.StatusesInUse(suite.ctx, p)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal([]string{"Review"}, names)
+	})
+}
+
+func (suite *workflowRepositoryTestSuite) TestReplace() {
+	t := suite.T()
+
+	t.Run("should replace statuses and transitions", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertWorkflowStatus(fixtures.GetWorkflowStatusRow(p, "Backlog", core.TASK_STATUS_UNASSIGNED, 0))
+
+		err := suite.repo.Replace(suite.ctx, p,
+			[]models.WorkflowStatus{
+				fixtures.GetWorkflowStatusRow(p, "Todo", core.TASK_STATUS_UNASSIGNED, 0),
+				fixtures.GetWorkflowStatusRow(p, "Doing", core.TASK_STATUS_ONGOING, 1),
+			},
+			[]models.WorkflowTransition{
+				fixtures.GetWorkflowTransitionRow(p, "Todo", "Doing"),
+			})
+		statuses, _ := suite.repo.ListStatuses(suite.ctx, p)
+		transitions, _ := suite.repo.ListTransitions(suite.ctx, p)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, len(statuses))
+		suite.Require().Equal("Todo", statuses[0].Name)
+		suite.Require().Equal(1, len(transitions))
+		suite.Require().Equal("Doing", transitions[0].ToStatus)
+	})
+	t.Run("should move tasks to the new category of their status", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertWorkflowStatus(fixtures.GetWorkflowStatusRow(p, "Review", core.TASK_STATUS_ONGOING, 0))
+		review := "Review"
+		task := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		task.WorkflowStatus = &review
+		taskID := suite.fixtures.InsertTask(task)
+
+		err := suite.repo.Replace(suite.ctx, p,
+			[]models.WorkflowStatus{
+				fixtures.GetWorkflowStatusRow(p, "Review", core.TASK_STATUS_COMPLETED, 0),
+			}, nil)
+		updated, _ := gorm.G[models.Task](suite.db).Where("id = ?", taskID).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(core.TASK_STATUS_COMPLETED, updated.Status.String)
+	})
+}
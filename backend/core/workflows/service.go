@@ -0,0 +1,179 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/members"
+	"github.com/ptracker/models"
+	"gorm.io/gorm"
+)
+
+type Status struct {
+	Name     string `json:"name" validate:"required"`
+	Category string `json:"category" validate:"required"`
+}
+
+type Transition struct {
+	From string `json:"from" validate:"required"`
+	To   string `json:"to" validate:"required"`
+}
+
+/*
+Custom statuses of a project and the moves allowed between them
+
+A project without statuses uses the built-in task statuses. A workflow
+without transitions lets the tasks move between any of its statuses.
+*/
+type Workflow struct {
+	Statuses    []Status     `json:"statuses" validate:"dive"`
+	Transitions []Transition `json:"transitions" validate:"dive"`
+}
+
+type WorkflowService struct {
+	txManager    *core.TxManager
+	workflowRepo *WorkflowRepository
+	memberRepo   *members.MemberRepository
+}
+
+func NewWorkflowService(txManager *core.TxManager,
+	workflowRepo *WorkflowRepository,
+	memberRepo *members.MemberRepository) *WorkflowService {
+	return &WorkflowService{
+		txManager:    txManager,
+		workflowRepo: workflowRepo,
+		memberRepo:   memberRepo,
+	}
+}
+
+func (s *WorkflowService) Get(ctx context.Context,
+	projectID, userID string) (*Workflow, error) {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_VIEW_PROJECT)
+	if err != nil {
+		return nil, fmt.Errorf("authorize view project: %w", err)
+	}
+
+	statuses, err := s.workflowRepo.ListStatuses(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("workflow repository list statuses: %w", err)
+	}
+
+	transitions, err := s.workflowRepo.ListTransitions(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("workflow repository list transitions: %w", err)
+	}
+
+	workflow := Workflow{
+		Statuses:    []Status{},
+		Transitions: []Transition{},
+	}
+	for _, r := range statuses {
+		workflow.Statuses = append(workflow.Statuses, Status{
+			Name:     r.Name,
+			Category: r.Category.String,
+		})
+	}
+	for _, r := range transitions {
+		workflow.Transitions = append(workflow.Transitions, Transition{
+			From: r.FromStatus,
+			To:   r.ToStatus,
+		})
+	}
+
+	return &workflow, nil
+}
+
+func (s *WorkflowService) Replace(ctx context.Context,
+	projectID, userID string,
+	workflow Workflow) error {
+
+	var err error
+
+	err = core.Authorize(ctx, s.memberRepo, projectID, userID,
+		core.PERMISSION_MANAGE_WORKFLOW)
+	if err != nil {
+		return fmt.Errorf("authorize manage workflow: %w", err)
+	}
+
+	statuses := []models.WorkflowStatus{}
+	names := []string{}
+	for i, status := range workflow.Statuses {
+		name := strings.Trim(status.Name, " ")
+		if name == "" || slices.Contains(names, name) {
+			return core.ErrInvalidValue
+		}
+
+		if !slices.Contains([]string{
+			core.TASK_STATUS_UNASSIGNED,
+			core.TASK_STATUS_ONGOING,
+			core.TASK_STATUS_COMPLETED,
+			core.TASK_STATUS_ABANDONED,
+		}, status.Category) {
+			return core.ErrInvalidValue
+		}
+
+		names = append(names, name)
+		statuses = append(statuses, models.WorkflowStatus{
+			ProjectID: projectID,
+			Name:      name,
+			Category:  models.TaskStatus{String: status.Category},
+			Position:  i,
+		})
+	}
+
+	transitions := []models.WorkflowTransition{}
+	for _, transition := range workflow.Transitions {
+		from := strings.Trim(transition.From, " ")
+		to := strings.Trim(transition.To, " ")
+		if from == to || !slices.Contains(names, from) || !slices.Contains(names, to) {
+			return core.ErrInvalidValue
+		}
+
+		if slices.ContainsFunc(transitions, func(t models.WorkflowTransition) bool {
+			return t.FromStatus == from && t.ToStatus == to
+		}) {
+			return core.ErrDuplicate
+		}
+
+		transitions = append(transitions, models.WorkflowTransition{
+			ProjectID:  projectID,
+			FromStatus: from,
+			ToStatus:   to,
+		})
+	}
+
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		workflowRepo := s.workflowRepo.WithTx(tx)
+
+		// statuses can not be removed while there are tasks in them, the
+		// tasks stay locked until the workflow is replaced
+		inUse, err := workflowRepo.StatusesInUse(ctx, projectID)
+		if err != nil {
+			return fmt.Errorf("workflow repository statuses in use: %w", err)
+		}
+		for _, name := range inUse {
+			if !slices.Contains(names, name) {
+				return core.ErrConflict
+			}
+		}
+
+		err = workflowRepo.Replace(ctx, projectID, statuses, transitions)
+		if err != nil {
+			return fmt.Errorf("workflow repository replace: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("txManager WithTx: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package workflows
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/members"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/ptracker/testhelpers/fixtures"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type workflowServiceTestSuite struct {
+	suite.Suite
+	ctx         context.Context
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	fixtures    *fixtures.Fixtures
+	service     *WorkflowService
+}
+
+func TestWorkflowService(t *testing.T) {
+	suite.Run(t, new(workflowServiceTestSuite))
+}
+
+func (suite *workflowServiceTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx = context.Background()
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	txManager := core.NewTxManager(suite.db)
+	workflowRepo := NewWorkflowRepository(suite.db)
+	memberRepo := members.NewMemberRepository(suite.db)
+	suite.service = NewWorkflowService(txManager, workflowRepo, memberRepo)
+
+	suite.fixtures = fixtures.New(suite.ctx, suite.db)
+
+	USER_ONE = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+	USER_TWO = suite.fixtures.InsertUser(fixtures.RandomUserRow())
+}
+
+func (suite *workflowServiceTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM projects").Error
+	suite.Require().NoError(err)
+}
+
+func sampleWorkflow() Workflow {
+	return Workflow{
+		Statuses: []Status{
+			{Name: "Todo", Category: core.TASK_STATUS_UNASSIGNED},
+			{Name: "Doing", Category: core.TASK_STATUS_ONGOING},
+			{Name: "Review", Category: core.TASK_STATUS_ONGOING},
+			{Name: "Done", Category: core.TASK_STATUS_COMPLETED},
+		},
+		Transitions: []Transition{
+			{From: "Todo", To: "Doing"},
+			{From: "Doing", To: "Review"},
+			{From: "Review", To: "Done"},
+		},
+	}
+}
+
+func (suite *workflowServiceTestSuite) TestGet() {
+	t := suite.T()
+
+	t.Run("should get empty workflow", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		workflow, err := suite.service.Get(suite.ctx, p, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().NotNil(workflow.Statuses)
+		suite.Require().Equal(0, len(workflow.Statuses))
+		suite.Require().NotNil(workflow.Transitions)
+	})
+	t.Run("should get workflow as viewer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_VIEWER))
+		suite.fixtures.InsertWorkflowStatus(fixtures.GetWorkflowStatusRow(p, "Todo", core.TASK_STATUS_UNASSIGNED, 0))
+
+		workflow, err := suite.service.Get(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(workflow.Statuses))
+		suite.Require().Equal(core.TASK_STATUS_UNASSIGNED, workflow.Statuses[0].Category)
+	})
+	t.Run("should be forbidden for non-member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, err := suite.service.Get(suite.ctx, p, USER_TWO)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+}
+
+func (suite *workflowServiceTestSuite) TestReplace() {
+	t := suite.T()
+
+	t.Run("should replace workflow as owner", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.service.Replace(suite.ctx, p, USER_ONE, sampleWorkflow())
+		workflow, _ := suite.service.Get(suite.ctx, p, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(4, len(workflow.Statuses))
+		suite.Require().Equal("Todo", workflow.Statuses[0].Name)
+		suite.Require().Equal(3, len(workflow.Transitions))
+	})
+	t.Run("should be forbidden for maintainer", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MAINTAINER))
+
+		err := suite.service.Replace(suite.ctx, p, USER_TWO, sampleWorkflow())
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+	})
+	t.Run("should be invalid with unknown category", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		workflow := sampleWorkflow()
+		workflow.Statuses[0].Category = "Blocked"
+
+		err := suite.service.Replace(suite.ctx, p, USER_ONE, workflow)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should be invalid with duplicate status names", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		workflow := sampleWorkflow()
+		workflow.Statuses[1].Name = "Todo"
+
+		err := suite.service.Replace(suite.ctx, p, USER_ONE, workflow)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should be invalid with transition to unknown status", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		workflow := sampleWorkflow()
+		workflow.Transitions = append(workflow.Transitions, Transition{From: "Done", To: "Archived"})
+
+		err := suite.service.Replace(suite.ctx, p, USER_ONE, workflow)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrInvalidValue)
+	})
+	t.Run("should get conflict error when removing status in use", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertWorkflowStatus(fixtures.GetWorkflowStatusRow(p, "Blocked", core.TASK_STATUS_ONGOING, 0))
+		blocked := "Blocked"
+		task := fixtures.RandomTaskRow(p, core.TASK_STATUS_ONGOING)
+		task.WorkflowStatus = &blocked
+		suite.fixtures.InsertTask(task)
+
+		err := suite.service.Replace(suite.ctx, p, USER_ONE, sampleWorkflow())
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrConflict)
+	})
+}
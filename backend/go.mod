@@ -3,6 +3,7 @@ module github.com/ptracker
 go 1.25.0
 
 require (
+	github.com/coder/websocket v1.8.14
 	github.com/go-playground/validator/v10 v10.30.1
 	github.com/golang-jwt/jwt/v5 v5.3.1
 	github.com/google/uuid v1.6.0
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+/*
+Entry of the project activity feed
+
+ActorID is the user who did the action, Body holds the details of the
+action and depends on the Type, see the activity package for its shapes.
+*/
+type Activity struct {
+	ID        string `gorm:"primaryKey"`
+	ProjectID string `gorm:"index:idx_activity_project"`
+	ActorID   string
+	Type      string
+	Body      JSON
+	CreatedAt time.Time
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+/*
+User banned from a project
+
+A banned user can not request to join the project again until the owner
+lifts the ban.
+*/
+type Ban struct {
+	ProjectID string `gorm:"primaryKey"`
+	UserID    string `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
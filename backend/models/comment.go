@@ -4,12 +4,24 @@ import (
 	"time"
 )
 
+/*
+Comment on a task
+
+Replies point to a top level comment through ParentID, replies of replies
+are not kept. Deleted comments keep their row with an empty Content so the
+replies stay in place.
+*/
 type Comment struct {
-	ID        string `gorm:"primaryKey"`
-	ProjectID string `gorm:"index:idx_project_task_comment"`
-	TaskID    string `gorm:"index:idx_project_task_comment"`
+	ID        string  `gorm:"primaryKey"`
+	ProjectID string  `gorm:"index:idx_project_task_comment"`
+	TaskID    string  `gorm:"index:idx_project_task_comment"`
+	ParentID  *string `gorm:"index:idx_comment_parent"`
 	UserID    string
 	Content   string
+	EditedAt  *time.Time
+	DeletedAt *time.Time
 	CreatedAt time.Time
 	UpdatedAt time.Time
+
+	Replies []Comment `gorm:"foreignKey:ParentID;constraint:OnDelete:CASCADE"`
 }
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+/*
+Project scoped label used to categorize the tasks of the project
+
+Color is a hex RGB value like #1f6feb, the name is unique in the project.
+*/
+type Label struct {
+	ID        string `gorm:"primaryKey"`
+	ProjectID string `gorm:"uniqueIndex:idx_label_project_name"`
+	Name      string `gorm:"uniqueIndex:idx_label_project_name"`
+	Color     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	TaskLabels []TaskLabel `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// Many-to-many join between tasks and labels
+type TaskLabel struct {
+	TaskID    string `gorm:"primaryKey"`
+	LabelID   string `gorm:"primaryKey;index:idx_task_label_label"`
+	CreatedAt time.Time
+}
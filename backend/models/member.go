@@ -34,9 +34,9 @@ func (r *UserRole) Scan(value any) error {
 }
 
 // Returns the role value of the UserRole
-// Returns error if role is not Owner/Member
+// Returns error if role is not Owner/Maintainer/Member/Viewer
 func (r UserRole) Value() (driver.Value, error) {
-	if r.String != core.ROLE_OWNER && r.String != core.ROLE_MEMBER {
+	if !core.IsValidRole(r.String) {
 		return nil, fmt.Errorf("Invalid role %s", r.String)
 	}
 
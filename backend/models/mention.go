@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+/*
+Member mentioned with @username in a comment or a task description
+
+SourceID is the id of the comment, or the id of the task for its
+description. A member is mentioned once per source, so editing the source
+only notifies the newly mentioned members.
+*/
+type Mention struct {
+	SourceID  string `gorm:"primaryKey"`
+	UserID    string `gorm:"primaryKey"`
+	ProjectID string `gorm:"index:idx_mention_project"`
+	TaskID    string `gorm:"index:idx_mention_task"`
+	CreatedAt time.Time
+}
@@ -53,6 +53,12 @@ type Notification struct {
 	Body      JSON
 	Read      bool
 	CreatedAt time.Time
+
+	// waiting for the next daily digest, it is left out if read by then
+	DigestPending bool `gorm:"default:false"`
+
+	// digest the notification was sent in, its id keys the digest mail
+	DigestID *string `gorm:"index:idx_notification_digest"`
 }
 
 /*
@@ -85,11 +91,11 @@ Example 2: task updated
 		"updates": [
 			{
 				"to": "...",
-				"field": "Title" / "Description" / "Status"
+				"field": "Title" / "Description" / "Status" / "Priority" / "Estimate" / "StartDate" / "DueDate"
 			},
 			{
 				"to": "...",
-				"field": "Title" / "Description" / "Status"
+				"field": "Title" / "Description" / "Status" / "Priority" / "Estimate" / "StartDate" / "DueDate"
 			},
 			...
 		],
@@ -188,4 +194,131 @@ Example 5: comment added
 			...
 		}
 	}
+
+Example 6: task deleted
+	Type: task_deleted
+	Body: {
+		"project": {
+			"id": "...",
+			"name": "..."
+		},
+		"task": {
+			"id": "...",
+			"title": "..."
+		},
+		"deleter": {
+			"user_id": "...",
+			"username": "...",
+			"email": "...",
+			...
+		}
+	}
+
+Example 7: project deleted
+	Type: project_deleted
+	Body: {
+		"project": {
+			"id": "...",
+			"name": "..."
+		},
+		"deleter": {
+			"user_id": "...",
+			"username": "...",
+			"email": "...",
+			...
+		}
+	}
+
+Example 8: project updated
+	Type: project_updated
+	Body: {
+		"project": {
+			"id": "...",
+			"name": "..."
+		},
+		"updates": [
+			{
+				"to": "...",
+				"field": "Name" / "Description" / "Skills"
+			},
+			...
+		],
+		"updater": {
+			"user_id": "...",
+			"username": "...",
+			"email": "...",
+			...
+		}
+	}
+
+Example 9: member removed
+	Type: member_removed
+	Body: {
+		"project": {
+			"id": "...",
+			"name": "..."
+		},
+		"remover": {
+			"user_id": "...",
+			"username": "...",
+			"email": "...",
+			...
+		}
+	}
+
+Example 10: member left
+	Type: member_left
+	Body: {
+		"project": {
+			"id": "...",
+			"name": "..."
+		},
+		"member": {
+			"user_id": "...",
+			"username": "...",
+			"email": "...",
+			...
+		}
+	}
+
+Example 11: ownership transferred
+	Type: ownership_transferred
+	Body: {
+		"project": {
+			"id": "...",
+			"name": "..."
+		},
+		"previous_owner": {
+			"user_id": "...",
+			"username": "...",
+			"email": "...",
+			...
+		},
+		"new_owner": {
+			"user_id": "...",
+			"username": "...",
+			"email": "...",
+			...
+		}
+	}
+
+Example 12: mentioned in a comment or a task description
+	Type: mentioned
+	Body: {
+		"project": {
+			"id": "...",
+			"name": "..."
+		},
+		"task": {
+			"id": "...",
+			"title": "..."
+		},
+		"mentioner": {
+			"user_id": "...",
+			"username": "...",
+			"email": "...",
+			...
+		},
+		"snippet": "..."
+	}
 */
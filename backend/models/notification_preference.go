@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+/*
+Channel user UserID gets the notifications of type Type in project
+ProjectID through, one of in_app, email, digest or none
+
+A missing preference means in_app.
+*/
+type NotificationPreference struct {
+	UserID    string `gorm:"primaryKey"`
+	ProjectID string `gorm:"primaryKey"`
+	Type      string `gorm:"primaryKey"`
+	Channel   string
+	UpdatedAt time.Time
+}
+
+// Project muted by user UserID, none of its notifications reach the user
+type ProjectMute struct {
+	UserID    string `gorm:"primaryKey"`
+	ProjectID string `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
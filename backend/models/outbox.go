@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+/*
+Domain event waiting to be dispatched, written in the same transaction as
+the change it describes
+
+A claimed event is hidden from the other dispatchers until AvailableAt, a
+failed one is retried from AvailableAt and moved to the dead letters once
+it runs out of attempts.
+*/
+type OutboxEvent struct {
+	ID          string `gorm:"primaryKey"`
+	Type        string
+	Payload     JSON
+	Attempts    int       `gorm:"default:0"`
+	AvailableAt time.Time `gorm:"index:idx_outbox_event_available_at"`
+	LastError   *string
+	CreatedAt   time.Time
+}
+
+// Event that could not be dispatched, kept with the error it last failed with
+type DeadLetter struct {
+	ID             string `gorm:"primaryKey"` // id of the outbox event
+	Type           string
+	Payload        JSON
+	Attempts       int
+	Error          string
+	EventCreatedAt time.Time
+	CreatedAt      time.Time
+}
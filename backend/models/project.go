@@ -12,10 +12,20 @@ type Project struct {
 	OwnerID     string
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
+	Version     int `gorm:"default:1"` // bumped by every update, sent as the ETag
 
 	Tasks        []Task        `gorm:"constraint:OnDelete:CASCADE"`
 	Roles        []Member      `gorm:"constraint:OnDelete:CASCADE"`
 	JoinRequests []JoinRequest `gorm:"constraint:OnDelete:CASCADE"`
 	Assignees    []Assignee    `gorm:"constraint:OnDelete:CASCADE"`
 	Comments     []Comment     `gorm:"constraint:OnDelete:CASCADE"`
+	Bans         []Ban         `gorm:"constraint:OnDelete:CASCADE"`
+	Labels       []Label       `gorm:"constraint:OnDelete:CASCADE"`
+	Activities   []Activity    `gorm:"constraint:OnDelete:CASCADE"`
+	Mutes        []ProjectMute `gorm:"constraint:OnDelete:CASCADE"`
+
+	WorkflowStatuses    []WorkflowStatus     `gorm:"constraint:OnDelete:CASCADE"`
+	WorkflowTransitions []WorkflowTransition `gorm:"constraint:OnDelete:CASCADE"`
+
+	NotificationPreferences []NotificationPreference `gorm:"constraint:OnDelete:CASCADE"`
 }
@@ -49,15 +49,74 @@ func (r TaskStatus) Value() (driver.Value, error) {
 	return r.String, nil
 }
 
+/*
+Gorm Custom Data Type: TaskPriority
+
+Implements the sql.Scanner and sql.Valuer the same way as TaskStatus.
+*/
+type TaskPriority struct {
+	String string
+}
+
+// Extracts the priority from TaskPriority
+// Returns error if priority is not a valid string
+func (r *TaskPriority) Scan(value any) error {
+	priority, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("Failed to extract task priority value %v as string", value)
+	}
+
+	r.String = priority
+	return nil
+}
+
+// Returns the priority value of the TaskPriority
+// Returns error if priority is not Low/Medium/High/Urgent
+func (r TaskPriority) Value() (driver.Value, error) {
+	if !slices.Contains([]string{
+		core.TASK_PRIORITY_LOW,
+		core.TASK_PRIORITY_MEDIUM,
+		core.TASK_PRIORITY_HIGH,
+		core.TASK_PRIORITY_URGENT,
+	}, r.String) {
+		return nil, fmt.Errorf("Invalid task priority %s", r.String)
+	}
+
+	return r.String, nil
+}
+
 type Task struct {
-	ID          string `gorm:"primaryKey"`
-	ProjectID   string `gorm:"index:idx_task_project"`
-	Title       string
-	Description *string
-	Status      TaskStatus
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-
-	Assignees []Assignee `gorm:"constraint:OnDelete:CASCADE"`
-	Comments  []Comment  `gorm:"constraint:OnDelete:CASCADE"`
+	ID             string  `gorm:"primaryKey"`
+	ProjectID      string  `gorm:"index:idx_task_project"`
+	ParentID       *string `gorm:"index:idx_task_parent"`
+	Title          string
+	Description    *string
+	Status         TaskStatus
+	WorkflowStatus *string      // custom status of the project workflow
+	Priority       TaskPriority `gorm:"default:Medium"`
+	Estimate       int          `gorm:"default:0"`
+	StartDate      *time.Time
+	DueDate        *time.Time `gorm:"index:idx_task_due_date"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Version        int `gorm:"default:1"` // bumped by every update, sent as the ETag
+
+	Assignees []Assignee  `gorm:"constraint:OnDelete:CASCADE"`
+	Comments  []Comment   `gorm:"constraint:OnDelete:CASCADE"`
+	Labels    []TaskLabel `gorm:"constraint:OnDelete:CASCADE"`
+	Events    []TaskEvent `gorm:"constraint:OnDelete:CASCADE"`
+	Mentions  []Mention   `gorm:"constraint:OnDelete:CASCADE"`
+
+	// subtasks are kept as top level tasks when their parent is deleted
+	Subtasks []Task `gorm:"foreignKey:ParentID;constraint:OnDelete:SET NULL"`
+
+	Blockers []TaskDependency `gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE"`
+	Blocking []TaskDependency `gorm:"foreignKey:BlockerID;constraint:OnDelete:CASCADE"`
+}
+
+// Task can not start until its blocker is Completed or Abandoned
+type TaskDependency struct {
+	TaskID    string `gorm:"primaryKey"`
+	BlockerID string `gorm:"primaryKey;index:idx_task_dependency_blocker"`
+	CreatedAt time.Time
 }
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+/*
+Entry of the task history
+
+Field, OldValue and NewValue are set for the updated fields, NewValue
+alone for the added assignees and the comments, OldValue alone for the
+removed assignees.
+*/
+type TaskEvent struct {
+	ID        string `gorm:"primaryKey"`
+	ProjectID string `gorm:"index:idx_task_event_project"`
+	TaskID    string `gorm:"index:idx_task_event_task"`
+	ActorID   string
+	Type      string
+	Field     *string
+	OldValue  *string
+	NewValue  *string
+	CreatedAt time.Time
+}
@@ -20,4 +20,5 @@ type User struct {
 	Comments       []Comment       `gorm:"constraint:OnDelete:CASCADE"`
 	Assignees      []Assignee      `gorm:"constraint:OnDelete:CASCADE"`
 	Notifications  []Notification  `gorm:"constraint:OnDelete:CASCADE"`
+	Bans           []Ban           `gorm:"constraint:OnDelete:CASCADE"`
 }
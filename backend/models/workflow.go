@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+/*
+Custom task status of a project
+
+Every custom status falls under one of the built-in task statuses, its
+category. Tasks keep the category in their status column, so the
+summaries and filters over the built-in statuses work the same with or
+without a workflow.
+*/
+type WorkflowStatus struct {
+	ProjectID string `gorm:"primaryKey"`
+	Name      string `gorm:"primaryKey"`
+	Category  TaskStatus
+	Position  int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Allowed move of a task from one custom status to another
+type WorkflowTransition struct {
+	ProjectID  string `gorm:"primaryKey"`
+	FromStatus string `gorm:"primaryKey"`
+	ToStatus   string `gorm:"primaryKey"`
+	CreatedAt  time.Time
+}
@@ -0,0 +1,139 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/ptracker/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	BROKER_CHANNEL_PREFIX = "notifications:"
+
+	// notifications buffered for a slow subscriber before it is dropped
+	SUBSCRIBER_BUFFER = 32
+)
+
+func getUserChannel(userID string) string {
+	return BROKER_CHANNEL_PREFIX + userID
+}
+
+/*
+Delivers the created notifications to the live streams of their users
+
+Notifications are published to the redis channel of the user, every server
+instance runs a single pattern subscription and fans them out to the
+streams connected to it.
+*/
+type Broker struct {
+	redis *redis.Client
+
+	mu          sync.Mutex
+	subscribers map[string]map[chan Notification]struct{}
+}
+
+func NewBroker(redis *redis.Client) *Broker {
+	return &Broker{
+		redis:       redis,
+		subscribers: map[string]map[chan Notification]struct{}{},
+	}
+}
+
+func (b *Broker) Publish(ctx context.Context,
+	n models.Notification) error {
+
+	payload, err := json.Marshal(toNotification(n))
+	if err != nil {
+		return fmt.Errorf("json marshal: %w", err)
+	}
+
+	err = b.redis.Publish(ctx, getUserChannel(n.UserID), payload).Err()
+	if err != nil {
+		return fmt.Errorf("redis publish: %w", err)
+	}
+
+	return nil
+}
+
+/*
+Registers a live stream of user userID
+
+The channel is closed by unsubscribe, or by the broker when the stream does
+not keep up, the client is then expected to reconnect and resume.
+*/
+func (b *Broker) Subscribe(userID string) (<-chan Notification, func()) {
+	ch := make(chan Notification, SUBSCRIBER_BUFFER)
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = map[chan Notification]struct{}{}
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(userID, ch)
+	}
+
+	return ch, unsubscribe
+}
+
+// Removes and closes the subscriber, mu must be held
+func (b *Broker) remove(userID string, ch chan Notification) {
+	if _, ok := b.subscribers[userID][ch]; !ok {
+		return
+	}
+
+	delete(b.subscribers[userID], ch)
+	if len(b.subscribers[userID]) == 0 {
+		delete(b.subscribers, userID)
+	}
+	close(ch)
+}
+
+func (b *Broker) deliver(userID string, n Notification) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[userID] {
+		select {
+		case ch <- n:
+		default:
+			b.remove(userID, ch)
+		}
+	}
+}
+
+// Receives the notifications of all users until ctx is done
+func (b *Broker) Run(ctx context.Context) error {
+	pubsub := b.redis.PSubscribe(ctx, BROKER_CHANNEL_PREFIX+"*")
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return fmt.Errorf("redis pubsub channel closed")
+			}
+
+			var n Notification
+			err := json.Unmarshal([]byte(msg.Payload), &n)
+			if err != nil {
+				log.Printf("[ERROR] broker json unmarshal: %s", err)
+				continue
+			}
+
+			b.deliver(strings.TrimPrefix(msg.Channel, BROKER_CHANNEL_PREFIX), n)
+		}
+	}
+}
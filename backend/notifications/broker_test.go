@@ -0,0 +1,119 @@
+package notifications
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ptracker/models"
+	"github.com/ptracker/testhelpers"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/suite"
+)
+
+type brokerTestSuite struct {
+	suite.Suite
+	redis  *redis.Client
+	broker *Broker
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (suite *brokerTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx, suite.cancel = context.WithCancel(context.Background())
+
+	redisContainer, err := testhelpers.CreateRedisContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	connString, err := redisContainer.ConnectionString(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opt, err := redis.ParseURL(connString)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.redis = redis.NewClient(opt)
+
+	suite.broker = NewBroker(suite.redis)
+	go suite.broker.Run(suite.ctx)
+
+	// the pattern subscription is ready once redis counts it
+	for range 50 {
+		n, _ := suite.redis.PubSubNumPat(suite.ctx).Result()
+		if n > 0 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func (suite *brokerTestSuite) TearDownSuite() {
+	suite.cancel()
+}
+
+func TestBroker(t *testing.T) {
+	suite.Run(t, new(brokerTestSuite))
+}
+
+func (suite *brokerTestSuite) TestPublish() {
+	t := suite.T()
+
+	t.Run("should deliver notification to the user stream", func(t *testing.T) {
+		userID := uuid.NewString()
+		live, unsubscribe := suite.broker.Subscribe(userID)
+		defer unsubscribe()
+
+		n := models.Notification{
+			ID:     uuid.NewString(),
+			UserID: userID,
+			Type:   NT_TASK_ADDED,
+			Body:   models.JSON(`{"task":{"id":"1"}}`),
+		}
+		err := suite.broker.Publish(suite.ctx, n)
+		suite.Require().NoError(err)
+
+		select {
+		case received := <-live:
+			suite.Require().Equal(n.ID, received.ID)
+			suite.Require().Equal(NT_TASK_ADDED, received.Type)
+		case <-time.After(5 * time.Second):
+			suite.Fail("notification was not delivered")
+		}
+	})
+
+	t.Run("should not deliver notification of another user", func(t *testing.T) {
+		live, unsubscribe := suite.broker.Subscribe(uuid.NewString())
+		defer unsubscribe()
+
+		err := suite.broker.Publish(suite.ctx, models.Notification{
+			ID:     uuid.NewString(),
+			UserID: uuid.NewString(),
+			Type:   NT_TASK_ADDED,
+		})
+		suite.Require().NoError(err)
+
+		select {
+		case <-live:
+			suite.Fail("notification of another user was delivered")
+		case <-time.After(500 * time.Millisecond):
+		}
+	})
+
+	t.Run("should close the stream when unsubscribed", func(t *testing.T) {
+		live, unsubscribe := suite.broker.Subscribe(uuid.NewString())
+		unsubscribe()
+		unsubscribe()
+
+		_, ok := <-live
+		suite.Require().False(ok)
+	})
+}
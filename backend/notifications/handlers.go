@@ -0,0 +1,115 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/projects"
+	"github.com/ptracker/core/tasks"
+	"github.com/ptracker/outbox"
+)
+
+// Decodes the payload of an event, a malformed payload can not be retried
+func decode[T any](payload []byte) (T, error) {
+	var e T
+
+	err := json.Unmarshal(payload, &e)
+	if err != nil {
+		return e, fmt.Errorf("json unmarshal: %w: %w", core.ErrInvalidValue, err)
+	}
+
+	return e, nil
+}
+
+type eventKey struct{}
+
+// Returns the outbox event handled in ctx, if any
+func eventID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(eventKey{}).(string)
+	return id, ok
+}
+
+/*
+Builds an outbox handler decoding the payload of the event into T
+
+The event is kept in the context of f, for notify to complete it with the
+notifications it writes.
+*/
+func handle[T any](f func(ctx context.Context, e T) error) outbox.Handler {
+	return func(ctx context.Context, id string, payload []byte) error {
+		e, err := decode[T](payload)
+		if err != nil {
+			return err
+		}
+
+		return f(context.WithValue(ctx, eventKey{}, id), e)
+	}
+}
+
+// Registers the handlers notifying the users of the outbox events
+func (s *NotificationService) Register(d *outbox.Dispatcher) {
+	d.Handle(outbox.EV_TASK_ADDED, handle(func(ctx context.Context, e outbox.TaskAdded) error {
+		return s.TaskAdded(ctx, e.ProjectID, e.TaskID, e.CreatorID)
+	}))
+
+	d.Handle(outbox.EV_TASK_UPDATED, handle(func(ctx context.Context, e outbox.TaskUpdated) error {
+		return s.TaskUpdated(ctx, e.ProjectID, e.TaskID,
+			e.Title, e.Description, e.Status, e.Priority,
+			e.Estimate, e.StartDate, e.DueDate, e.UpdaterID)
+	}))
+
+	d.Handle(outbox.EV_TASK_DELETED, handle(func(ctx context.Context, e outbox.TaskDeleted) error {
+		return s.TaskDeleted(ctx, &tasks.DeletedTask{
+			ID:          e.TaskID,
+			ProjectID:   e.ProjectID,
+			Title:       e.Title,
+			AssigneeIDs: e.AssigneeIDs,
+		}, e.DeleterID)
+	}))
+
+	d.Handle(outbox.EV_ASSIGNEE_UPDATED, handle(func(ctx context.Context, e outbox.AssigneeUpdated) error {
+		return s.AssigneeUpdated(ctx, e.ProjectID, e.TaskID, e.AssigneeID, e.Added)
+	}))
+
+	d.Handle(outbox.EV_COMMENT_ADDED, handle(func(ctx context.Context, e outbox.CommentAdded) error {
+		return s.CommentAdded(ctx, e.ProjectID, e.TaskID, e.CommenterID)
+	}))
+
+	d.Handle(outbox.EV_MENTIONED, handle(func(ctx context.Context, e outbox.Mentioned) error {
+		return s.Mentioned(ctx, e.ProjectID, e.TaskID, e.SourceID, e.MentionerID, e.Content)
+	}))
+
+	d.Handle(outbox.EV_JOIN_REQUESTED, handle(func(ctx context.Context, e outbox.JoinRequested) error {
+		return s.JoinRequested(ctx, e.ProjectID, e.RequestorID)
+	}))
+
+	d.Handle(outbox.EV_JOIN_RESPONDED, handle(func(ctx context.Context, e outbox.JoinResponded) error {
+		return s.JoinResponded(ctx, e.ProjectID, e.RequestorID, e.ResponderID, e.Status)
+	}))
+
+	d.Handle(outbox.EV_PROJECT_UPDATED, handle(func(ctx context.Context, e outbox.ProjectUpdated) error {
+		return s.ProjectUpdated(ctx, e.ProjectID, e.Name, e.Description, e.Skills, e.UpdaterID)
+	}))
+
+	d.Handle(outbox.EV_PROJECT_DELETED, handle(func(ctx context.Context, e outbox.ProjectDeleted) error {
+		return s.ProjectDeleted(ctx, &projects.DeletedProject{
+			ID:        e.ProjectID,
+			Name:      e.Name,
+			MemberIDs: e.MemberIDs,
+		}, e.DeleterID)
+	}))
+
+	d.Handle(outbox.EV_MEMBER_REMOVED, handle(func(ctx context.Context, e outbox.MemberRemoved) error {
+		return s.MemberRemoved(ctx, e.ProjectID, e.MemberID, e.RemoverID)
+	}))
+
+	d.Handle(outbox.EV_MEMBER_LEFT, handle(func(ctx context.Context, e outbox.MemberLeft) error {
+		return s.MemberLeft(ctx, e.ProjectID, e.MemberID)
+	}))
+
+	d.Handle(outbox.EV_OWNERSHIP_TRANSFERRED, handle(func(ctx context.Context, e outbox.OwnershipTransferred) error {
+		return s.OwnershipTransferred(ctx, e.ProjectID, e.PreviousOwnerID, e.NewOwnerID)
+	}))
+}
@@ -0,0 +1,74 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/resend/resend-go/v3"
+)
+
+const (
+	MAIL_FROM     = "Arup <hello@contact.itsdeployedbyme.dpdns.org>"
+	MAIL_REPLY_TO = "hello@contact.itsdeployedbyme.dpdns.org"
+)
+
+type Mail struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+
+	// sending a mail again with the same key does nothing, empty to always send
+	IdempotencyKey string
+}
+
+// Delivers the notification mails, swapped for a LogSender to run locally
+type MailSender interface {
+	Send(ctx context.Context, m Mail) error
+}
+
+type ResendSender struct {
+	client *resend.Client
+}
+
+func NewResendSender(client *resend.Client) *ResendSender {
+	return &ResendSender{
+		client: client,
+	}
+}
+
+func (s *ResendSender) Send(ctx context.Context, m Mail) error {
+	params := &resend.SendEmailRequest{
+		From:    MAIL_FROM,
+		To:      []string{m.To},
+		Subject: m.Subject,
+		Html:    m.HTML,
+		Text:    m.Text,
+		ReplyTo: MAIL_REPLY_TO,
+	}
+
+	sent, err := s.client.Emails.SendWithOptions(ctx, params, &resend.SendEmailOptions{
+		IdempotencyKey: m.IdempotencyKey,
+	})
+	if err != nil {
+		return fmt.Errorf("email client send: %w", err)
+	}
+
+	log.Printf("[INFO] Email sent: %s\n", sent.Id)
+
+	return nil
+}
+
+// Writes the mails to the log instead of sending them
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(ctx context.Context, m Mail) error {
+	log.Printf("[INFO] Email to %s: %s\n%s\n", m.To, m.Subject, m.Text)
+
+	return nil
+}
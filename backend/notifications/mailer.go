@@ -0,0 +1,223 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/users"
+	"github.com/ptracker/models"
+	"github.com/ptracker/outbox"
+	"gorm.io/gorm"
+)
+
+// Hour of the day, in UTC, the daily digests are sent at
+const DIGEST_HOUR = 8
+
+/*
+Sends the notifications of the email and digest channels
+
+A notification on the email channel is sent from its outbox event, so a
+failed send is retried by the dispatcher. The unread notifications on the
+digest channel are batched into one mail per user every day at DIGEST_HOUR.
+*/
+type Mailer struct {
+	txManager        *core.TxManager
+	notificationRepo *NotificationRepository
+	userRepo         *users.UserRepository
+	renderer         *Renderer
+	sender           MailSender
+}
+
+func NewMailer(
+	txManager *core.TxManager,
+	notificationRepo *NotificationRepository,
+	userRepo *users.UserRepository,
+	renderer *Renderer,
+	sender MailSender,
+) *Mailer {
+	return &Mailer{
+		txManager:        txManager,
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		renderer:         renderer,
+		sender:           sender,
+	}
+}
+
+// Registers the handler sending the notifications of the email channel
+func (m *Mailer) Register(d *outbox.Dispatcher) {
+	d.Handle(outbox.EV_EMAIL_REQUESTED, handle(func(ctx context.Context, e outbox.EmailRequested) error {
+		return m.Send(ctx, e.NotificationID)
+	}))
+}
+
+// Sends the notification notificationID to its user by email
+func (m *Mailer) Send(ctx context.Context, notificationID string) error {
+	n, err := m.notificationRepo.Get(ctx, notificationID)
+	if err != nil {
+		return fmt.Errorf("notification repository Get: %w", err)
+	}
+
+	user, err := m.userRepo.Get(ctx, n.UserID)
+	if err != nil {
+		return fmt.Errorf("user repository Get: %w", err)
+	}
+
+	mail, err := m.renderer.Render(*n)
+	if err != nil {
+		return fmt.Errorf("renderer Render: %w", err)
+	}
+
+	// a retried event sends the same mail once
+	mail.To = user.Email
+	mail.IdempotencyKey = n.ID
+
+	err = m.sender.Send(ctx, *mail)
+	if err != nil {
+		return fmt.Errorf("mail sender Send: %w", err)
+	}
+
+	return nil
+}
+
+/*
+Sends the daily digest of every user with unread notifications waiting for
+it, returns the number of digests sent
+
+A digest that fails is logged and its notifications wait for the next one.
+*/
+func (m *Mailer) SendDigests(ctx context.Context) (int, error) {
+	userIDs, err := m.notificationRepo.ListDigestUsers(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("notification repository ListDigestUsers: %w", err)
+	}
+
+	sent := 0
+	for _, userID := range userIDs {
+		ok, err := m.sendDigest(ctx, userID)
+		if err != nil {
+			log.Printf("[ERROR] mailer sendDigest %s: %s", userID, err)
+			continue
+		}
+		if ok {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+/*
+Sends the digest of user userID and takes its notifications out of the
+next one
+
+The notifications are claimed in a short transaction, the other server
+instances skip them, and the digest is sent once it is committed with the
+digest id as the idempotency key. A digest that fails to send puts them
+back for the next one. Returns false when there was nothing to send.
+*/
+func (m *Mailer) sendDigest(ctx context.Context, userID string) (bool, error) {
+	digestID := uuid.NewString()
+
+	var ns []models.Notification
+	err := m.txManager.WithTx(func(tx *gorm.DB) error {
+		notificationRepo := m.notificationRepo.WithTx(tx)
+
+		var err error
+		ns, err = notificationRepo.ClaimDigest(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("notification repository ClaimDigest: %w", err)
+		}
+		if len(ns) == 0 {
+			return nil
+		}
+
+		ids := []string{}
+		for _, n := range ns {
+			ids = append(ids, n.ID)
+		}
+
+		err = notificationRepo.MarkDigest(ctx, userID, digestID, ids)
+		if err != nil {
+			return fmt.Errorf("notification repository MarkDigest: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("txManager WithTx: %w", err)
+	}
+	if len(ns) == 0 {
+		return false, nil
+	}
+
+	// none of them can be rendered, they would never be sent
+	mail, err := m.renderer.RenderDigest(ns)
+	if errors.Is(err, core.ErrInvalidValue) {
+		return false, nil
+	} else if err != nil {
+		return false, m.restoreDigest(ctx, digestID, fmt.Errorf("renderer RenderDigest: %w", err))
+	}
+
+	user, err := m.userRepo.Get(ctx, userID)
+	if err != nil {
+		return false, m.restoreDigest(ctx, digestID, fmt.Errorf("user repository Get: %w", err))
+	}
+
+	mail.To = user.Email
+	mail.IdempotencyKey = "digest:" + digestID
+
+	err = m.sender.Send(ctx, *mail)
+	if err != nil {
+		return false, m.restoreDigest(ctx, digestID, fmt.Errorf("mail sender Send: %w", err))
+	}
+
+	return true, nil
+}
+
+// Puts the notifications of the digest that failed with err back for the
+// next one, returns err
+func (m *Mailer) restoreDigest(ctx context.Context, digestID string, err error) error {
+	restoreErr := m.notificationRepo.RestoreDigest(ctx, digestID)
+	if restoreErr != nil {
+		return fmt.Errorf("%w, notification repository RestoreDigest: %w", err, restoreErr)
+	}
+
+	return err
+}
+
+// Time left from now until the next DIGEST_HOUR
+func untilNextDigest(now time.Time) time.Duration {
+	now = now.UTC()
+
+	next := time.Date(now.Year(), now.Month(), now.Day(), DIGEST_HOUR, 0, 0, 0, time.UTC)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next.Sub(now)
+}
+
+// Sends the digests every day at DIGEST_HOUR until ctx is done
+func (m *Mailer) RunDigests(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(untilNextDigest(time.Now())):
+		}
+
+		n, err := m.SendDigests(ctx)
+		if err != nil {
+			log.Printf("[ERROR] mailer SendDigests: %s", err)
+			continue
+		}
+
+		log.Printf("[INFO] %d digests sent", n)
+	}
+}
@@ -0,0 +1,230 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"testing"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/core/users"
+	"github.com/ptracker/models"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/ptracker/testhelpers/fixtures"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Keeps the mails instead of sending them, fails while err is set
+type recordingSender struct {
+	mu    sync.Mutex
+	mails []Mail
+	err   error
+}
+
+func (s *recordingSender) Send(ctx context.Context, m Mail) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.mails = append(s.mails, m)
+
+	return nil
+}
+
+func (s *recordingSender) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mails = nil
+	s.err = nil
+}
+
+type mailerTestSuite struct {
+	suite.Suite
+	ctx         context.Context
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	fixtures    *fixtures.Fixtures
+	sender      *recordingSender
+	mailer      *Mailer
+	userOne     models.User
+	userTwo     models.User
+}
+
+func TestMailer(t *testing.T) {
+	suite.Run(t, new(mailerTestSuite))
+}
+
+func (suite *mailerTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx = context.Background()
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db.AutoMigrate(&models.Notification{})
+
+	suite.sender = &recordingSender{}
+	suite.mailer = NewMailer(
+		core.NewTxManager(suite.db),
+		NewNotificationRepository(suite.db, nil),
+		users.NewUserRepository(suite.db),
+		NewRenderer("https://app.test"),
+		suite.sender,
+	)
+
+	suite.fixtures = fixtures.New(suite.ctx, suite.db)
+
+	suite.userOne = fixtures.RandomUserRow()
+	suite.fixtures.InsertUser(suite.userOne)
+	suite.userTwo = fixtures.RandomUserRow()
+	suite.fixtures.InsertUser(suite.userTwo)
+}
+
+func (suite *mailerTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM notifications").Error
+	suite.Require().NoError(err)
+
+	suite.sender.reset()
+}
+
+func taskAddedRow(userID, title string, read, digestPending bool) models.Notification {
+	n := fixtures.GetNotificationRow(userID, NT_TASK_ADDED, TaskAdded{
+		Project: ProjectBody{Name: "Tracker"},
+		Task:    TaskBody{Title: title},
+	})
+	n.Read = read
+	n.DigestPending = digestPending
+
+	return n
+}
+
+func (suite *mailerTestSuite) TestSend() {
+	t := suite.T()
+
+	t.Run("should mail the notification to its user", func(t *testing.T) {
+		n := taskAddedRow(suite.userOne.ID, "Launch", false, false)
+		suite.fixtures.InsertNotification(n)
+
+		err := suite.mailer.Send(suite.ctx, n.ID)
+		mails := suite.sender.mails
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Len(mails, 1)
+		suite.Require().Equal(suite.userOne.Email, mails[0].To)
+		suite.Require().Equal(n.ID, mails[0].IdempotencyKey)
+		suite.Require().Contains(mails[0].Text, `"Launch"`)
+	})
+
+	t.Run("should return not found for unknown notification", func(t *testing.T) {
+		err := suite.mailer.Send(suite.ctx, "unknown")
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+	})
+}
+
+func (suite *mailerTestSuite) TestSendDigests() {
+	t := suite.T()
+
+	t.Run("should send one digest per user with the unread notifications", func(t *testing.T) {
+		suite.fixtures.InsertNotification(taskAddedRow(suite.userOne.ID, "First", false, true))
+		suite.fixtures.InsertNotification(taskAddedRow(suite.userOne.ID, "Second", false, true))
+		suite.fixtures.InsertNotification(taskAddedRow(suite.userOne.ID, "Read", true, true))
+		suite.fixtures.InsertNotification(taskAddedRow(suite.userOne.ID, "Immediate", false, false))
+		suite.fixtures.InsertNotification(taskAddedRow(suite.userTwo.ID, "Other", false, true))
+
+		sent, err := suite.mailer.SendDigests(suite.ctx)
+		mails := suite.sender.mails
+
+		var pending int64
+		suite.db.Model(&models.Notification{}).Where("digest_pending").Count(&pending)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, sent)
+		suite.Require().Len(mails, 2)
+		suite.Require().Zero(pending)
+
+		for _, m := range mails {
+			if m.To != suite.userOne.Email {
+				continue
+			}
+			suite.Require().Contains(m.Text, `"First"`)
+			suite.Require().Contains(m.Text, `"Second"`)
+			suite.Require().NotContains(m.Text, `"Read"`)
+			suite.Require().NotContains(m.Text, `"Immediate"`)
+		}
+	})
+
+	t.Run("should not send the same notifications twice", func(t *testing.T) {
+		suite.fixtures.InsertNotification(taskAddedRow(suite.userOne.ID, "First", false, true))
+
+		first, err1 := suite.mailer.SendDigests(suite.ctx)
+		second, err2 := suite.mailer.SendDigests(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err1)
+		suite.Require().NoError(err2)
+		suite.Require().Equal(1, first)
+		suite.Require().Equal(0, second)
+	})
+
+	t.Run("should key the digest mail with the digest of its notifications", func(t *testing.T) {
+		n := taskAddedRow(suite.userOne.ID, "First", false, true)
+		suite.fixtures.InsertNotification(n)
+
+		_, err := suite.mailer.SendDigests(suite.ctx)
+		mails := suite.sender.mails
+
+		stored, _ := gorm.G[models.Notification](suite.db).Where("id = ?", n.ID).First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Len(mails, 1)
+		suite.Require().NotNil(stored.DigestID)
+		suite.Require().Equal("digest:"+*stored.DigestID, mails[0].IdempotencyKey)
+	})
+
+	t.Run("should keep notifications for the next digest when sending fails", func(t *testing.T) {
+		suite.fixtures.InsertNotification(taskAddedRow(suite.userOne.ID, "First", false, true))
+		suite.sender.err = errors.New("unavailable")
+
+		sent, err := suite.mailer.SendDigests(suite.ctx)
+
+		var pending int64
+		suite.db.Model(&models.Notification{}).Where("digest_pending AND digest_id IS NULL").Count(&pending)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(0, sent)
+		suite.Require().EqualValues(1, pending)
+	})
+}
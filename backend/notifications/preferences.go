@@ -0,0 +1,149 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ptracker/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	CHANNEL_IN_APP = "in_app"
+	CHANNEL_EMAIL  = "email"  // in app, and sent by email right away
+	CHANNEL_DIGEST = "digest" // in app, and in the daily digest email while unread
+	CHANNEL_NONE   = "none"
+)
+
+func IsValidChannel(channel string) bool {
+	return channel == CHANNEL_IN_APP || channel == CHANNEL_EMAIL ||
+		channel == CHANNEL_DIGEST || channel == CHANNEL_NONE
+}
+
+type PreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewPreferenceRepository(db *gorm.DB) *PreferenceRepository {
+	return &PreferenceRepository{
+		db: db,
+	}
+}
+
+func (r *PreferenceRepository) WithTx(tx *gorm.DB) *PreferenceRepository {
+	return NewPreferenceRepository(tx)
+}
+
+/*
+Channel user userID gets the notifications of type nType in project
+projectID through
+
+A muted project gives CHANNEL_NONE whatever the preference, a missing
+preference gives CHANNEL_IN_APP.
+*/
+func (r *PreferenceRepository) Channel(ctx context.Context,
+	userID, projectID, nType string) (string, error) {
+
+	muted, err := r.IsMuted(ctx, userID, projectID)
+	if err != nil {
+		return "", err
+	}
+	if muted {
+		return CHANNEL_NONE, nil
+	}
+
+	preferences, err := gorm.G[models.NotificationPreference](r.db).
+		Where("user_id = ? AND project_id = ? AND type = ?", userID, projectID, nType).
+		Find(ctx)
+	if err != nil {
+		return "", fmt.Errorf("gorm query: %w", err)
+	}
+	if len(preferences) == 0 {
+		return CHANNEL_IN_APP, nil
+	}
+
+	return preferences[0].Channel, nil
+}
+
+// Lists the preferences user userID set in project projectID
+func (r *PreferenceRepository) List(ctx context.Context,
+	userID, projectID string) ([]models.NotificationPreference, error) {
+
+	preferences, err := gorm.G[models.NotificationPreference](r.db).
+		Where("user_id = ? AND project_id = ?", userID, projectID).
+		Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gorm query: %w", err)
+	}
+
+	return preferences, nil
+}
+
+// Creates or replaces the preference of user userID for type nType in project projectID
+func (r *PreferenceRepository) Set(ctx context.Context,
+	userID, projectID, nType, channel string) error {
+
+	preference := models.NotificationPreference{
+		UserID:    userID,
+		ProjectID: projectID,
+		Type:      nType,
+		Channel:   channel,
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "project_id"}, {Name: "type"}},
+			DoUpdates: clause.AssignmentColumns([]string{"channel", "updated_at"}),
+		}).
+		Create(&preference).Error
+	if err != nil {
+		return fmt.Errorf("gorm db create: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PreferenceRepository) IsMuted(ctx context.Context,
+	userID, projectID string) (bool, error) {
+
+	count, err := gorm.G[models.ProjectMute](r.db).
+		Where("user_id = ? AND project_id = ?", userID, projectID).
+		Count(ctx, "*")
+	if err != nil {
+		return false, fmt.Errorf("gorm count: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// Mutes project projectID for user userID, muting it again does nothing
+func (r *PreferenceRepository) Mute(ctx context.Context,
+	userID, projectID string) error {
+
+	mute := models.ProjectMute{
+		UserID:    userID,
+		ProjectID: projectID,
+	}
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&mute).Error
+	if err != nil {
+		return fmt.Errorf("gorm db create: %w", err)
+	}
+
+	return nil
+}
+
+// Unmutes project projectID for user userID, the preferences apply again
+func (r *PreferenceRepository) Unmute(ctx context.Context,
+	userID, projectID string) error {
+
+	_, err := gorm.G[models.ProjectMute](r.db).
+		Where("user_id = ? AND project_id = ?", userID, projectID).
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("gorm delete: %w", err)
+	}
+
+	return nil
+}
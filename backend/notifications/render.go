@@ -0,0 +1,207 @@
+package notifications
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+)
+
+// Body of every notification type, as documented in models.Notification
+var bodies = map[string]func() any{
+	NT_TASK_ADDED:            func() any { return &TaskAdded{} },
+	NT_TASK_UPDATED:          func() any { return &TaskUpdated{} },
+	NT_ASSIGNEE_ADDED:        func() any { return &AssigneeUpdated{} },
+	NT_ASSIGNEE_REMOVED:      func() any { return &AssigneeUpdated{} },
+	NT_JOIN_REQUESTED:        func() any { return &JoinRequested{} },
+	NT_JOIN_RESPONDED:        func() any { return &JoinResponded{} },
+	NT_COMMENT_ADDED:         func() any { return &CommentAdded{} },
+	NT_MENTIONED:             func() any { return &Mentioned{} },
+	NT_TASK_DELETED:          func() any { return &TaskDeleted{} },
+	NT_PROJECT_DELETED:       func() any { return &ProjectDeleted{} },
+	NT_PROJECT_UPDATED:       func() any { return &ProjectUpdated{} },
+	NT_MEMBER_REMOVED:        func() any { return &MemberRemoved{} },
+	NT_MEMBER_LEFT:           func() any { return &MemberLeft{} },
+	NT_OWNERSHIP_TRANSFERRED: func() any { return &OwnershipTransferred{} },
+}
+
+var templateFuncs = map[string]any{
+	// display name of the user, or the username when not set
+	"name": func(a core.Avatar) string {
+		if a.DisplayName != nil && *a.DisplayName != "" {
+			return *a.DisplayName
+		}
+		return a.Username
+	},
+	"lower": strings.ToLower,
+}
+
+// Data of the mail layouts, Content holds the rendered notification
+type mailLayout struct {
+	AppURL  string
+	Content any
+	Items   []mailLayout
+}
+
+//go:embed templates
+var templates embed.FS
+
+var htmlTemplates = htmltemplate.Must(htmltemplate.New("mail").
+	Funcs(templateFuncs).
+	ParseFS(templates, "templates/mail.html"))
+
+// The text templates also hold the subjects, "<type>.subject"
+var textTemplates = texttemplate.Must(texttemplate.New("mail").
+	Funcs(templateFuncs).
+	ParseFS(templates, "templates/mail.txt"))
+
+/*
+Renders the notification mails from the templates of every type
+
+Both the single notification and the digest are wrapped in a layout
+linking to appURL.
+*/
+type Renderer struct {
+	html   *htmltemplate.Template
+	text   *texttemplate.Template
+	appURL string
+}
+
+func NewRenderer(appURL string) *Renderer {
+	return &Renderer{
+		html:   htmlTemplates,
+		text:   textTemplates,
+		appURL: appURL,
+	}
+}
+
+func executeHTML(t *htmltemplate.Template, name string, data any) (string, error) {
+	var b bytes.Buffer
+
+	err := t.ExecuteTemplate(&b, name, data)
+	if err != nil {
+		return "", fmt.Errorf("html template execute %s: %w", name, err)
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
+
+func executeText(t *texttemplate.Template, name string, data any) (string, error) {
+	var b bytes.Buffer
+
+	err := t.ExecuteTemplate(&b, name, data)
+	if err != nil {
+		return "", fmt.Errorf("text template execute %s: %w", name, err)
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
+
+/*
+Renders the subject and the contents of notification n, without the layout
+
+Returns ErrInvalidValue for an unknown type or a body that does not decode.
+*/
+func (r *Renderer) content(n models.Notification) (string, htmltemplate.HTML, string, error) {
+	newBody, ok := bodies[n.Type]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown notification type %s: %w", n.Type, core.ErrInvalidValue)
+	}
+
+	body := newBody()
+	err := json.Unmarshal(n.Body, body)
+	if err != nil {
+		return "", "", "", fmt.Errorf("json unmarshal: %w: %w", core.ErrInvalidValue, err)
+	}
+
+	subject, err := executeText(r.text, n.Type+".subject", body)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	html, err := executeHTML(r.html, n.Type, body)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	text, err := executeText(r.text, n.Type, body)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	// the html template escaped the body already
+	return subject, htmltemplate.HTML(html), text, nil
+}
+
+// Renders the mail of notification n, the recipient is left to the caller
+func (r *Renderer) Render(n models.Notification) (*Mail, error) {
+	subject, html, text, err := r.content(n)
+	if err != nil {
+		return nil, err
+	}
+
+	mail := Mail{
+		Subject: subject,
+	}
+
+	mail.HTML, err = executeHTML(r.html, "notification", mailLayout{AppURL: r.appURL, Content: html})
+	if err != nil {
+		return nil, err
+	}
+
+	mail.Text, err = executeText(r.text, "notification", mailLayout{AppURL: r.appURL, Content: text})
+	if err != nil {
+		return nil, err
+	}
+
+	return &mail, nil
+}
+
+/*
+Renders the daily digest mail of the notifications ns, oldest first
+
+The notifications that can not be rendered are left out.
+*/
+func (r *Renderer) RenderDigest(ns []models.Notification) (*Mail, error) {
+	htmlLayout := mailLayout{AppURL: r.appURL}
+	textLayout := mailLayout{AppURL: r.appURL}
+
+	for _, n := range ns {
+		_, html, text, err := r.content(n)
+		if err != nil {
+			continue
+		}
+
+		htmlLayout.Items = append(htmlLayout.Items, mailLayout{Content: html})
+		textLayout.Items = append(textLayout.Items, mailLayout{Content: text})
+	}
+	if len(textLayout.Items) == 0 {
+		return nil, fmt.Errorf("no notification to digest: %w", core.ErrInvalidValue)
+	}
+
+	var err error
+	mail := Mail{}
+
+	mail.Subject, err = executeText(r.text, "digest.subject", textLayout)
+	if err != nil {
+		return nil, err
+	}
+
+	mail.HTML, err = executeHTML(r.html, "digest", htmlLayout)
+	if err != nil {
+		return nil, err
+	}
+
+	mail.Text, err = executeText(r.text, "digest", textLayout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mail, nil
+}
@@ -0,0 +1,109 @@
+package notifications
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"github.com/ptracker/testhelpers/fixtures"
+)
+
+func TestRender(t *testing.T) {
+	r := NewRenderer("https://app.test")
+
+	t.Run("should render every notification type", func(t *testing.T) {
+		for _, nType := range NOTIFICATION_TYPES {
+			body := bodies[nType]()
+			n := fixtures.GetNotificationRow("user", nType, body)
+
+			mail, err := r.Render(n)
+			if err != nil {
+				t.Fatalf("render %s: %s", nType, err)
+			}
+			if mail.Subject == "" || mail.HTML == "" || mail.Text == "" {
+				t.Fatalf("render %s: empty mail %+v", nType, mail)
+			}
+		}
+	})
+
+	t.Run("should escape the html body only", func(t *testing.T) {
+		n := fixtures.GetNotificationRow("user", NT_MENTIONED, Mentioned{
+			Project:   ProjectBody{Name: "Tracker"},
+			Task:      TaskBody{Title: "Launch"},
+			Mentioner: core.Avatar{Username: "alice"},
+			Snippet:   "<script>@bob</script>",
+		})
+
+		mail, err := r.Render(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(mail.HTML, "<script>") {
+			t.Fatalf("unescaped html %s", mail.HTML)
+		}
+		if !strings.Contains(mail.Text, "> <script>@bob</script>") {
+			t.Fatalf("unexpected text %s", mail.Text)
+		}
+		if mail.Subject != `alice mentioned you in "Launch"` {
+			t.Fatalf("unexpected subject %s", mail.Subject)
+		}
+	})
+
+	t.Run("should return invalid value for unknown type", func(t *testing.T) {
+		_, err := r.Render(fixtures.GetNotificationRow("user", "unknown", nil))
+		if !errors.Is(err, core.ErrInvalidValue) {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+}
+
+func TestRenderDigest(t *testing.T) {
+	r := NewRenderer("https://app.test")
+
+	t.Run("should batch the notifications and leave out unknown types", func(t *testing.T) {
+		mail, err := r.RenderDigest([]models.Notification{
+			fixtures.GetNotificationRow("user", NT_TASK_ADDED, TaskAdded{
+				Project: ProjectBody{Name: "Tracker"},
+				Task:    TaskBody{Title: "Launch"},
+			}),
+			fixtures.GetNotificationRow("user", "unknown", nil),
+			fixtures.GetNotificationRow("user", NT_MEMBER_LEFT, MemberLeft{
+				Project: ProjectBody{Name: "Tracker"},
+				Member:  core.Avatar{Username: "bob"},
+			}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(mail.Subject, "2 unread notifications") {
+			t.Fatalf("unexpected subject %s", mail.Subject)
+		}
+		if !strings.Contains(mail.Text, `"Launch"`) || !strings.Contains(mail.HTML, "bob left") {
+			t.Fatalf("unexpected mail %+v", mail)
+		}
+	})
+
+	t.Run("should return invalid value when nothing can be rendered", func(t *testing.T) {
+		_, err := r.RenderDigest([]models.Notification{
+			fixtures.GetNotificationRow("user", "unknown", nil),
+		})
+		if !errors.Is(err, core.ErrInvalidValue) {
+			t.Fatalf("unexpected error %v", err)
+		}
+	})
+}
+
+func TestUntilNextDigest(t *testing.T) {
+	before := time.Date(2026, 1, 1, DIGEST_HOUR-1, 30, 0, 0, time.UTC)
+	if d := untilNextDigest(before); d != 30*time.Minute {
+		t.Fatalf("unexpected delay %s", d)
+	}
+
+	at := time.Date(2026, 1, 1, DIGEST_HOUR, 0, 0, 0, time.UTC)
+	if d := untilNextDigest(at); d != 24*time.Hour {
+		t.Fatalf("unexpected delay %s", d)
+	}
+}
@@ -3,46 +3,100 @@ package notifications
 import (
 	"context"
 	"fmt"
+	"log"
 
 	"github.com/google/uuid"
+	"github.com/ptracker/core"
 	"github.com/ptracker/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Pushes the created notifications to the users who are connected
+type Publisher interface {
+	Publish(ctx context.Context, n models.Notification) error
+}
+
 type NotificationRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	publisher Publisher
 }
 
-func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+// publisher can be nil when nothing is delivered live
+func NewNotificationRepository(db *gorm.DB,
+	publisher Publisher) *NotificationRepository {
 	return &NotificationRepository{
-		db: db,
+		db:        db,
+		publisher: publisher,
 	}
 }
 
+func (r *NotificationRepository) WithTx(tx *gorm.DB) *NotificationRepository {
+	return NewNotificationRepository(tx, r.publisher)
+}
+
+/*
+digestPending keeps the notification for the next daily digest
+
+The notification is not published, see Publish.
+*/
 func (r *NotificationRepository) Create(ctx context.Context,
 	userID, nType string,
 	body models.JSON,
-	read bool) (string, error) {
+	read bool,
+	digestPending bool) (*models.Notification, error) {
 
-	id := uuid.NewString()
 	notification := models.Notification{
-		ID:     id,
-		UserID: userID,
-		Type:   nType,
-		Body:   body,
-		Read:   read,
+		ID:            uuid.NewString(),
+		UserID:        userID,
+		Type:          nType,
+		Body:          body,
+		Read:          read,
+		DigestPending: digestPending,
 	}
 
 	err := gorm.G[models.Notification](r.db).Create(ctx, &notification)
 	if err != nil {
-		return "", fmt.Errorf("gorm create: %w", err)
+		return nil, fmt.Errorf("gorm create: %w", err)
+	}
+
+	return &notification, nil
+}
+
+/*
+Pushes the notification to its user, once the notification is committed
+
+The notification is stored, a stream that misses it gets it on resume, so
+a failure is only logged.
+*/
+func (r *NotificationRepository) Publish(ctx context.Context, n models.Notification) {
+	if r.publisher == nil {
+		return
+	}
+
+	err := r.publisher.Publish(ctx, n)
+	if err != nil {
+		log.Printf("[ERROR] notification publisher Publish: %s", err)
+	}
+}
+
+func (r *NotificationRepository) Get(ctx context.Context,
+	id string) (*models.Notification, error) {
+
+	notification, err := gorm.G[models.Notification](r.db).
+		Where("id = ?", id).
+		First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return nil, core.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("gorm query: %w", err)
 	}
 
-	return notification.ID, nil
+	return &notification, nil
 }
 
 func (r *NotificationRepository) Update(ctx context.Context,
-	id string,
+	userID, id string,
 	read bool) error {
 
 	var err error
@@ -50,9 +104,11 @@ func (r *NotificationRepository) Update(ctx context.Context,
 
 	notification, err =
 		gorm.G[models.Notification](r.db).
-			Where("id = ?", id).
+			Where("user_id = ? AND id = ?", userID, id).
 			First(ctx)
-	if err != nil {
+	if err == gorm.ErrRecordNotFound {
+		return core.ErrNotFound
+	} else if err != nil {
 		return fmt.Errorf("gorm query notification: %w", err)
 	}
 
@@ -67,15 +123,153 @@ func (r *NotificationRepository) Update(ctx context.Context,
 }
 
 func (r *NotificationRepository) List(ctx context.Context,
+	userID string,
+	page core.PageQuery) ([]models.Notification, string, error) {
+
+	cursor, err := core.DecodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := gorm.G[models.Notification](r.db).
+		Where("user_id = ?", userID)
+
+	if cursor != nil {
+		createdAt, err := cursor.Time()
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(created_at, id) < (?, ?)", createdAt, cursor.ID)
+	}
+
+	notifications, err := query.
+		Order("created_at DESC, id DESC").
+		Limit(page.Limit + 1).
+		Find(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("gorm query: %w", err)
+	}
+
+	notifications, nextCursor := core.Paginate(notifications, page.Limit,
+		func(n models.Notification) core.Cursor {
+			return core.NewTimeCursor(n.CreatedAt, n.ID)
+		})
+
+	return notifications, nextCursor, nil
+}
+
+/*
+Lists the notifications of user userID created after the notification
+afterID, oldest first
+
+Returns ErrNotFound if the notification afterID is not of the user.
+*/
+func (r *NotificationRepository) ListAfter(ctx context.Context,
+	userID, afterID string,
+	limit int) ([]models.Notification, error) {
+
+	after, err := gorm.G[models.Notification](r.db).
+		Where("user_id = ? AND id = ?", userID, afterID).
+		First(ctx)
+	if err == gorm.ErrRecordNotFound {
+		return nil, core.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("gorm query notification: %w", err)
+	}
+
+	notifications, err := gorm.G[models.Notification](r.db).
+		Where("user_id = ? AND (created_at, id) > (?, ?)",
+			userID, after.CreatedAt, after.ID).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gorm query: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// Lists the users with unread notifications waiting for the daily digest
+func (r *NotificationRepository) ListDigestUsers(ctx context.Context) ([]string, error) {
+	userIDs := []string{}
+	err := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("digest_pending AND NOT read").
+		Distinct().
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("gorm db pluck: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+/*
+Claims the unread notifications of user userID waiting for the daily
+digest, oldest first
+
+The notifications locked by another transaction are skipped, so it has to
+run in the transaction marking them with MarkDigest.
+*/
+func (r *NotificationRepository) ClaimDigest(ctx context.Context,
 	userID string) ([]models.Notification, error) {
 
-	notifications, err :=
-		gorm.G[models.Notification](r.db).
-			Where("user_id = ?", userID).
-			Find(ctx)
+	notifications, err := gorm.G[models.Notification](r.db,
+		clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("user_id = ? AND digest_pending AND NOT read", userID).
+		Order("created_at ASC, id ASC").
+		Find(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("gorm query: %w", err)
 	}
 
 	return notifications, nil
 }
+
+// Takes the notifications ids of user userID out of the next digest into
+// digest digestID, and the read ones out of any digest
+func (r *NotificationRepository) MarkDigest(ctx context.Context,
+	userID, digestID string,
+	ids []string) error {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("user_id = ? AND id IN ?", userID, ids).
+		Updates(map[string]any{
+			"digest_pending": false,
+			"digest_id":      digestID,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("gorm db updates: %w", err)
+	}
+
+	err = r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("user_id = ? AND digest_pending AND read", userID).
+		Update("digest_pending", false).Error
+	if err != nil {
+		return fmt.Errorf("gorm db update: %w", err)
+	}
+
+	return nil
+}
+
+// Puts the unread notifications of digest digestID back for the next one,
+// the digest was not sent
+func (r *NotificationRepository) RestoreDigest(ctx context.Context,
+	digestID string) error {
+
+	err := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("digest_id = ? AND NOT read", digestID).
+		Updates(map[string]any{
+			"digest_pending": true,
+			"digest_id":      nil,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("gorm db updates: %w", err)
+	}
+
+	return nil
+}
@@ -3,14 +3,23 @@ package notifications
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ptracker/core"
 	"github.com/ptracker/core/members"
+	"github.com/ptracker/core/mentions"
 	"github.com/ptracker/core/projects"
 	"github.com/ptracker/core/tasks"
 	"github.com/ptracker/core/users"
+	"github.com/ptracker/models"
+	"github.com/ptracker/outbox"
+	"gorm.io/gorm"
 )
 
 const (
@@ -21,8 +30,34 @@ const (
 	NT_JOIN_REQUESTED   = "join_requested"
 	NT_JOIN_RESPONDED   = "join_responded"
 	NT_COMMENT_ADDED    = "comment_added"
+	NT_TASK_DELETED     = "task_deleted"
+	NT_PROJECT_DELETED  = "project_deleted"
+	NT_PROJECT_UPDATED  = "project_updated"
+	NT_MEMBER_REMOVED   = "member_removed"
+	NT_MEMBER_LEFT      = "member_left"
+	NT_MENTIONED        = "mentioned"
+
+	NT_OWNERSHIP_TRANSFERRED = "ownership_transferred"
 )
 
+// Every notification type, each one has its own preference
+var NOTIFICATION_TYPES = []string{
+	NT_TASK_ADDED,
+	NT_TASK_UPDATED,
+	NT_ASSIGNEE_ADDED,
+	NT_ASSIGNEE_REMOVED,
+	NT_JOIN_REQUESTED,
+	NT_JOIN_RESPONDED,
+	NT_COMMENT_ADDED,
+	NT_TASK_DELETED,
+	NT_PROJECT_DELETED,
+	NT_PROJECT_UPDATED,
+	NT_MEMBER_REMOVED,
+	NT_MEMBER_LEFT,
+	NT_MENTIONED,
+	NT_OWNERSHIP_TRANSFERRED,
+}
+
 type ProjectBody struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
@@ -38,6 +73,11 @@ type TaskUpdateBody struct {
 	Field string `json:"field"`
 }
 
+type ProjectUpdateBody struct {
+	To    string `json:"to"`
+	Field string `json:"field"`
+}
+
 type TaskAdded struct {
 	Project ProjectBody `json:"project"`
 	Task    TaskBody    `json:"task"`
@@ -73,6 +113,46 @@ type CommentAdded struct {
 	Commenter core.Avatar `json:"commenter"`
 }
 
+type Mentioned struct {
+	Project   ProjectBody `json:"project"`
+	Task      TaskBody    `json:"task"`
+	Mentioner core.Avatar `json:"mentioner"`
+	Snippet   string      `json:"snippet"`
+}
+
+type TaskDeleted struct {
+	Project ProjectBody `json:"project"`
+	Task    TaskBody    `json:"task"`
+	Deleter core.Avatar `json:"deleter"`
+}
+
+type ProjectDeleted struct {
+	Project ProjectBody `json:"project"`
+	Deleter core.Avatar `json:"deleter"`
+}
+
+type ProjectUpdated struct {
+	Project ProjectBody         `json:"project"`
+	Updates []ProjectUpdateBody `json:"updates"`
+	Updater core.Avatar         `json:"updater"`
+}
+
+type MemberRemoved struct {
+	Project ProjectBody `json:"project"`
+	Remover core.Avatar `json:"remover"`
+}
+
+type MemberLeft struct {
+	Project ProjectBody `json:"project"`
+	Member  core.Avatar `json:"member"`
+}
+
+type OwnershipTransferred struct {
+	Project       ProjectBody `json:"project"`
+	PreviousOwner core.Avatar `json:"previous_owner"`
+	NewOwner      core.Avatar `json:"new_owner"`
+}
+
 type Notification struct {
 	ID        string    `json:"id"`
 	UserID    string    `json:"user_id"`
@@ -82,39 +162,159 @@ type Notification struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+func toNotification(r models.Notification) Notification {
+	var body any
+	json.Unmarshal(r.Body, &body)
+
+	return Notification{
+		ID:        r.ID,
+		UserID:    r.UserID,
+		Type:      r.Type,
+		Body:      body,
+		Read:      r.Read,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
 type NotificationService struct {
+	txManager        *core.TxManager
 	projectRepo      *projects.ProjectRepository
 	taskRepo         *tasks.TaskRepository
 	membershipRepo   *members.MemberRepository
 	userRepo         *users.UserRepository
 	notificationRepo *NotificationRepository
+	mentionRepo      *mentions.MentionRepository
+	preferenceRepo   *PreferenceRepository
+	outboxRepo       *outbox.OutboxRepository
 }
 
 func NewNotificationService(
+	txManager *core.TxManager,
 	projectRepo *projects.ProjectRepository,
 	taskRepo *tasks.TaskRepository,
 	membershipRepo *members.MemberRepository,
 	userRepo *users.UserRepository,
 	notificationRepo *NotificationRepository,
+	mentionRepo *mentions.MentionRepository,
+	preferenceRepo *PreferenceRepository,
+	outboxRepo *outbox.OutboxRepository,
 ) *NotificationService {
 	return &NotificationService{
+		txManager:        txManager,
 		projectRepo:      projectRepo,
 		taskRepo:         taskRepo,
 		membershipRepo:   membershipRepo,
 		userRepo:         userRepo,
 		notificationRepo: notificationRepo,
+		mentionRepo:      mentionRepo,
+		preferenceRepo:   preferenceRepo,
+		outboxRepo:       outboxRepo,
+	}
+}
+
+/*
+Stores the notification of type nType for every user of userIDs, except the
+ones who muted project projectID or turned the type off in it
+
+The notifications of an outbox event are written in the transaction
+completing the event, so a retried event does not notify anyone twice,
+and are published once committed.
+*/
+func (s *NotificationService) notify(ctx context.Context,
+	projectID string,
+	userIDs []string,
+	nType string,
+	body models.JSON) error {
+
+	var created []models.Notification
+
+	err := s.txManager.WithTx(func(tx *gorm.DB) error {
+		var err error
+		created, err = s.notifyTx(ctx, tx, projectID, userIDs, nType, body)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("txManager WithTx: %w", err)
+	}
+
+	s.publish(ctx, created)
+
+	return nil
+}
+
+/*
+Stores the notifications of notify in transaction tx, returns the ones to
+publish after commit
+
+A notification on the email channel is sent through the outbox, on the
+digest channel it waits for the next daily digest.
+*/
+func (s *NotificationService) notifyTx(ctx context.Context,
+	tx *gorm.DB,
+	projectID string,
+	userIDs []string,
+	nType string,
+	body models.JSON) ([]models.Notification, error) {
+
+	preferenceRepo := s.preferenceRepo.WithTx(tx)
+	notificationRepo := s.notificationRepo.WithTx(tx)
+	outboxRepo := s.outboxRepo.WithTx(tx)
+
+	if id, ok := eventID(ctx); ok {
+		err := outboxRepo.Complete(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("outbox repository Complete: %w", err)
+		}
+	}
+
+	created := []models.Notification{}
+	for _, userID := range userIDs {
+		channel, err := preferenceRepo.Channel(ctx, userID, projectID, nType)
+		if err != nil {
+			return nil, fmt.Errorf("preference repository Channel: %w", err)
+		}
+		if channel == CHANNEL_NONE {
+			continue
+		}
+
+		notification, err := notificationRepo.Create(ctx, userID, nType, body, false,
+			channel == CHANNEL_DIGEST)
+		if err != nil {
+			return nil, fmt.Errorf("notification repository Create: %w", err)
+		}
+
+		if channel == CHANNEL_EMAIL {
+			err = outboxRepo.Create(ctx, outbox.EV_EMAIL_REQUESTED, outbox.EmailRequested{
+				NotificationID: notification.ID,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("outbox repository Create: %w", err)
+			}
+		}
+
+		created = append(created, *notification)
+	}
+
+	return created, nil
+}
+
+// Pushes the committed notifications to the users who are connected
+func (s *NotificationService) publish(ctx context.Context, ns []models.Notification) {
+	for _, n := range ns {
+		s.notificationRepo.Publish(ctx, n)
 	}
 }
 
+// Notifies the members of the project, except the creator of the task
 func (s *NotificationService) TaskAdded(ctx context.Context,
-	projectID, taskID string) error {
+	projectID, taskID, creatorID string) error {
 
 	project, err := s.projectRepo.Get(ctx, projectID)
 	if err != nil {
 		return fmt.Errorf("project repository Get: %w", err)
 	}
 
-	task, err := s.taskRepo.Get(ctx, taskID)
+	task, err := s.taskRepo.Get(ctx, projectID, taskID)
 	if err != nil {
 		return fmt.Errorf("task repository Get: %w", err)
 	}
@@ -134,23 +334,35 @@ func (s *NotificationService) TaskAdded(ctx context.Context,
 			Title: task.Title,
 		},
 	})
+	userIDs := []string{}
 	for _, m := range members {
-		if m.Role.String == core.ROLE_OWNER {
-			continue
+		if m.UserID != creatorID {
+			userIDs = append(userIDs, m.UserID)
 		}
+	}
 
-		_, err := s.notificationRepo.Create(ctx, m.UserID, NT_TASK_ADDED, body, false)
-		if err != nil {
-			return fmt.Errorf("notification repository Create: %w", err)
-		}
+	err = s.notify(ctx, projectID, userIDs, NT_TASK_ADDED, body)
+	if err != nil {
+		return fmt.Errorf("notification service notify: %w", err)
 	}
 
 	return nil
 }
 
+// Formats the date of an update, a cleared date is zero and shows as empty
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
 func (s *NotificationService) TaskUpdated(ctx context.Context,
 	projectID, taskID string,
-	title, description, status *string,
+	title, description, status, priority *string,
+	estimate *int,
+	startDate, dueDate *time.Time,
 	updaterID string) error {
 
 	project, err := s.projectRepo.Get(ctx, projectID)
@@ -158,7 +370,7 @@ func (s *NotificationService) TaskUpdated(ctx context.Context,
 		return fmt.Errorf("project repository Get: %w", err)
 	}
 
-	task, err := s.taskRepo.Get(ctx, taskID)
+	task, err := s.taskRepo.Get(ctx, projectID, taskID)
 	if err != nil {
 		return fmt.Errorf("task repository Get: %w", err)
 	}
@@ -187,6 +399,30 @@ func (s *NotificationService) TaskUpdated(ctx context.Context,
 			Field: "Status",
 		})
 	}
+	if priority != nil {
+		updates = append(updates, TaskUpdateBody{
+			To:    *priority,
+			Field: "Priority",
+		})
+	}
+	if estimate != nil {
+		updates = append(updates, TaskUpdateBody{
+			To:    strconv.Itoa(*estimate),
+			Field: "Estimate",
+		})
+	}
+	if startDate != nil {
+		updates = append(updates, TaskUpdateBody{
+			To:    formatDate(*startDate),
+			Field: "StartDate",
+		})
+	}
+	if dueDate != nil {
+		updates = append(updates, TaskUpdateBody{
+			To:    formatDate(*dueDate),
+			Field: "DueDate",
+		})
+	}
 
 	body, _ := json.Marshal(TaskUpdated{
 		Project: ProjectBody{
@@ -207,34 +443,26 @@ func (s *NotificationService) TaskUpdated(ctx context.Context,
 		},
 	})
 
+	userIDs := []string{}
 	if project.OwnerID != updaterID {
-		_, err := s.notificationRepo.Create(
-			ctx,
-			project.OwnerID,
-			NT_TASK_UPDATED,
-			body,
-			false,
-		)
-		if err != nil {
-			return fmt.Errorf("notification repository Create: %w", err)
-		}
+		userIDs = append(userIDs, project.OwnerID)
 	}
-
 	for _, assignee := range task.Assignees {
 		if assignee.AssigneeID == updaterID {
 			continue
 		}
+		userIDs = append(userIDs, assignee.AssigneeID)
+	}
 
-		_, err := s.notificationRepo.Create(
-			ctx,
-			assignee.AssigneeID,
-			NT_TASK_UPDATED,
-			body,
-			false,
-		)
-		if err != nil {
-			return fmt.Errorf("notification repository Create: %w", err)
-		}
+	err = s.notify(
+		ctx,
+		projectID,
+		userIDs,
+		NT_TASK_UPDATED,
+		body,
+	)
+	if err != nil {
+		return fmt.Errorf("notification service notify: %w", err)
 	}
 
 	return nil
@@ -250,7 +478,7 @@ func (s *NotificationService) AssigneeUpdated(ctx context.Context,
 		return fmt.Errorf("project repository Get: %w", err)
 	}
 
-	task, err := s.taskRepo.Get(ctx, taskID)
+	task, err := s.taskRepo.Get(ctx, projectID, taskID)
 	if err != nil {
 		return fmt.Errorf("task repository Get: %w", err)
 	}
@@ -285,22 +513,26 @@ func (s *NotificationService) AssigneeUpdated(ctx context.Context,
 		notificationType = NT_ASSIGNEE_REMOVED
 	}
 
+	userIDs := []string{}
 	for _, assignee := range task.Assignees {
-		_, err := s.notificationRepo.Create(
-			ctx,
-			assignee.AssigneeID,
-			notificationType,
-			body,
-			false,
-		)
-		if err != nil {
-			return fmt.Errorf("notification repository Create: %w", err)
-		}
+		userIDs = append(userIDs, assignee.AssigneeID)
+	}
+
+	err = s.notify(
+		ctx,
+		projectID,
+		userIDs,
+		notificationType,
+		body,
+	)
+	if err != nil {
+		return fmt.Errorf("notification service notify: %w", err)
 	}
 
 	return nil
 }
 
+// Notifies the members who can respond to the join request
 func (s *NotificationService) JoinRequested(ctx context.Context,
 	projectID string,
 	requestorID string) error {
@@ -310,6 +542,11 @@ func (s *NotificationService) JoinRequested(ctx context.Context,
 		return fmt.Errorf("project repository Get: %w", err)
 	}
 
+	members, err := s.membershipRepo.List(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("membership repository List: %w", err)
+	}
+
 	requestor, err := s.userRepo.Get(ctx, requestorID)
 	if err != nil {
 		return fmt.Errorf("user repository Get: %w", err)
@@ -329,15 +566,22 @@ func (s *NotificationService) JoinRequested(ctx context.Context,
 		},
 	})
 
-	_, err = s.notificationRepo.Create(
+	userIDs := []string{}
+	for _, m := range members {
+		if core.HasPermission(m.Role.String, core.PERMISSION_MANAGE_JOIN_REQUESTS) {
+			userIDs = append(userIDs, m.UserID)
+		}
+	}
+
+	err = s.notify(
 		ctx,
-		project.OwnerID,
+		projectID,
+		userIDs,
 		NT_JOIN_REQUESTED,
 		body,
-		false,
 	)
 	if err != nil {
-		return fmt.Errorf("notification repository Create: %w", err)
+		return fmt.Errorf("notification service notify: %w", err)
 	}
 
 	return nil
@@ -345,7 +589,7 @@ func (s *NotificationService) JoinRequested(ctx context.Context,
 
 func (s *NotificationService) JoinResponded(ctx context.Context,
 	projectID string,
-	requestorID string,
+	requestorID, responderID string,
 	status string) error {
 
 	project, err := s.projectRepo.Get(ctx, projectID)
@@ -353,7 +597,7 @@ func (s *NotificationService) JoinResponded(ctx context.Context,
 		return fmt.Errorf("project repository Get: %w", err)
 	}
 
-	responder, err := s.userRepo.Get(ctx, project.OwnerID)
+	responder, err := s.userRepo.Get(ctx, responderID)
 	if err != nil {
 		return fmt.Errorf("user repository Get: %w", err)
 	}
@@ -373,15 +617,15 @@ func (s *NotificationService) JoinResponded(ctx context.Context,
 		Status: status,
 	})
 
-	_, err = s.notificationRepo.Create(
+	err = s.notify(
 		ctx,
-		requestorID,
+		projectID,
+		[]string{requestorID},
 		NT_JOIN_RESPONDED,
 		body,
-		false,
 	)
 	if err != nil {
-		return fmt.Errorf("notification repository Create: %w", err)
+		return fmt.Errorf("notification service notify: %w", err)
 	}
 
 	return nil
@@ -396,7 +640,7 @@ func (s *NotificationService) CommentAdded(ctx context.Context,
 		return fmt.Errorf("project repository Get: %w", err)
 	}
 
-	task, err := s.taskRepo.Get(ctx, taskID)
+	task, err := s.taskRepo.Get(ctx, projectID, taskID)
 	if err != nil {
 		return fmt.Errorf("task repository Get: %w", err)
 	}
@@ -424,42 +668,290 @@ func (s *NotificationService) CommentAdded(ctx context.Context,
 		},
 	})
 
+	userIDs := []string{}
 	for _, assignee := range task.Assignees {
-		_, err = s.notificationRepo.Create(
-			ctx,
-			assignee.AssigneeID,
-			NT_COMMENT_ADDED,
-			body,
-			false,
-		)
+		userIDs = append(userIDs, assignee.AssigneeID)
+	}
+
+	err = s.notify(
+		ctx,
+		projectID,
+		userIDs,
+		NT_COMMENT_ADDED,
+		body,
+	)
+	if err != nil {
+		return fmt.Errorf("notification service notify: %w", err)
+	}
+
+	return nil
+}
+
+// @username, not preceded by a word character so emails are not mentions
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w])@(\w+(?:[.-]\w+)*)`)
+
+// Returns the usernames mentioned in the content, each one once
+func mentionedUsernames(content string) []string {
+	usernames := []string{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		if !slices.Contains(usernames, match[1]) {
+			usernames = append(usernames, match[1])
+		}
+	}
+
+	return usernames
+}
+
+const SNIPPET_LENGTH = 140
+
+// Returns the content cut to SNIPPET_LENGTH characters
+func snippet(content string) string {
+	runes := []rune(strings.TrimSpace(content))
+	if len(runes) <= SNIPPET_LENGTH {
+		return string(runes)
+	}
+
+	return strings.TrimSpace(string(runes[:SNIPPET_LENGTH])) + "…"
+}
+
+/*
+Notifies the project members mentioned with @username in the content of
+the comment or the task description sourceID
+
+The mentions are stored per source, so a member mentioned again when the
+source is edited is not notified twice. Usernames that are not members of
+the project and the mentioner themselves are ignored.
+*/
+func (s *NotificationService) Mentioned(ctx context.Context,
+	projectID, taskID, sourceID string,
+	mentionerID string,
+	content string) error {
+
+	usernames := mentionedUsernames(content)
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	members, err := s.membershipRepo.List(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("membership repository List: %w", err)
+	}
+
+	mentioned := []string{}
+	for _, m := range members {
+		if m.UserID != mentionerID && slices.Contains(usernames, m.Username) {
+			mentioned = append(mentioned, m.UserID)
+		}
+	}
+	if len(mentioned) == 0 {
+		return nil
+	}
+
+	project, err := s.projectRepo.Get(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("project repository Get: %w", err)
+	}
+
+	task, err := s.taskRepo.Get(ctx, projectID, taskID)
+	if err != nil {
+		return fmt.Errorf("task repository Get: %w", err)
+	}
+
+	mentioner, err := s.userRepo.Get(ctx, mentionerID)
+	if err != nil {
+		return fmt.Errorf("user repository Get: %w", err)
+	}
+
+	body, _ := json.Marshal(Mentioned{
+		Project: ProjectBody{
+			ID:   projectID,
+			Name: project.Name,
+		},
+		Task: TaskBody{
+			ID:    taskID,
+			Title: task.Title,
+		},
+		Mentioner: core.Avatar{
+			UserID:      mentioner.ID,
+			Username:    mentioner.Username,
+			DisplayName: mentioner.DisplayName,
+			Email:       mentioner.Email,
+			AvatarURL:   mentioner.AvatarURL,
+		},
+		Snippet: snippet(content),
+	})
+
+	var created []models.Notification
+
+	// a mention is stored with its notification, a retried event that
+	// failed to notify finds no mention and notifies again
+	err = s.txManager.WithTx(func(tx *gorm.DB) error {
+		mentionRepo := s.mentionRepo.WithTx(tx)
+
+		userIDs := []string{}
+		for _, userID := range mentioned {
+			ok, err := mentionRepo.Create(ctx, projectID, taskID, sourceID, userID)
+			if err != nil {
+				return fmt.Errorf("mention repository Create: %w", err)
+			}
+			if ok {
+				userIDs = append(userIDs, userID)
+			}
+		}
+
+		created, err = s.notifyTx(ctx, tx, projectID, userIDs, NT_MENTIONED, body)
 		if err != nil {
-			return fmt.Errorf("notification repository Create: %w", err)
+			return fmt.Errorf("notification service notifyTx: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("txManager WithTx: %w", err)
+	}
+
+	s.publish(ctx, created)
+
+	return nil
+}
+
+func (s *NotificationService) TaskDeleted(ctx context.Context,
+	task *tasks.DeletedTask,
+	deleterID string) error {
+
+	project, err := s.projectRepo.Get(ctx, task.ProjectID)
+	if err != nil {
+		return fmt.Errorf("project repository Get: %w", err)
+	}
+
+	deleter, err := s.userRepo.Get(ctx, deleterID)
+	if err != nil {
+		return fmt.Errorf("user repository Get: %w", err)
+	}
+
+	body, _ := json.Marshal(TaskDeleted{
+		Project: ProjectBody{
+			ID:   task.ProjectID,
+			Name: project.Name,
+		},
+		Task: TaskBody{
+			ID:    task.ID,
+			Title: task.Title,
+		},
+		Deleter: core.Avatar{
+			UserID:      deleter.ID,
+			Username:    deleter.Username,
+			DisplayName: deleter.DisplayName,
+			Email:       deleter.Email,
+			AvatarURL:   deleter.AvatarURL,
+		},
+	})
+
+	userIDs := []string{}
+	for _, assigneeID := range task.AssigneeIDs {
+		if assigneeID != deleterID {
+			userIDs = append(userIDs, assigneeID)
+		}
+	}
+
+	err = s.notify(
+		ctx,
+		task.ProjectID,
+		userIDs,
+		NT_TASK_DELETED,
+		body,
+	)
+	if err != nil {
+		return fmt.Errorf("notification service notify: %w", err)
+	}
+
+	return nil
+}
+
+func (s *NotificationService) ProjectDeleted(ctx context.Context,
+	project *projects.DeletedProject,
+	deleterID string) error {
+
+	deleter, err := s.userRepo.Get(ctx, deleterID)
+	if err != nil {
+		return fmt.Errorf("user repository Get: %w", err)
+	}
+
+	body, _ := json.Marshal(ProjectDeleted{
+		Project: ProjectBody{
+			ID:   project.ID,
+			Name: project.Name,
+		},
+		Deleter: core.Avatar{
+			UserID:      deleter.ID,
+			Username:    deleter.Username,
+			DisplayName: deleter.DisplayName,
+			Email:       deleter.Email,
+			AvatarURL:   deleter.AvatarURL,
+		},
+	})
+
+	userIDs := []string{}
+	for _, memberID := range project.MemberIDs {
+		if memberID != deleterID {
+			userIDs = append(userIDs, memberID)
 		}
 	}
 
+	err = s.notify(
+		ctx,
+		project.ID,
+		userIDs,
+		NT_PROJECT_DELETED,
+		body,
+	)
+	if err != nil {
+		return fmt.Errorf("notification service notify: %w", err)
+	}
+
 	return nil
 }
 
 func (s *NotificationService) List(ctx context.Context,
-	userID string) ([]Notification, error) {
+	userID string,
+	page core.PageQuery) ([]Notification, string, error) {
+
+	if page.Limit <= 0 || page.Limit > core.MAX_LIST_LIMIT {
+		return nil, "", core.ErrInvalidValue
+	}
 
-	rows, err := s.notificationRepo.List(ctx, userID)
+	rows, nextCursor, err := s.notificationRepo.List(ctx, userID, page)
 	if err != nil {
-		return nil, fmt.Errorf("notification repository List: %w", err)
+		return nil, "", fmt.Errorf("notification repository List: %w", err)
 	}
 
 	notifications := []Notification{}
-	var body any
 	for _, r := range rows {
-		json.Unmarshal(r.Body, &body)
-		notifications = append(notifications, Notification{
-			ID:        r.ID,
-			UserID:    r.UserID,
-			Type:      r.Type,
-			Body:      body,
-			Read:      r.Read,
-			CreatedAt: r.CreatedAt,
-		})
+		notifications = append(notifications, toNotification(r))
+	}
+
+	return notifications, nextCursor, nil
+}
+
+/*
+Lists the notifications of user userID created after the notification
+lastID, the ones a reconnecting stream has missed
+
+An unknown lastID is ignored, the stream then only receives new ones.
+*/
+func (s *NotificationService) Missed(ctx context.Context,
+	userID, lastID string) ([]Notification, error) {
+
+	rows, err := s.notificationRepo.ListAfter(ctx, userID, lastID, core.MAX_LIST_LIMIT)
+	if errors.Is(err, core.ErrNotFound) {
+		return []Notification{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("notification repository ListAfter: %w", err)
+	}
+
+	notifications := []Notification{}
+	for _, r := range rows {
+		notifications = append(notifications, toNotification(r))
 	}
 
 	return notifications, nil
@@ -468,10 +960,320 @@ func (s *NotificationService) List(ctx context.Context,
 func (s *NotificationService) MarkAsRead(ctx context.Context,
 	userID, notificationID string) error {
 
-	err := s.notificationRepo.Update(ctx, notificationID, true)
+	err := s.notificationRepo.Update(ctx, userID, notificationID, true)
 	if err != nil {
 		return fmt.Errorf("notification repository Update: %w", err)
 	}
 
 	return nil
 }
+
+func (s *NotificationService) ProjectUpdated(ctx context.Context,
+	projectID string,
+	name, description, skills *string,
+	updaterID string) error {
+
+	project, err := s.projectRepo.Get(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("project repository Get: %w", err)
+	}
+
+	updater, err := s.userRepo.Get(ctx, updaterID)
+	if err != nil {
+		return fmt.Errorf("user repository Get: %w", err)
+	}
+
+	members, err := s.membershipRepo.List(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("membership repository List: %w", err)
+	}
+
+	updates := []ProjectUpdateBody{}
+	if name != nil {
+		updates = append(updates, ProjectUpdateBody{
+			To:    *name,
+			Field: "Name",
+		})
+	}
+	if description != nil {
+		updates = append(updates, ProjectUpdateBody{
+			To:    *description,
+			Field: "Description",
+		})
+	}
+	if skills != nil {
+		updates = append(updates, ProjectUpdateBody{
+			To:    *skills,
+			Field: "Skills",
+		})
+	}
+
+	body, _ := json.Marshal(ProjectUpdated{
+		Project: ProjectBody{
+			ID:   projectID,
+			Name: project.Name,
+		},
+		Updates: updates,
+		Updater: core.Avatar{
+			UserID:      updater.ID,
+			Username:    updater.Username,
+			DisplayName: updater.DisplayName,
+			Email:       updater.Email,
+			AvatarURL:   updater.AvatarURL,
+		},
+	})
+
+	userIDs := []string{}
+	for _, m := range members {
+		if m.UserID != updaterID {
+			userIDs = append(userIDs, m.UserID)
+		}
+	}
+
+	err = s.notify(
+		ctx,
+		projectID,
+		userIDs,
+		NT_PROJECT_UPDATED,
+		body,
+	)
+	if err != nil {
+		return fmt.Errorf("notification service notify: %w", err)
+	}
+
+	return nil
+}
+
+func (s *NotificationService) MemberRemoved(ctx context.Context,
+	projectID, memberID, removerID string) error {
+
+	project, err := s.projectRepo.Get(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("project repository Get: %w", err)
+	}
+
+	remover, err := s.userRepo.Get(ctx, removerID)
+	if err != nil {
+		return fmt.Errorf("user repository Get: %w", err)
+	}
+
+	body, _ := json.Marshal(MemberRemoved{
+		Project: ProjectBody{
+			ID:   projectID,
+			Name: project.Name,
+		},
+		Remover: core.Avatar{
+			UserID:      remover.ID,
+			Username:    remover.Username,
+			DisplayName: remover.DisplayName,
+			Email:       remover.Email,
+			AvatarURL:   remover.AvatarURL,
+		},
+	})
+
+	err = s.notify(
+		ctx,
+		projectID,
+		[]string{memberID},
+		NT_MEMBER_REMOVED,
+		body,
+	)
+	if err != nil {
+		return fmt.Errorf("notification service notify: %w", err)
+	}
+
+	return nil
+}
+
+func (s *NotificationService) MemberLeft(ctx context.Context,
+	projectID, memberID string) error {
+
+	project, err := s.projectRepo.Get(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("project repository Get: %w", err)
+	}
+
+	member, err := s.userRepo.Get(ctx, memberID)
+	if err != nil {
+		return fmt.Errorf("user repository Get: %w", err)
+	}
+
+	body, _ := json.Marshal(MemberLeft{
+		Project: ProjectBody{
+			ID:   projectID,
+			Name: project.Name,
+		},
+		Member: core.Avatar{
+			UserID:      member.ID,
+			Username:    member.Username,
+			DisplayName: member.DisplayName,
+			Email:       member.Email,
+			AvatarURL:   member.AvatarURL,
+		},
+	})
+
+	err = s.notify(
+		ctx,
+		projectID,
+		[]string{project.OwnerID},
+		NT_MEMBER_LEFT,
+		body,
+	)
+	if err != nil {
+		return fmt.Errorf("notification service notify: %w", err)
+	}
+
+	return nil
+}
+
+func (s *NotificationService) OwnershipTransferred(ctx context.Context,
+	projectID, previousOwnerID, newOwnerID string) error {
+
+	project, err := s.projectRepo.Get(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("project repository Get: %w", err)
+	}
+
+	previousOwner, err := s.userRepo.Get(ctx, previousOwnerID)
+	if err != nil {
+		return fmt.Errorf("user repository Get: %w", err)
+	}
+
+	newOwner, err := s.userRepo.Get(ctx, newOwnerID)
+	if err != nil {
+		return fmt.Errorf("user repository Get: %w", err)
+	}
+
+	body, _ := json.Marshal(OwnershipTransferred{
+		Project: ProjectBody{
+			ID:   projectID,
+			Name: project.Name,
+		},
+		PreviousOwner: core.Avatar{
+			UserID:      previousOwner.ID,
+			Username:    previousOwner.Username,
+			DisplayName: previousOwner.DisplayName,
+			Email:       previousOwner.Email,
+			AvatarURL:   previousOwner.AvatarURL,
+		},
+		NewOwner: core.Avatar{
+			UserID:      newOwner.ID,
+			Username:    newOwner.Username,
+			DisplayName: newOwner.DisplayName,
+			Email:       newOwner.Email,
+			AvatarURL:   newOwner.AvatarURL,
+		},
+	})
+
+	err = s.notify(
+		ctx,
+		projectID,
+		[]string{previousOwnerID, newOwnerID},
+		NT_OWNERSHIP_TRANSFERRED,
+		body,
+	)
+	if err != nil {
+		return fmt.Errorf("notification service notify: %w", err)
+	}
+
+	return nil
+}
+
+// Notification preferences of a user in a project
+type Preferences struct {
+	ProjectID string `json:"project_id"`
+	Muted     bool   `json:"muted"`
+
+	// channel of every notification type, the ones not set are in_app
+	Channels map[string]string `json:"channels"`
+}
+
+func (s *NotificationService) Preferences(ctx context.Context,
+	projectID, userID string) (*Preferences, error) {
+
+	err := core.NeedsToBeAMember(ctx, s.membershipRepo, projectID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("needs to be a member: %w", err)
+	}
+
+	muted, err := s.preferenceRepo.IsMuted(ctx, userID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("preference repository IsMuted: %w", err)
+	}
+
+	rows, err := s.preferenceRepo.List(ctx, userID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("preference repository List: %w", err)
+	}
+
+	channels := map[string]string{}
+	for _, nType := range NOTIFICATION_TYPES {
+		channels[nType] = CHANNEL_IN_APP
+	}
+	for _, r := range rows {
+		channels[r.Type] = r.Channel
+	}
+
+	return &Preferences{
+		ProjectID: projectID,
+		Muted:     muted,
+		Channels:  channels,
+	}, nil
+}
+
+/*
+Sets the channels of the notification types in channels, the other types
+keep their channel
+
+Returns ErrInvalidValue for an unknown type or channel.
+*/
+func (s *NotificationService) UpdatePreferences(ctx context.Context,
+	projectID, userID string,
+	channels map[string]string) error {
+
+	for nType, channel := range channels {
+		if !slices.Contains(NOTIFICATION_TYPES, nType) || !IsValidChannel(channel) {
+			return core.ErrInvalidValue
+		}
+	}
+
+	err := core.NeedsToBeAMember(ctx, s.membershipRepo, projectID, userID)
+	if err != nil {
+		return fmt.Errorf("needs to be a member: %w", err)
+	}
+
+	for nType, channel := range channels {
+		err = s.preferenceRepo.Set(ctx, userID, projectID, nType, channel)
+		if err != nil {
+			return fmt.Errorf("preference repository Set: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Mutes or unmutes every notification of project projectID for user userID
+func (s *NotificationService) SetMuted(ctx context.Context,
+	projectID, userID string,
+	muted bool) error {
+
+	err := core.NeedsToBeAMember(ctx, s.membershipRepo, projectID, userID)
+	if err != nil {
+		return fmt.Errorf("needs to be a member: %w", err)
+	}
+
+	if muted {
+		err = s.preferenceRepo.Mute(ctx, userID, projectID)
+		if err != nil {
+			return fmt.Errorf("preference repository Mute: %w", err)
+		}
+		return nil
+	}
+
+	err = s.preferenceRepo.Unmute(ctx, userID, projectID)
+	if err != nil {
+		return fmt.Errorf("preference repository Unmute: %w", err)
+	}
+
+	return nil
+}
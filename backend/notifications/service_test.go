@@ -4,14 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/ptracker/core"
 	"github.com/ptracker/core/members"
+	"github.com/ptracker/core/mentions"
 	"github.com/ptracker/core/projects"
 	"github.com/ptracker/core/tasks"
 	"github.com/ptracker/core/users"
 	"github.com/ptracker/models"
+	"github.com/ptracker/outbox"
 	"github.com/ptracker/testdata"
 	"github.com/ptracker/testhelpers"
 	"github.com/ptracker/testhelpers/fixtures"
@@ -45,7 +49,7 @@ func (suite *notificationServiceTestSuite) SetupSuite() {
 		log.Fatal(err)
 	}
 
-	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{})
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -57,17 +61,25 @@ func (suite *notificationServiceTestSuite) SetupSuite() {
 
 	suite.db.AutoMigrate(&models.Notification{})
 
+	txManager := core.NewTxManager(suite.db)
 	projectRepo := projects.NewProjectRepository(suite.db)
 	taskRepo := tasks.NewTaskRepository(suite.db)
 	memberRepo := members.NewMemberRepository(suite.db)
 	userRepo := users.NewUserRepository(suite.db)
-	notificationRepo := NewNotificationRepository(suite.db)
+	notificationRepo := NewNotificationRepository(suite.db, nil)
+	mentionRepo := mentions.NewMentionRepository(suite.db)
+	preferenceRepo := NewPreferenceRepository(suite.db)
+	outboxRepo := outbox.NewOutboxRepository(suite.db)
 	suite.service = NewNotificationService(
+		txManager,
 		projectRepo,
 		taskRepo,
 		memberRepo,
 		userRepo,
 		notificationRepo,
+		mentionRepo,
+		preferenceRepo,
+		outboxRepo,
 	)
 
 	suite.fixtures = fixtures.New(suite.ctx, suite.db)
@@ -95,7 +107,7 @@ func (suite *notificationServiceTestSuite) TestTaskAdded() {
 		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
 		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
-		err := suite.service.TaskAdded(suite.ctx, p, taskID)
+		err := suite.service.TaskAdded(suite.ctx, p, taskID, USER_ONE)
 
 		suite.Cleanup()
 		suite.Require().NoError(err)
@@ -104,7 +116,7 @@ func (suite *notificationServiceTestSuite) TestTaskAdded() {
 		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
 		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
-		suite.service.TaskAdded(suite.ctx, p, taskID)
+		suite.service.TaskAdded(suite.ctx, p, taskID, USER_ONE)
 
 		n, _ :=
 			gorm.G[models.Notification](suite.db).
@@ -114,11 +126,27 @@ func (suite *notificationServiceTestSuite) TestTaskAdded() {
 		suite.Cleanup()
 		suite.Require().Equal(1, len(n))
 	})
+	t.Run("should notify the owner and not the creator", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MAINTAINER))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		err := suite.service.TaskAdded(suite.ctx, p, taskID, USER_TWO)
+
+		n, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("type = ?", NT_TASK_ADDED).
+				Find(suite.ctx)
+
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Len(n, 1)
+		suite.Require().Equal(USER_ONE, n[0].UserID)
+	})
 	t.Run("should match task_added notification body", func(t *testing.T) {
 		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
 		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
 		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
-		suite.service.TaskAdded(suite.ctx, p, taskID)
+		suite.service.TaskAdded(suite.ctx, p, taskID, USER_ONE)
 
 		/*
 			Body: {
@@ -154,7 +182,7 @@ func (suite *notificationServiceTestSuite) TestTaskUpdated() {
 		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
 		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskID, USER_TWO))
 
-		err := suite.service.TaskUpdated(suite.ctx, p, taskID, &[]string{"New title"}[0], nil, nil, USER_TWO)
+		err := suite.service.TaskUpdated(suite.ctx, p, taskID, &[]string{"New title"}[0], nil, nil, nil, nil, nil, nil, USER_TWO)
 
 		suite.Cleanup()
 		suite.Require().NoError(err)
@@ -165,7 +193,7 @@ func (suite *notificationServiceTestSuite) TestTaskUpdated() {
 		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
 		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskID, USER_TWO))
 
-		suite.service.TaskUpdated(suite.ctx, p, taskID, &[]string{"New title"}[0], nil, nil, USER_TWO)
+		suite.service.TaskUpdated(suite.ctx, p, taskID, &[]string{"New title"}[0], nil, nil, nil, nil, nil, nil, USER_TWO)
 
 		n, _ :=
 			gorm.G[models.Notification](suite.db).
@@ -180,7 +208,7 @@ func (suite *notificationServiceTestSuite) TestTaskUpdated() {
 		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
 		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
 		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskID, USER_TWO))
-		suite.service.TaskUpdated(suite.ctx, p, taskID, &[]string{"New title"}[0], nil, nil, USER_TWO)
+		suite.service.TaskUpdated(suite.ctx, p, taskID, &[]string{"New title"}[0], nil, nil, nil, nil, nil, nil, USER_TWO)
 
 		n, _ :=
 			gorm.G[models.Notification](suite.db).
@@ -203,7 +231,7 @@ func (suite *notificationServiceTestSuite) TestTaskUpdated() {
 		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
 		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
 		suite.fixtures.InsertAssignee(fixtures.GetAssigneeRow(p, taskID, USER_TWO))
-		suite.service.TaskUpdated(suite.ctx, p, taskID, &[]string{"New title"}[0], &[]string{"New description"}[0], nil, USER_TWO)
+		suite.service.TaskUpdated(suite.ctx, p, taskID, &[]string{"New title"}[0], &[]string{"New description"}[0], nil, nil, nil, nil, nil, USER_TWO)
 
 		n, _ :=
 			gorm.G[models.Notification](suite.db).
@@ -311,6 +339,25 @@ func (suite *notificationServiceTestSuite) TestJoinRequested() {
 		suite.Require().Equal(p, body.Project.ID)
 		suite.Require().Equal(USER_TWO, body.Requestor.UserID)
 	})
+	t.Run("should notify the members who manage join requests", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MAINTAINER))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_THREE, core.ROLE_MEMBER))
+		requestor := suite.fixtures.InsertUser(fixtures.RandomUserRow())
+
+		err := suite.service.JoinRequested(suite.ctx, p, requestor)
+
+		n, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("type = ?", NT_JOIN_REQUESTED).
+				Find(suite.ctx)
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Len(n, 2)
+		for _, notification := range n {
+			suite.Require().NotEqual(USER_THREE, notification.UserID)
+		}
+	})
 }
 
 func (suite *notificationServiceTestSuite) TestJoinResponded() {
@@ -326,7 +373,9 @@ func (suite *notificationServiceTestSuite) TestJoinResponded() {
 			},
 		})
 
-		err := suite.service.JoinResponded(suite.ctx, p, USER_TWO, core.JOIN_STATUS_ACCEPTED)
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_THREE, core.ROLE_MAINTAINER))
+
+		err := suite.service.JoinResponded(suite.ctx, p, USER_TWO, USER_THREE, core.JOIN_STATUS_ACCEPTED)
 
 		n, _ :=
 			gorm.G[models.Notification](suite.db).
@@ -340,7 +389,7 @@ func (suite *notificationServiceTestSuite) TestJoinResponded() {
 		err = json.Unmarshal(n.Body, &body)
 		suite.Require().NoError(err)
 		suite.Require().Equal(p, body.Project.ID)
-		suite.Require().Equal(USER_ONE, body.Responder.UserID)
+		suite.Require().Equal(USER_THREE, body.Responder.UserID)
 		suite.Require().Equal(core.JOIN_STATUS_ACCEPTED, body.Status)
 	})
 }
@@ -373,6 +422,263 @@ func (suite *notificationServiceTestSuite) TestCommentAdded() {
 	})
 }
 
+func (suite *notificationServiceTestSuite) TestMentioned() {
+	t := suite.T()
+
+	t.Run("should notify mentioned member with snippet", func(t *testing.T) {
+		mentioned := fixtures.RandomUserRow()
+		suite.fixtures.InsertUser(mentioned)
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, mentioned.ID, core.ROLE_MEMBER))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+		content := "can you check this @" + mentioned.Username + "?"
+
+		err := suite.service.Mentioned(suite.ctx, p, taskID, taskID, USER_ONE, content)
+
+		n, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("user_id = ?", mentioned.ID).
+				First(suite.ctx)
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Equal(NT_MENTIONED, n.Type)
+
+		var body Mentioned
+		err = json.Unmarshal(n.Body, &body)
+		suite.Require().NoError(err)
+		suite.Require().Equal(taskID, body.Task.ID)
+		suite.Require().Equal(USER_ONE, body.Mentioner.UserID)
+		suite.Require().Equal(content, body.Snippet)
+	})
+
+	t.Run("should not notify again when the source is edited", func(t *testing.T) {
+		mentioned := fixtures.RandomUserRow()
+		suite.fixtures.InsertUser(mentioned)
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, mentioned.ID, core.ROLE_MEMBER))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+
+		err1 := suite.service.Mentioned(suite.ctx, p, taskID, taskID, USER_ONE,
+			"hi @"+mentioned.Username)
+		err2 := suite.service.Mentioned(suite.ctx, p, taskID, taskID, USER_ONE,
+			"hello @"+mentioned.Username)
+
+		n, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("user_id = ?", mentioned.ID).
+				Count(suite.ctx, "*")
+		suite.Cleanup()
+		suite.Require().NoError(err1)
+		suite.Require().NoError(err2)
+		suite.Require().Equal(int64(1), n)
+	})
+
+	t.Run("should ignore users who are not members", func(t *testing.T) {
+		outsider := fixtures.RandomUserRow()
+		suite.fixtures.InsertUser(outsider)
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+
+		err := suite.service.Mentioned(suite.ctx, p, taskID, taskID, USER_ONE,
+			"@"+outsider.Username)
+
+		n, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("user_id = ?", outsider.ID).
+				Count(suite.ctx, "*")
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Equal(int64(0), n)
+	})
+}
+
+func (suite *notificationServiceTestSuite) TestPreferences() {
+	t := suite.T()
+
+	t.Run("should default every type to in_app", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		preferences, err := suite.service.Preferences(suite.ctx, p, USER_ONE)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().False(preferences.Muted)
+		suite.Require().Len(preferences.Channels, len(NOTIFICATION_TYPES))
+		suite.Require().Equal(CHANNEL_IN_APP, preferences.Channels[NT_TASK_ADDED])
+	})
+
+	t.Run("should return forbidden when user is not a member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		_, err := suite.service.Preferences(suite.ctx, p, USER_TWO)
+		err2 := suite.service.SetMuted(suite.ctx, p, USER_TWO, true)
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err, core.ErrForbidden)
+		suite.Require().ErrorIs(err2, core.ErrForbidden)
+	})
+
+	t.Run("should return invalid value for unknown type or channel", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err1 := suite.service.UpdatePreferences(suite.ctx, p, USER_ONE,
+			map[string]string{"unknown": CHANNEL_NONE})
+		err2 := suite.service.UpdatePreferences(suite.ctx, p, USER_ONE,
+			map[string]string{NT_TASK_ADDED: "sms"})
+
+		suite.Cleanup()
+
+		suite.Require().ErrorIs(err1, core.ErrInvalidValue)
+		suite.Require().ErrorIs(err2, core.ErrInvalidValue)
+	})
+
+	t.Run("should skip notifications of a type turned off", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_THREE, core.ROLE_MEMBER))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+
+		err := suite.service.UpdatePreferences(suite.ctx, p, USER_TWO,
+			map[string]string{NT_TASK_ADDED: CHANNEL_NONE})
+		suite.Require().NoError(err)
+		preferences, _ := suite.service.Preferences(suite.ctx, p, USER_TWO)
+
+		err = suite.service.TaskAdded(suite.ctx, p, taskID, USER_ONE)
+
+		n, _ := gorm.G[models.Notification](suite.db).
+			Where("type = ?", NT_TASK_ADDED).
+			Find(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Equal(CHANNEL_NONE, preferences.Channels[NT_TASK_ADDED])
+		suite.Require().Len(n, 1)
+		suite.Require().Equal(USER_THREE, n[0].UserID)
+	})
+
+	t.Run("should skip every notification of a muted project until unmuted", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+
+		err1 := suite.service.SetMuted(suite.ctx, p, USER_TWO, true)
+		preferences, _ := suite.service.Preferences(suite.ctx, p, USER_TWO)
+		suite.service.TaskAdded(suite.ctx, p, taskID, USER_ONE)
+		suite.service.OwnershipTransferred(suite.ctx, p, USER_ONE, USER_TWO)
+
+		var muted int64
+		suite.db.Model(&models.Notification{}).Where("user_id = ?", USER_TWO).Count(&muted)
+
+		err2 := suite.service.SetMuted(suite.ctx, p, USER_TWO, false)
+		suite.service.TaskAdded(suite.ctx, p, taskID, USER_ONE)
+
+		var unmuted int64
+		suite.db.Model(&models.Notification{}).Where("user_id = ?", USER_TWO).Count(&unmuted)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err1)
+		suite.Require().NoError(err2)
+		suite.Require().True(preferences.Muted)
+		suite.Require().Zero(muted)
+		suite.Require().EqualValues(1, unmuted)
+	})
+	t.Run("should request an email for the email channel", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+
+		suite.service.UpdatePreferences(suite.ctx, p, USER_TWO,
+			map[string]string{NT_TASK_ADDED: CHANNEL_EMAIL})
+		err := suite.service.TaskAdded(suite.ctx, p, taskID, USER_ONE)
+
+		n, _ := gorm.G[models.Notification](suite.db).
+			Where("user_id = ?", USER_TWO).
+			First(suite.ctx)
+		events, _ := gorm.G[models.OutboxEvent](suite.db).
+			Where("type = ?", outbox.EV_EMAIL_REQUESTED).
+			Find(suite.ctx)
+
+		suite.Cleanup()
+		suite.db.Exec("DELETE FROM outbox_events")
+
+		suite.Require().NoError(err)
+		suite.Require().False(n.DigestPending)
+		suite.Require().Len(events, 1)
+		suite.Require().Contains(string(events[0].Payload), n.ID)
+	})
+
+	t.Run("should keep notification for the digest channel", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		taskID := suite.fixtures.InsertTask(fixtures.RandomTaskRow(p, core.TASK_STATUS_UNASSIGNED))
+
+		suite.service.UpdatePreferences(suite.ctx, p, USER_TWO,
+			map[string]string{NT_TASK_ADDED: CHANNEL_DIGEST})
+		err := suite.service.TaskAdded(suite.ctx, p, taskID, USER_ONE)
+
+		n, _ := gorm.G[models.Notification](suite.db).
+			Where("user_id = ?", USER_TWO).
+			First(suite.ctx)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().True(n.DigestPending)
+	})
+}
+
+func (suite *notificationServiceTestSuite) TestMissed() {
+	t := suite.T()
+
+	t.Run("should list notifications after the last one oldest first", func(t *testing.T) {
+		now := time.Now()
+		first := fixtures.GetNotificationRow(USER_ONE, NT_TASK_ADDED, TaskAdded{})
+		first.CreatedAt = now.Add(-2 * time.Minute)
+		second := fixtures.GetNotificationRow(USER_ONE, NT_TASK_ADDED, TaskAdded{})
+		second.CreatedAt = now.Add(-1 * time.Minute)
+		third := fixtures.GetNotificationRow(USER_ONE, NT_TASK_ADDED, TaskAdded{})
+		third.CreatedAt = now
+		suite.fixtures.InsertNotification(first)
+		suite.fixtures.InsertNotification(second)
+		suite.fixtures.InsertNotification(third)
+		suite.fixtures.InsertNotification(
+			fixtures.GetNotificationRow(USER_TWO, NT_TASK_ADDED, TaskAdded{}))
+
+		missed, err := suite.service.Missed(suite.ctx, USER_ONE, first.ID)
+
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, len(missed))
+		suite.Require().Equal(second.ID, missed[0].ID)
+		suite.Require().Equal(third.ID, missed[1].ID)
+	})
+
+	t.Run("should ignore notification of another user", func(t *testing.T) {
+		other := fixtures.GetNotificationRow(USER_TWO, NT_TASK_ADDED, TaskAdded{})
+		suite.fixtures.InsertNotification(other)
+		suite.fixtures.InsertNotification(
+			fixtures.GetNotificationRow(USER_ONE, NT_TASK_ADDED, TaskAdded{}))
+
+		missed, err := suite.service.Missed(suite.ctx, USER_ONE, other.ID)
+
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Empty(missed)
+	})
+}
+
+func TestMentionedUsernames(t *testing.T) {
+	usernames := mentionedUsernames("@alice, ask @bob.smith and @alice. mail bob@test.com")
+
+	if !slices.Equal([]string{"alice", "bob.smith"}, usernames) {
+		t.Fatalf("unexpected usernames %v", usernames)
+	}
+}
+
 func (suite *notificationServiceTestSuite) TestList() {
 	t := suite.T()
 
@@ -395,10 +701,253 @@ func (suite *notificationServiceTestSuite) TestList() {
 			},
 		}))
 
-		notifications, err := suite.service.List(suite.ctx, USER_ONE)
+		notifications, _, err := suite.service.List(suite.ctx, USER_ONE, core.PageQuery{Limit: 10})
 
 		suite.Cleanup()
 		suite.Require().NoError(err)
 		suite.Require().Equal(2, len(notifications))
 	})
+	t.Run("should list latest notification first with next cursor", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		older := fixtures.GetNotificationRow(USER_ONE, NT_JOIN_REQUESTED, JoinRequested{
+			Project: ProjectBody{
+				ID: p,
+			},
+		})
+		newer := fixtures.GetNotificationRow(USER_ONE, NT_JOIN_REQUESTED, JoinRequested{
+			Project: ProjectBody{
+				ID: p,
+			},
+		})
+		suite.fixtures.InsertNotification(older)
+		suite.fixtures.InsertNotification(newer)
+
+		notifications, cursor, err := suite.service.List(suite.ctx, USER_ONE, core.PageQuery{Limit: 1})
+
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Equal(newer.ID, notifications[0].ID)
+		suite.Require().NotEmpty(cursor)
+	})
+}
+
+func (suite *notificationServiceTestSuite) TestTaskDeleted() {
+	t := suite.T()
+
+	t.Run("should notify assignees except deleter", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := &tasks.DeletedTask{
+			ID:          "deleted-task",
+			ProjectID:   p,
+			Title:       "Deleted Task",
+			AssigneeIDs: []string{USER_ONE, USER_TWO},
+		}
+
+		err := suite.service.TaskDeleted(suite.ctx, task, USER_ONE)
+
+		notifications, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("type = ?", NT_TASK_DELETED).
+				Find(suite.ctx)
+
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Equal(1, len(notifications))
+		suite.Require().Equal(USER_TWO, notifications[0].UserID)
+	})
+	t.Run("should match notification body", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		task := &tasks.DeletedTask{
+			ID:          "deleted-task",
+			ProjectID:   p,
+			Title:       "Deleted Task",
+			AssigneeIDs: []string{USER_TWO},
+		}
+		suite.service.TaskDeleted(suite.ctx, task, USER_ONE)
+
+		n, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("user_id = ?", USER_TWO).
+				First(suite.ctx)
+
+		suite.Cleanup()
+		var body TaskDeleted
+		err := json.Unmarshal(n.Body, &body)
+		suite.Require().NoError(err)
+		suite.Require().Equal(p, body.Project.ID)
+		suite.Require().Equal("Deleted Task", body.Task.Title)
+		suite.Require().Equal(USER_ONE, body.Deleter.UserID)
+	})
+}
+
+func (suite *notificationServiceTestSuite) TestProjectDeleted() {
+	t := suite.T()
+
+	t.Run("should notify members except deleter", func(t *testing.T) {
+		project := &projects.DeletedProject{
+			ID:        "deleted-project",
+			Name:      "Deleted Project",
+			MemberIDs: []string{USER_ONE, USER_TWO, USER_THREE},
+		}
+
+		err := suite.service.ProjectDeleted(suite.ctx, project, USER_ONE)
+
+		notifications, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("type = ?", NT_PROJECT_DELETED).
+				Find(suite.ctx)
+
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, len(notifications))
+	})
+}
+
+func (suite *notificationServiceTestSuite) TestProjectUpdated() {
+	t := suite.T()
+
+	t.Run("should notify members except updater", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_THREE, core.ROLE_MEMBER))
+		name := "Project Renamed"
+
+		err := suite.service.ProjectUpdated(suite.ctx, p, &name, nil, nil, USER_ONE)
+
+		notifications, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("type = ?", NT_PROJECT_UPDATED).
+				Find(suite.ctx)
+
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, len(notifications))
+	})
+	t.Run("should match notification body", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+		skills := "Go, Postgres"
+		suite.service.ProjectUpdated(suite.ctx, p, nil, nil, &skills, USER_ONE)
+
+		n, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("user_id = ?", USER_TWO).
+				First(suite.ctx)
+
+		suite.Cleanup()
+		var body ProjectUpdated
+		err := json.Unmarshal(n.Body, &body)
+		suite.Require().NoError(err)
+		suite.Require().Equal(p, body.Project.ID)
+		suite.Require().Equal([]ProjectUpdateBody{{To: skills, Field: "Skills"}}, body.Updates)
+		suite.Require().Equal(USER_ONE, body.Updater.UserID)
+	})
+}
+
+func (suite *notificationServiceTestSuite) TestMemberRemoved() {
+	t := suite.T()
+
+	t.Run("should notify removed member", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.service.MemberRemoved(suite.ctx, p, USER_TWO, USER_ONE)
+
+		n, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("user_id = ?", USER_TWO).
+				First(suite.ctx)
+
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Equal(NT_MEMBER_REMOVED, n.Type)
+		var body MemberRemoved
+		suite.Require().NoError(json.Unmarshal(n.Body, &body))
+		suite.Require().Equal(p, body.Project.ID)
+		suite.Require().Equal(USER_ONE, body.Remover.UserID)
+	})
+}
+
+func (suite *notificationServiceTestSuite) TestMemberLeft() {
+	t := suite.T()
+
+	t.Run("should notify owner", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+
+		err := suite.service.MemberLeft(suite.ctx, p, USER_TWO)
+
+		n, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("user_id = ?", USER_ONE).
+				First(suite.ctx)
+
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Equal(NT_MEMBER_LEFT, n.Type)
+		var body MemberLeft
+		suite.Require().NoError(json.Unmarshal(n.Body, &body))
+		suite.Require().Equal(USER_TWO, body.Member.UserID)
+	})
+}
+
+func (suite *notificationServiceTestSuite) TestOwnershipTransferred() {
+	t := suite.T()
+
+	t.Run("should notify previous and new owner", func(t *testing.T) {
+		p := suite.fixtures.InsertProject(fixtures.RandomProjectRow(USER_ONE))
+		suite.fixtures.InsertMember(fixtures.GetMemberRow(p, USER_TWO, core.ROLE_MEMBER))
+
+		err := suite.service.OwnershipTransferred(suite.ctx, p, USER_ONE, USER_TWO)
+
+		notifications, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("type = ?", NT_OWNERSHIP_TRANSFERRED).
+				Find(suite.ctx)
+
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Equal(2, len(notifications))
+		var body OwnershipTransferred
+		suite.Require().NoError(json.Unmarshal(notifications[0].Body, &body))
+		suite.Require().Equal(USER_ONE, body.PreviousOwner.UserID)
+		suite.Require().Equal(USER_TWO, body.NewOwner.UserID)
+	})
+}
+
+func (suite *notificationServiceTestSuite) TestCrossUserAccess() {
+	t := suite.T()
+
+	t.Run("should mark own notification as read", func(t *testing.T) {
+		n := fixtures.GetNotificationRow(USER_ONE, NT_JOIN_REQUESTED, JoinRequested{})
+		suite.fixtures.InsertNotification(n)
+
+		err := suite.service.MarkAsRead(suite.ctx, USER_ONE, n.ID)
+
+		suite.Cleanup()
+		suite.Require().NoError(err)
+	})
+	t.Run("should not mark notification of another user as read", func(t *testing.T) {
+		n := fixtures.GetNotificationRow(USER_ONE, NT_JOIN_REQUESTED, JoinRequested{})
+		suite.fixtures.InsertNotification(n)
+
+		err := suite.service.MarkAsRead(suite.ctx, USER_TWO, n.ID)
+
+		stored, _ :=
+			gorm.G[models.Notification](suite.db).
+				Where("id = ?", n.ID).
+				First(suite.ctx)
+
+		suite.Cleanup()
+		suite.Require().ErrorIs(err, core.ErrNotFound)
+		suite.Require().False(stored.Read)
+	})
+	t.Run("should not list notifications of another user", func(t *testing.T) {
+		suite.fixtures.InsertNotification(
+			fixtures.GetNotificationRow(USER_ONE, NT_JOIN_REQUESTED, JoinRequested{}))
+
+		notifications, _, err := suite.service.List(suite.ctx, USER_TWO, core.PageQuery{Limit: 10})
+
+		suite.Cleanup()
+		suite.Require().NoError(err)
+		suite.Require().Equal(0, len(notifications))
+	})
 }
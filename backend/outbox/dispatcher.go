@@ -0,0 +1,226 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"gorm.io/gorm"
+)
+
+const (
+	DISPATCH_WORKERS    = 4
+	DISPATCH_BATCH_SIZE = 10
+
+	// how long an idle worker waits before claiming again
+	DISPATCH_POLL_INTERVAL = 1 * time.Second
+
+	// a claimed event is claimed again once the lease is over, it has to
+	// outlast the handlers of the batch
+	DISPATCH_LEASE = 2 * time.Minute
+
+	DISPATCH_MAX_ATTEMPTS = 8
+	DISPATCH_BASE_BACKOFF = 2 * time.Second
+	DISPATCH_MAX_BACKOFF  = 15 * time.Minute
+)
+
+// Returned by a handler for an event another attempt already completed
+var ErrDispatched = errors.New("event already dispatched")
+
+/*
+Handles the payload of event eventID
+
+Returning an error wrapping ErrNotFound or ErrInvalidValue sends the event
+to the dead letters at once, retrying can not fix a missing subject or a
+malformed payload. Events are delivered at least once, so a handler can see
+an event again after a failure or once its lease is over: a handler writing
+to the database completes the event with OutboxRepository.Complete in the
+transaction of its changes, for them to be written once.
+*/
+type Handler func(ctx context.Context, eventID string, payload []byte) error
+
+/*
+Dispatches the outbox events to their handlers from a pool of workers
+
+Every worker claims a batch of events with SELECT ... FOR UPDATE SKIP
+LOCKED, so the workers of all the server instances share the outbox. A
+failed event is retried with exponential backoff until it runs out of
+attempts, then it is moved to the dead letters.
+*/
+type Dispatcher struct {
+	txManager  *core.TxManager
+	outboxRepo *OutboxRepository
+
+	handlers map[string]Handler
+
+	workers      int
+	batchSize    int
+	pollInterval time.Duration
+	lease        time.Duration
+	maxAttempts  int
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+}
+
+func NewDispatcher(
+	txManager *core.TxManager,
+	outboxRepo *OutboxRepository,
+) *Dispatcher {
+	return &Dispatcher{
+		txManager:    txManager,
+		outboxRepo:   outboxRepo,
+		handlers:     map[string]Handler{},
+		workers:      DISPATCH_WORKERS,
+		batchSize:    DISPATCH_BATCH_SIZE,
+		pollInterval: DISPATCH_POLL_INTERVAL,
+		lease:        DISPATCH_LEASE,
+		maxAttempts:  DISPATCH_MAX_ATTEMPTS,
+		baseBackoff:  DISPATCH_BASE_BACKOFF,
+		maxBackoff:   DISPATCH_MAX_BACKOFF,
+	}
+}
+
+// Registers the handler of the event type, an event has a single handler
+func (d *Dispatcher) Handle(eventType string, h Handler) {
+	d.handlers[eventType] = h
+}
+
+// Runs the workers until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for range d.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.work(ctx)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (d *Dispatcher) work(ctx context.Context) {
+	for {
+		n, err := d.Dispatch(ctx)
+		if err != nil {
+			log.Printf("[ERROR] dispatcher Dispatch: %s", err)
+		}
+
+		// keep going while there are events left
+		if n > 0 && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.pollInterval):
+		}
+	}
+}
+
+// Delay before the next attempt of an event that failed attempts times
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.baseBackoff
+	for i := 1; i < attempts && delay < d.maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, d.maxBackoff)
+}
+
+// Claims a batch of events and dispatches them, returns the batch size
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	var events []models.OutboxEvent
+
+	err := d.txManager.WithTx(func(tx *gorm.DB) error {
+		var err error
+
+		events, err = d.outboxRepo.WithTx(tx).Claim(ctx, d.batchSize, d.lease)
+		if err != nil {
+			return fmt.Errorf("outbox repository claim: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("txManager WithTx: %w", err)
+	}
+
+	for _, e := range events {
+		err = d.dispatch(ctx, e)
+		if err != nil {
+			log.Printf("[ERROR] dispatcher dispatch %s: %s", e.ID, err)
+		}
+	}
+
+	return len(events), nil
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, e models.OutboxEvent) error {
+	var err error
+
+	h, ok := d.handlers[e.Type]
+	if !ok {
+		err = fmt.Errorf("no handler for event type %s: %w", e.Type, core.ErrInvalidValue)
+	} else {
+		err = h(ctx, e.ID, e.Payload)
+	}
+
+	if errors.Is(err, ErrDispatched) {
+		return nil
+	}
+
+	if err == nil {
+		// the handler may have completed the event in its transaction
+		err = d.outboxRepo.Delete(ctx, e.ID)
+		if err != nil && !errors.Is(err, core.ErrNotFound) {
+			return fmt.Errorf("outbox repository delete: %w", err)
+		}
+		return nil
+	}
+
+	if errors.Is(err, core.ErrNotFound) || errors.Is(err, core.ErrInvalidValue) ||
+		e.Attempts >= d.maxAttempts {
+		return d.bury(ctx, e, err.Error())
+	}
+
+	err = d.outboxRepo.Retry(ctx, e.ID, time.Now().Add(d.backoff(e.Attempts)), err.Error())
+	if err != nil {
+		return fmt.Errorf("outbox repository retry: %w", err)
+	}
+
+	return nil
+}
+
+// Moves the event to the dead letters
+func (d *Dispatcher) bury(ctx context.Context, e models.OutboxEvent, lastError string) error {
+	err := d.txManager.WithTx(func(tx *gorm.DB) error {
+		outboxRepo := d.outboxRepo.WithTx(tx)
+
+		err := outboxRepo.CreateDeadLetter(ctx, e, lastError)
+		if err != nil {
+			return fmt.Errorf("outbox repository create dead letter: %w", err)
+		}
+
+		err = outboxRepo.Delete(ctx, e.ID)
+		if err != nil {
+			return fmt.Errorf("outbox repository delete: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("txManager WithTx: %w", err)
+	}
+
+	log.Printf("[ERROR] dispatcher dead letter %s %s: %s", e.ID, e.Type, lastError)
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type dispatcherTestSuite struct {
+	suite.Suite
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	txManager   *core.TxManager
+	repo        *OutboxRepository
+	ctx         context.Context
+}
+
+func (suite *dispatcherTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx = context.Background()
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.txManager = core.NewTxManager(suite.db)
+	suite.repo = NewOutboxRepository(suite.db)
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func (suite *dispatcherTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM outbox_events").Error
+	suite.Require().NoError(err)
+
+	err = suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM dead_letters").Error
+	suite.Require().NoError(err)
+}
+
+func TestDispatcher(t *testing.T) {
+	suite.Run(t, new(dispatcherTestSuite))
+}
+
+// Dispatches a single task added event, returns what is left of it
+func (suite *dispatcherTestSuite) dispatchOnce(d *Dispatcher) ([]models.OutboxEvent, []models.DeadLetter) {
+	err := suite.repo.Create(suite.ctx, EV_TASK_ADDED, TaskAdded{ProjectID: "p", TaskID: "t"})
+	suite.Require().NoError(err)
+
+	_, err = d.Dispatch(suite.ctx)
+	suite.Require().NoError(err)
+
+	var events []models.OutboxEvent
+	suite.db.Find(&events)
+
+	var letters []models.DeadLetter
+	suite.db.Find(&letters)
+
+	return events, letters
+}
+
+func (suite *dispatcherTestSuite) TestDispatch() {
+	t := suite.T()
+
+	t.Run("should delete event once handled", func(t *testing.T) {
+		var received TaskAdded
+
+		d := NewDispatcher(suite.txManager, suite.repo)
+		d.Handle(EV_TASK_ADDED, func(ctx context.Context, eventID string, payload []byte) error {
+			return json.Unmarshal(payload, &received)
+		})
+
+		events, letters := suite.dispatchOnce(d)
+
+		suite.Cleanup()
+
+		suite.Require().Empty(events)
+		suite.Require().Empty(letters)
+		suite.Require().Equal(TaskAdded{ProjectID: "p", TaskID: "t"}, received)
+	})
+
+	t.Run("should drop event completed by its handler", func(t *testing.T) {
+		d := NewDispatcher(suite.txManager, suite.repo)
+		d.Handle(EV_TASK_ADDED, func(ctx context.Context, eventID string, payload []byte) error {
+			return suite.txManager.WithTx(func(tx *gorm.DB) error {
+				return suite.repo.WithTx(tx).Complete(ctx, eventID)
+			})
+		})
+
+		events, letters := suite.dispatchOnce(d)
+
+		suite.Cleanup()
+
+		suite.Require().Empty(events)
+		suite.Require().Empty(letters)
+	})
+
+	t.Run("should drop event another attempt completed", func(t *testing.T) {
+		d := NewDispatcher(suite.txManager, suite.repo)
+		d.Handle(EV_TASK_ADDED, func(ctx context.Context, eventID string, payload []byte) error {
+			err := suite.repo.Delete(ctx, eventID)
+			if err != nil {
+				return err
+			}
+
+			return suite.repo.Complete(ctx, eventID)
+		})
+
+		events, letters := suite.dispatchOnce(d)
+
+		suite.Cleanup()
+
+		suite.Require().Empty(events)
+		suite.Require().Empty(letters)
+	})
+
+	t.Run("should retry failed event later", func(t *testing.T) {
+		d := NewDispatcher(suite.txManager, suite.repo)
+		d.Handle(EV_TASK_ADDED, func(ctx context.Context, eventID string, payload []byte) error {
+			return errors.New("unavailable")
+		})
+
+		events, letters := suite.dispatchOnce(d)
+
+		suite.Cleanup()
+
+		suite.Require().Len(events, 1)
+		suite.Require().Empty(letters)
+		suite.Require().Equal(1, events[0].Attempts)
+		suite.Require().Equal("unavailable", *events[0].LastError)
+		suite.Require().True(events[0].AvailableAt.After(time.Now()))
+	})
+
+	t.Run("should move event to dead letters when subject is not found", func(t *testing.T) {
+		d := NewDispatcher(suite.txManager, suite.repo)
+		d.Handle(EV_TASK_ADDED, func(ctx context.Context, eventID string, payload []byte) error {
+			return fmt.Errorf("task repository get: %w", core.ErrNotFound)
+		})
+
+		events, letters := suite.dispatchOnce(d)
+
+		suite.Cleanup()
+
+		suite.Require().Empty(events)
+		suite.Require().Len(letters, 1)
+		suite.Require().Equal(EV_TASK_ADDED, letters[0].Type)
+		suite.Require().Equal(1, letters[0].Attempts)
+	})
+
+	t.Run("should move event to dead letters when out of attempts", func(t *testing.T) {
+		d := NewDispatcher(suite.txManager, suite.repo)
+		d.maxAttempts = 1
+		d.Handle(EV_TASK_ADDED, func(ctx context.Context, eventID string, payload []byte) error {
+			return errors.New("unavailable")
+		})
+
+		events, letters := suite.dispatchOnce(d)
+
+		suite.Cleanup()
+
+		suite.Require().Empty(events)
+		suite.Require().Len(letters, 1)
+		suite.Require().Equal("unavailable", letters[0].Error)
+	})
+
+	t.Run("should move event to dead letters when it has no handler", func(t *testing.T) {
+		d := NewDispatcher(suite.txManager, suite.repo)
+
+		events, letters := suite.dispatchOnce(d)
+
+		suite.Cleanup()
+
+		suite.Require().Empty(events)
+		suite.Require().Len(letters, 1)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(nil, nil)
+
+	t.Run("should double the delay after every attempt", func(t *testing.T) {
+		if d.backoff(1) != DISPATCH_BASE_BACKOFF || d.backoff(3) != 4*DISPATCH_BASE_BACKOFF {
+			t.Errorf("backoff(1) = %s, backoff(3) = %s", d.backoff(1), d.backoff(3))
+		}
+	})
+
+	t.Run("should not go past the max backoff", func(t *testing.T) {
+		if d.backoff(100) != DISPATCH_MAX_BACKOFF {
+			t.Errorf("backoff(100) = %s", d.backoff(100))
+		}
+	})
+}
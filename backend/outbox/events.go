@@ -0,0 +1,122 @@
+package outbox
+
+import "time"
+
+const (
+	EV_TASK_ADDED            = "task_added"
+	EV_TASK_UPDATED          = "task_updated"
+	EV_TASK_DELETED          = "task_deleted"
+	EV_ASSIGNEE_UPDATED      = "assignee_updated"
+	EV_COMMENT_ADDED         = "comment_added"
+	EV_MENTIONED             = "mentioned"
+	EV_JOIN_REQUESTED        = "join_requested"
+	EV_JOIN_RESPONDED        = "join_responded"
+	EV_PROJECT_UPDATED       = "project_updated"
+	EV_PROJECT_DELETED       = "project_deleted"
+	EV_MEMBER_REMOVED        = "member_removed"
+	EV_MEMBER_LEFT           = "member_left"
+	EV_OWNERSHIP_TRANSFERRED = "ownership_transferred"
+	EV_EMAIL_REQUESTED       = "email_requested"
+)
+
+type TaskAdded struct {
+	ProjectID string `json:"project_id"`
+	TaskID    string `json:"task_id"`
+	CreatorID string `json:"creator_id"`
+}
+
+// Only the updated fields are set
+type TaskUpdated struct {
+	ProjectID   string     `json:"project_id"`
+	TaskID      string     `json:"task_id"`
+	Title       *string    `json:"title"`
+	Description *string    `json:"description"`
+	Status      *string    `json:"status"`
+	Priority    *string    `json:"priority"`
+	Estimate    *int       `json:"estimate"`
+	StartDate   *time.Time `json:"start_date"` // a zero date was cleared
+	DueDate     *time.Time `json:"due_date"`   // a zero date was cleared
+	UpdaterID   string     `json:"updater_id"`
+}
+
+// The task is gone by the time it is dispatched, so it carries what is left of it
+type TaskDeleted struct {
+	ProjectID   string   `json:"project_id"`
+	TaskID      string   `json:"task_id"`
+	Title       string   `json:"title"`
+	AssigneeIDs []string `json:"assignee_ids"`
+	DeleterID   string   `json:"deleter_id"`
+}
+
+type AssigneeUpdated struct {
+	ProjectID  string `json:"project_id"`
+	TaskID     string `json:"task_id"`
+	AssigneeID string `json:"assignee_id"`
+	Added      bool   `json:"added"`
+}
+
+type CommentAdded struct {
+	ProjectID   string `json:"project_id"`
+	TaskID      string `json:"task_id"`
+	CommenterID string `json:"commenter_id"`
+}
+
+// SourceID is the id of the comment, or the id of the task for its description
+type Mentioned struct {
+	ProjectID   string `json:"project_id"`
+	TaskID      string `json:"task_id"`
+	SourceID    string `json:"source_id"`
+	MentionerID string `json:"mentioner_id"`
+	Content     string `json:"content"`
+}
+
+type JoinRequested struct {
+	ProjectID   string `json:"project_id"`
+	RequestorID string `json:"requestor_id"`
+}
+
+type JoinResponded struct {
+	ProjectID   string `json:"project_id"`
+	RequestorID string `json:"requestor_id"`
+	ResponderID string `json:"responder_id"`
+	Status      string `json:"status"`
+}
+
+// Only the updated fields are set
+type ProjectUpdated struct {
+	ProjectID   string  `json:"project_id"`
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Skills      *string `json:"skills"`
+	UpdaterID   string  `json:"updater_id"`
+}
+
+// The project is gone by the time it is dispatched, so it carries what is left of it
+type ProjectDeleted struct {
+	ProjectID string   `json:"project_id"`
+	Name      string   `json:"name"`
+	MemberIDs []string `json:"member_ids"`
+	DeleterID string   `json:"deleter_id"`
+}
+
+type MemberRemoved struct {
+	ProjectID string `json:"project_id"`
+	MemberID  string `json:"member_id"`
+	RemoverID string `json:"remover_id"`
+}
+
+type MemberLeft struct {
+	ProjectID string `json:"project_id"`
+	MemberID  string `json:"member_id"`
+}
+
+type OwnershipTransferred struct {
+	ProjectID       string `json:"project_id"`
+	PreviousOwnerID string `json:"previous_owner_id"`
+	NewOwnerID      string `json:"new_owner_id"`
+}
+
+// Notification to be sent by email, its user chose the email channel
+type EmailRequested struct {
+	NotificationID string `json:"notification_id"`
+}
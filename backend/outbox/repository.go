@@ -0,0 +1,177 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+func (r *OutboxRepository) WithTx(tx *gorm.DB) *OutboxRepository {
+	return NewOutboxRepository(tx)
+}
+
+// Writes the event, to be called with the transaction of the change
+func (r *OutboxRepository) Create(ctx context.Context,
+	eventType string,
+	payload any) error {
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("json marshal: %w", err)
+	}
+
+	event := models.OutboxEvent{
+		ID:          uuid.NewString(),
+		Type:        eventType,
+		Payload:     body,
+		AvailableAt: time.Now(),
+	}
+	err = gorm.G[models.OutboxEvent](r.db).Create(ctx, &event)
+	if err != nil {
+		return fmt.Errorf("gorm create: %w", err)
+	}
+
+	return nil
+}
+
+/*
+Claims up to limit available events, oldest first, counting an attempt for
+each of them
+
+The claimed events are hidden until lease is over, so an event whose worker
+died is claimed again. Events locked by another transaction are skipped, so
+it has to run in a transaction for the lock to hold until the update.
+*/
+func (r *OutboxRepository) Claim(ctx context.Context,
+	limit int,
+	lease time.Duration) ([]models.OutboxEvent, error) {
+
+	now := time.Now()
+	events, err := gorm.G[models.OutboxEvent](r.db,
+		clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("available_at <= ?", now).
+		Order("available_at ASC").
+		Limit(limit).
+		Find(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gorm query: %w", err)
+	}
+	if len(events) == 0 {
+		return events, nil
+	}
+
+	ids := []string{}
+	for i := range events {
+		ids = append(ids, events[i].ID)
+		events[i].Attempts++
+		events[i].AvailableAt = now.Add(lease)
+	}
+
+	err = r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Updates(map[string]any{
+			"attempts":     gorm.Expr("attempts + 1"),
+			"available_at": now.Add(lease),
+		}).Error
+	if err != nil {
+		return nil, fmt.Errorf("gorm db updates: %w", err)
+	}
+
+	return events, nil
+}
+
+// Removes the dispatched event
+func (r *OutboxRepository) Delete(ctx context.Context, id string) error {
+	rows, err := gorm.G[models.OutboxEvent](r.db).
+		Where("id = ?", id).
+		Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("gorm delete: %w", err)
+	}
+	if rows == 0 {
+		return core.ErrNotFound
+	}
+
+	return nil
+}
+
+/*
+Deletes event id in the transaction of its handler, returns ErrDispatched
+when another attempt already completed it
+
+The delete locks the event, an attempt running alongside waits for the
+first one to commit and finds nothing left to complete.
+*/
+func (r *OutboxRepository) Complete(ctx context.Context, id string) error {
+	err := r.Delete(ctx, id)
+	if errors.Is(err, core.ErrNotFound) {
+		return ErrDispatched
+	}
+	if err != nil {
+		return fmt.Errorf("outbox repository Delete: %w", err)
+	}
+
+	return nil
+}
+
+// Makes the failed event available again at availableAt
+func (r *OutboxRepository) Retry(ctx context.Context,
+	id string,
+	availableAt time.Time,
+	lastError string) error {
+
+	result := r.db.WithContext(ctx).
+		Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"available_at": availableAt,
+			"last_error":   lastError,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("gorm db updates: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return core.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) CreateDeadLetter(ctx context.Context,
+	event models.OutboxEvent,
+	lastError string) error {
+
+	letter := models.DeadLetter{
+		ID:             event.ID,
+		Type:           event.Type,
+		Payload:        event.Payload,
+		Attempts:       event.Attempts,
+		Error:          lastError,
+		EventCreatedAt: event.CreatedAt,
+	}
+	err := gorm.G[models.DeadLetter](r.db).Create(ctx, &letter)
+	if err != nil {
+		return fmt.Errorf("gorm create: %w", err)
+	}
+
+	return nil
+}
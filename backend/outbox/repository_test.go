@@ -0,0 +1,126 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/ptracker/core"
+	"github.com/ptracker/models"
+	"github.com/ptracker/testdata"
+	"github.com/ptracker/testhelpers"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type outboxRepositoryTestSuite struct {
+	suite.Suite
+	pgContainer *testhelpers.PostgresContainer
+	db          *gorm.DB
+	txManager   *core.TxManager
+	repo        *OutboxRepository
+	ctx         context.Context
+}
+
+func (suite *outboxRepositoryTestSuite) SetupSuite() {
+	var err error
+
+	suite.ctx = context.Background()
+
+	suite.pgContainer, err = testhelpers.CreatePostgresContainer(suite.ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.db, err = gorm.Open(postgres.Open(suite.pgContainer.ConnectionString), &gorm.Config{TranslateError: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	suite.txManager = core.NewTxManager(suite.db)
+	suite.repo = NewOutboxRepository(suite.db)
+
+	err = testdata.TestMigrate(suite.db)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func (suite *outboxRepositoryTestSuite) Cleanup() {
+	err := suite.db.WithContext(suite.ctx).
+		Exec("DELETE FROM outbox_events").Error
+	suite.Require().NoError(err)
+}
+
+func TestOutboxRepository(t *testing.T) {
+	suite.Run(t, new(outboxRepositoryTestSuite))
+}
+
+func (suite *outboxRepositoryTestSuite) TestClaim() {
+	t := suite.T()
+
+	t.Run("should claim available events and count an attempt", func(t *testing.T) {
+		err := suite.repo.Create(suite.ctx, EV_TASK_ADDED, TaskAdded{ProjectID: "p", TaskID: "t"})
+		suite.Require().NoError(err)
+
+		claimed, err := suite.repo.Claim(suite.ctx, 10, time.Minute)
+
+		var row models.OutboxEvent
+		suite.db.First(&row)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().Len(claimed, 1)
+		suite.Require().Equal(EV_TASK_ADDED, claimed[0].Type)
+		suite.Require().Equal(1, claimed[0].Attempts)
+		suite.Require().Equal(1, row.Attempts)
+		suite.Require().True(row.AvailableAt.After(time.Now()))
+	})
+
+	t.Run("should not claim an event twice during its lease", func(t *testing.T) {
+		err := suite.repo.Create(suite.ctx, EV_TASK_ADDED, TaskAdded{ProjectID: "p", TaskID: "t"})
+		suite.Require().NoError(err)
+
+		first, err1 := suite.repo.Claim(suite.ctx, 10, time.Minute)
+		second, err2 := suite.repo.Claim(suite.ctx, 10, time.Minute)
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err1)
+		suite.Require().NoError(err2)
+		suite.Require().Len(first, 1)
+		suite.Require().Empty(second)
+	})
+
+	t.Run("should skip events locked by another transaction", func(t *testing.T) {
+		for range 2 {
+			err := suite.repo.Create(suite.ctx, EV_TASK_ADDED, TaskAdded{ProjectID: "p", TaskID: "t"})
+			suite.Require().NoError(err)
+		}
+
+		var first, second []models.OutboxEvent
+		var err1, err2 error
+
+		// the first claim holds its lock while the second one runs
+		err := suite.txManager.WithTx(func(tx *gorm.DB) error {
+			first, err1 = suite.repo.WithTx(tx).Claim(suite.ctx, 1, time.Minute)
+
+			return suite.txManager.WithTx(func(tx *gorm.DB) error {
+				second, err2 = suite.repo.WithTx(tx).Claim(suite.ctx, 10, time.Minute)
+				return nil
+			})
+		})
+
+		suite.Cleanup()
+
+		suite.Require().NoError(err)
+		suite.Require().NoError(err1)
+		suite.Require().NoError(err2)
+		suite.Require().Len(first, 1)
+		suite.Require().Len(second, 1)
+		suite.Require().NotEqual(first[0].ID, second[0].ID)
+	})
+}
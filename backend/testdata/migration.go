@@ -18,18 +18,37 @@ func TestMigrate(db *gorm.DB) error {
 		&models.JoinRequest{},
 		&models.Member{},
 		&models.Comment{},
+		&models.Ban{},
+		&models.Label{},
+		&models.TaskLabel{},
+		&models.TaskDependency{},
+		&models.TaskEvent{},
+		&models.WorkflowStatus{},
+		&models.WorkflowTransition{},
+		&models.Activity{},
+		&models.Mention{},
+		&models.OutboxEvent{},
+		&models.DeadLetter{},
+		&models.NotificationPreference{},
+		&models.ProjectMute{},
 	)
 	if err != nil {
 		return fmt.Errorf("gorm db auto migrate: %w", err)
 	}
 
+	// the status of a task is its built-in category, so the tasks in custom
+	// workflow statuses are counted under their category
 	query := db.
 		Table("projects p").
 		Select(`p.id, 
 				COUNT(t.id) FILTER (WHERE t.status='Unassigned') as unassigned_tasks,
 				COUNT(t.id) FILTER (WHERE t.status='Ongoing') as ongoing_tasks, 
 				COUNT(t.id) FILTER (WHERE t.status='Completed') as completed_tasks, 
-				COUNT(t.id) FILTER (WHERE t.status='Abandoned') as abandoned_tasks`).
+				COUNT(t.id) FILTER (WHERE t.status='Abandoned') as abandoned_tasks,
+				COALESCE(SUM(t.estimate) FILTER (WHERE t.status='Unassigned'), 0) as unassigned_estimate,
+				COALESCE(SUM(t.estimate) FILTER (WHERE t.status='Ongoing'), 0) as ongoing_estimate,
+				COALESCE(SUM(t.estimate) FILTER (WHERE t.status='Completed'), 0) as completed_estimate,
+				COALESCE(SUM(t.estimate) FILTER (WHERE t.status='Abandoned'), 0) as abandoned_estimate`).
 		Joins("LEFT JOIN tasks as t ON p.id=t.project_id").
 		Group("p.id")
 	err = db.Migrator().CreateView("project_summary", gorm.ViewOption{Query: query})
@@ -0,0 +1,38 @@
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ptracker/models"
+)
+
+func GetActivityRow(projectID, actorID, activityType string, body any,
+	createdAt time.Time) models.Activity {
+
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		panic(fmt.Sprintf("json marshal body failed: %v", err))
+	}
+
+	return models.Activity{
+		ID:        uuid.NewString(),
+		ProjectID: projectID,
+		ActorID:   actorID,
+		Type:      activityType,
+		Body:      jsonBody,
+		CreatedAt: createdAt,
+	}
+}
+
+func (f *Fixtures) InsertActivity(a models.Activity) string {
+	if f.db != nil {
+		if err := f.db.WithContext(f.ctx).Create(&a).Error; err != nil {
+			panic(fmt.Sprintf("insert activity fixture failed: %v", err))
+		}
+		return a.ID
+	}
+	return ""
+}
@@ -23,3 +23,9 @@ func (f *Fixtures) InsertComment(c models.Comment) {
 		return
 	}
 }
+
+func GetReplyRow(parent models.Comment, userId, content string) models.Comment {
+	c := GetCommentRow(parent.ProjectID, parent.TaskID, userId, content)
+	c.ParentID = &parent.ID
+	return c
+}
@@ -0,0 +1,31 @@
+package fixtures
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ptracker/models"
+)
+
+func GetTaskEventRow(projectID, taskID, actorID, eventType string,
+	createdAt time.Time) models.TaskEvent {
+	return models.TaskEvent{
+		ID:        uuid.NewString(),
+		ProjectID: projectID,
+		TaskID:    taskID,
+		ActorID:   actorID,
+		Type:      eventType,
+		CreatedAt: createdAt,
+	}
+}
+
+func (f *Fixtures) InsertTaskEvent(e models.TaskEvent) string {
+	if f.db != nil {
+		if err := f.db.WithContext(f.ctx).Create(&e).Error; err != nil {
+			panic(fmt.Sprintf("insert task event fixture failed: %v", err))
+		}
+		return e.ID
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package fixtures
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/ptracker/models"
+)
+
+func RandomLabelRow(projectID string) models.Label {
+	lId := uuid.NewString()
+
+	return models.Label{
+		ID:        lId,
+		ProjectID: projectID,
+		Name:      "Label " + lId,
+		Color:     "#1f6feb",
+	}
+}
+
+func (f *Fixtures) InsertLabel(l models.Label) string {
+	if f.db != nil {
+		if err := f.db.WithContext(f.ctx).Create(&l).Error; err != nil {
+			panic(fmt.Sprintf("insert label fixture failed: %v", err))
+		}
+		return l.ID
+	}
+	return ""
+}
+
+func GetTaskLabelRow(taskID, labelID string) models.TaskLabel {
+	return models.TaskLabel{
+		TaskID:  taskID,
+		LabelID: labelID,
+	}
+}
+
+func (f *Fixtures) InsertTaskLabel(tl models.TaskLabel) {
+	if f.db != nil {
+		if err := f.db.WithContext(f.ctx).Create(&tl).Error; err != nil {
+			panic(fmt.Sprintf("insert task label fixture failed: %v", err))
+		}
+		return
+	}
+}
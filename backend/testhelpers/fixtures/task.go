@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/ptracker/core"
 	"github.com/ptracker/models"
 )
 
@@ -18,6 +19,7 @@ func RandomTaskRow(projectId, status string) models.Task {
 		Title:       "Test Task " + tId,
 		Description: &desc,
 		Status:      models.TaskStatus{String: status},
+		Priority:    models.TaskPriority{String: core.TASK_PRIORITY_MEDIUM},
 	}
 }
 
@@ -30,3 +32,19 @@ func (f *Fixtures) InsertTask(t models.Task) string {
 	}
 	return ""
 }
+
+func GetTaskDependencyRow(taskID, blockerID string) models.TaskDependency {
+	return models.TaskDependency{
+		TaskID:    taskID,
+		BlockerID: blockerID,
+	}
+}
+
+func (f *Fixtures) InsertTaskDependency(d models.TaskDependency) {
+	if f.db != nil {
+		if err := f.db.WithContext(f.ctx).Create(&d).Error; err != nil {
+			panic(fmt.Sprintf("insert task dependency fixture failed: %v", err))
+		}
+		return
+	}
+}
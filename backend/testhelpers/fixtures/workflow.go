@@ -0,0 +1,42 @@
+package fixtures
+
+import (
+	"fmt"
+
+	"github.com/ptracker/models"
+)
+
+func GetWorkflowStatusRow(projectID, name, category string, position int) models.WorkflowStatus {
+	return models.WorkflowStatus{
+		ProjectID: projectID,
+		Name:      name,
+		Category:  models.TaskStatus{String: category},
+		Position:  position,
+	}
+}
+
+func (f *Fixtures) InsertWorkflowStatus(s models.WorkflowStatus) {
+	if f.db != nil {
+		if err := f.db.WithContext(f.ctx).Create(&s).Error; err != nil {
+			panic(fmt.Sprintf("insert workflow status fixture failed: %v", err))
+		}
+		return
+	}
+}
+
+func GetWorkflowTransitionRow(projectID, from, to string) models.WorkflowTransition {
+	return models.WorkflowTransition{
+		ProjectID:  projectID,
+		FromStatus: from,
+		ToStatus:   to,
+	}
+}
+
+func (f *Fixtures) InsertWorkflowTransition(t models.WorkflowTransition) {
+	if f.db != nil {
+		if err := f.db.WithContext(f.ctx).Create(&t).Error; err != nil {
+			panic(fmt.Sprintf("insert workflow transition fixture failed: %v", err))
+		}
+		return
+	}
+}